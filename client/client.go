@@ -55,6 +55,31 @@ const (
 	QueryTypeOpenSessions string = internal.QueryTypeOpenSessions
 )
 
+const (
+	// ResetTypeEventID resets the workflow to the workflow task finish event given by ResetWorkflowRequest.EventID.
+	ResetTypeEventID = internal.ResetTypeEventID
+
+	// ResetTypeLastWorkflowTask resets the workflow to its last completed workflow task.
+	ResetTypeLastWorkflowTask = internal.ResetTypeLastWorkflowTask
+
+	// ResetTypeBadBinary resets the workflow to the first workflow task completed by the worker binary
+	// with ResetWorkflowRequest.BinaryChecksum, so that the task is redone by a fixed worker.
+	ResetTypeBadBinary = internal.ResetTypeBadBinary
+
+	// ResetTypeLastContinuedAsNew resets the run that continued as new into the given run to its last
+	// completed workflow task.
+	ResetTypeLastContinuedAsNew = internal.ResetTypeLastContinuedAsNew
+
+	// BatchOperationSignal signals every execution with BatchOperationOptions.SignalName and SignalArg.
+	BatchOperationSignal = internal.BatchOperationSignal
+
+	// BatchOperationCancel requests cancellation of every execution.
+	BatchOperationCancel = internal.BatchOperationCancel
+
+	// BatchOperationTerminate terminates every execution with BatchOperationOptions.Reason and Details.
+	BatchOperationTerminate = internal.BatchOperationTerminate
+)
+
 type (
 	// Options are optional parameters for Client creation.
 	Options = internal.ClientOptions
@@ -62,12 +87,24 @@ type (
 	// ConnectionOptions are optional parameters that can be specified in ClientOptions
 	ConnectionOptions = internal.ConnectionOptions
 
+	// PayloadLimitOptions are limits of payload sizes that can be specified in ClientOptions
+	PayloadLimitOptions = internal.PayloadLimitOptions
+
 	// StartWorkflowOptions configuration parameters for starting a workflow execution.
 	StartWorkflowOptions = internal.StartWorkflowOptions
 
 	// HistoryEventIterator is a iterator which can return history events.
 	HistoryEventIterator = internal.HistoryEventIterator
 
+	// WorkflowExecutionIterator is a iterator which can return workflow executions from the visibility APIs.
+	WorkflowExecutionIterator = internal.WorkflowExecutionIterator
+
+	// WorkflowExecutionInfo is a workflow execution returned by WorkflowExecutionIterator.
+	WorkflowExecutionInfo = internal.WorkflowExecutionInfo
+
+	// ListWorkflowExecutionsOptions select the visibility API used by ListWorkflowExecutions.
+	ListWorkflowExecutionsOptions = internal.ListWorkflowExecutionsOptions
+
 	// WorkflowRun represents a started non child workflow.
 	WorkflowRun = internal.WorkflowRun
 
@@ -77,6 +114,24 @@ type (
 	// QueryWorkflowWithOptionsResponse defines the response to QueryWorkflowWithOptions.
 	QueryWorkflowWithOptionsResponse = internal.QueryWorkflowWithOptionsResponse
 
+	// ResetWorkflowRequest defines the request to ResetWorkflow.
+	ResetWorkflowRequest = internal.ResetWorkflowRequest
+
+	// ResetType selects the workflow task a workflow execution is reset to by ResetWorkflow.
+	ResetType = internal.ResetType
+
+	// BatchOperationOptions configure BatchOperation.
+	BatchOperationOptions = internal.BatchOperationOptions
+
+	// BatchOperationType is the operation BatchOperation applies to every workflow execution matching the query.
+	BatchOperationType = internal.BatchOperationType
+
+	// BatchOperationResult is the progress and result report of BatchOperation.
+	BatchOperationResult = internal.BatchOperationResult
+
+	// BatchOperationFailure is the failure of the batch operation for a single workflow execution.
+	BatchOperationFailure = internal.BatchOperationFailure
+
 	// Client is the client for starting and getting information about a workflow executions as well as
 	// completing activities asynchronously.
 	Client interface {
@@ -282,6 +337,19 @@ type (
 		//  - InternalServiceError
 		ScanWorkflow(ctx context.Context, request *workflowservice.ScanWorkflowExecutionsRequest) (*workflowservice.ScanWorkflowExecutionsResponse, error)
 
+		// ListWorkflowExecutions returns an iterator over the workflow executions returned by ListWorkflow,
+		// ListOpenWorkflow, ListClosedWorkflow, ListArchivedWorkflow or ScanWorkflow, depending on the request set in
+		// the options. Pages are fetched lazily and next page tokens are handled by the iterator. Memo and search
+		// attributes of the returned executions are decoded through the DataConverter of the client.
+		//	iter := ListWorkflowExecutions(ctx, ListWorkflowExecutionsOptions{ListRequest: request})
+		//	for iter.HasNext() {
+		//		execution, err := iter.Next()
+		//		if err != nil {
+		//			return err
+		//		}
+		//	}
+		ListWorkflowExecutions(ctx context.Context, options ListWorkflowExecutionsOptions) WorkflowExecutionIterator
+
 		// CountWorkflow gets number of workflow executions based on query. This API only works with ElasticSearch,
 		// and will return BadRequestError when using Cassandra or MySQL. The query is basically the SQL WHERE clause
 		// (see ListWorkflow for query examples).
@@ -333,6 +401,25 @@ type (
 		//  - EntityNotExistError
 		DescribeWorkflowExecution(ctx context.Context, workflowID, runID string) (*workflowservice.DescribeWorkflowExecutionResponse, error)
 
+		// ResetWorkflow resets a workflow execution to the reset point selected by the request and starts a new run
+		// from there. See ResetWorkflowRequest and ResetType for more information.
+		// The server reapplies the signals received after the reset point to the new run.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		ResetWorkflow(ctx context.Context, request *ResetWorkflowRequest) (*workflowservice.ResetWorkflowExecutionResponse, error)
+
+		// BatchOperation pages through the workflow executions matching the visibility query of the options using
+		// ListWorkflow, or ScanWorkflow, and signals, cancels or terminates each of them with bounded concurrency
+		// and rate. The returned BatchOperationResult reports per-execution failures and can be used to resume
+		// an interrupted batch through its NextPageToken and ProcessedExecutions.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - context errors if the batch is interrupted
+		BatchOperation(ctx context.Context, options BatchOperationOptions) (*BatchOperationResult, error)
+
 		// DescribeTaskQueue returns information about the target taskqueue, right now this API returns the
 		// pollers which polled this taskqueue in last few minutes.
 		// The errors it can return:
@@ -0,0 +1,201 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package converter
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	commonpb "go.temporal.io/api/common/v1"
+)
+
+const defaultBlobStoreCodecMaxInlineBytes = 128 * 1024
+
+type (
+	// BlobStore is an external storage of large payloads, see NewBlobStoreCodec.
+	BlobStore interface {
+		// Put stores data and returns reference to it. Every call must return new reference.
+		Put(data []byte) (reference string, err error)
+		// Get returns data by reference.
+		Get(reference string) ([]byte, error)
+		// Delete removes data by reference. Deleting data which doesn't exist is not an error.
+		Delete(reference string) error
+	}
+
+	// BlobStoreCodecOptions are optional parameters for NewBlobStoreCodec.
+	BlobStoreCodecOptions struct {
+		// Optional: Payloads with data larger than MaxInlineBytes are offloaded to BlobStore.
+		// default: 128KB
+		MaxInlineBytes int
+	}
+
+	blobStoreCodec struct {
+		store          BlobStore
+		maxInlineBytes int
+	}
+
+	// FileBlobStore is BlobStore which keeps every blob in a separate file of a local directory.
+	FileBlobStore struct {
+		dir string
+	}
+)
+
+// NewBlobStoreCodec creates PayloadCodec which offloads large payloads to BlobStore.
+// Offloaded payload has MetadataEncodingBlobReference encoding and reference as data, whole original payload
+// is stored in BlobStore. References are resolved on decode, so values are read from BlobStore only when they are
+// actually used, for example by EncodedValue.Get.
+// Blobs referenced from workflow history are deleted once namespace retention removed the history if BlobStore is
+// set in worker options.
+func NewBlobStoreCodec(store BlobStore, options BlobStoreCodecOptions) PayloadCodec {
+	if options.MaxInlineBytes == 0 {
+		options.MaxInlineBytes = defaultBlobStoreCodecMaxInlineBytes
+	}
+	return &blobStoreCodec{
+		store:          store,
+		maxInlineBytes: options.MaxInlineBytes,
+	}
+}
+
+// GetBlobReferences returns references to BlobStore of offloaded payloads.
+func GetBlobReferences(payloads []*commonpb.Payload) []string {
+	var result []string
+	for _, payload := range payloads {
+		if string(payload.GetMetadata()[MetadataEncoding]) == MetadataEncodingBlobReference {
+			result = append(result, string(payload.GetData()))
+		}
+	}
+	return result
+}
+
+func (c *blobStoreCodec) Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, payload := range payloads {
+		if len(payload.GetData()) <= c.maxInlineBytes {
+			result[i] = payload
+			continue
+		}
+
+		data, err := payload.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("payload item %d: %v: %w", i, err, ErrUnableToEncode)
+		}
+		reference, err := c.store.Put(data)
+		if err != nil {
+			return nil, fmt.Errorf("payload item %d: %v: %w", i, err, ErrUnableToEncode)
+		}
+		result[i] = &commonpb.Payload{
+			Metadata: map[string][]byte{
+				MetadataEncoding: []byte(MetadataEncodingBlobReference),
+			},
+			Data: []byte(reference),
+		}
+	}
+	return result, nil
+}
+
+func (c *blobStoreCodec) Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, payload := range payloads {
+		if string(payload.GetMetadata()[MetadataEncoding]) != MetadataEncodingBlobReference {
+			result[i] = payload
+			continue
+		}
+
+		data, err := c.store.Get(string(payload.GetData()))
+		if err != nil {
+			return nil, fmt.Errorf("payload item %d: blob %s: %v: %w", i, payload.GetData(), err, ErrUnableToDecode)
+		}
+		result[i] = &commonpb.Payload{}
+		if err := result[i].Unmarshal(data); err != nil {
+			return nil, fmt.Errorf("payload item %d: blob %s: %v: %w", i, payload.GetData(), err, ErrUnableToDecode)
+		}
+	}
+	return result, nil
+}
+
+// NewFileBlobStore creates new instance of FileBlobStore. Directory must exist.
+func NewFileBlobStore(dir string) *FileBlobStore {
+	return &FileBlobStore{dir: dir}
+}
+
+// Put writes data to a new file.
+func (s *FileBlobStore) Put(data []byte) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	reference := hex.EncodeToString(id)
+
+	// Write to temporary file first, so readers never see partial data.
+	f, err := ioutil.TempFile(s.dir, reference+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(s.dir, reference))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return reference, nil
+}
+
+// Get reads data from file.
+func (s *FileBlobStore) Get(reference string) ([]byte, error) {
+	path, err := s.path(reference)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
+
+// Delete removes file.
+func (s *FileBlobStore) Delete(reference string) error {
+	path, err := s.path(reference)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileBlobStore) path(reference string) (string, error) {
+	if reference == "" || strings.ContainsAny(reference, `/\.`) {
+		return "", fmt.Errorf("invalid blob reference %q", reference)
+	}
+	return filepath.Join(s.dir, reference), nil
+}
@@ -0,0 +1,77 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package converter
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobStoreCodec(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "blobs")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	store := NewFileBlobStore(dir)
+	dc := NewCodecDataConverter(defaultDataConverter, NewBlobStoreCodec(store, BlobStoreCodecOptions{MaxInlineBytes: 100}))
+	large := strings.Repeat("large value ", 100)
+
+	payloads, err := dc.ToPayloads("small", large)
+	require.NoError(t, err)
+	small, offloaded := payloads.GetPayloads()[0], payloads.GetPayloads()[1]
+	require.Equal(t, `"small"`, string(small.GetData()))
+	require.Equal(t, MetadataEncodingBlobReference, string(offloaded.GetMetadata()[MetadataEncoding]))
+
+	references := GetBlobReferences(payloads.GetPayloads())
+	require.Equal(t, []string{string(offloaded.GetData())}, references)
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, references[0], files[0].Name())
+
+	var s1, s2 string
+	require.NoError(t, dc.FromPayloads(payloads, &s1, &s2))
+	require.Equal(t, "small", s1)
+	require.Equal(t, large, s2)
+	require.Equal(t, large, dc.ToString(offloaded))
+
+	require.NoError(t, store.Delete(references[0]))
+	require.NoError(t, store.Delete(references[0]))
+	require.Error(t, dc.FromPayload(offloaded, &s2))
+}
+
+func TestFileBlobStore_InvalidReference(t *testing.T) {
+	t.Parallel()
+	store := NewFileBlobStore(os.TempDir())
+	for _, reference := range []string{"", "../secret", "a/b", "."} {
+		_, err := store.Get(reference)
+		require.Error(t, err, reference)
+		require.Error(t, store.Delete(reference), reference)
+	}
+}
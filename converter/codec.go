@@ -0,0 +1,183 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package converter
+
+import (
+	"fmt"
+
+	commonpb "go.temporal.io/api/common/v1"
+)
+
+type (
+	// PayloadCodec is a byte level transformation of payloads, like compression or encryption,
+	// which is applied to every payload produced by DataConverter.
+	// PayloadCodec can also be used without DataConverter, for example to decode payloads of workflow history
+	// in a standalone tool.
+	PayloadCodec interface {
+		// Encode transforms payloads. Returned slice must have the same length as the input one.
+		Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error)
+		// Decode reverts Encode. Payloads which weren't encoded by this codec must be returned as is.
+		Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error)
+	}
+
+	// CodecDataConverter applies PayloadCodecs to payloads of parent DataConverter.
+	CodecDataConverter struct {
+		parent DataConverter
+		codecs []PayloadCodec
+	}
+)
+
+// NewCodecDataConverter creates new instance of CodecDataConverter.
+// Codecs are applied in specified order on encode and in reverse order on decode. For example, to compress payloads
+// before they are encrypted, pass compression codec before encryption codec.
+func NewCodecDataConverter(parent DataConverter, codecs ...PayloadCodec) *CodecDataConverter {
+	return &CodecDataConverter{
+		parent: parent,
+		codecs: codecs,
+	}
+}
+
+// Encode applies codecs to payloads in order.
+func (dc *CodecDataConverter) Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	var err error
+	for _, codec := range dc.codecs {
+		if payloads, err = codec.Encode(payloads); err != nil {
+			return nil, err
+		}
+	}
+	return payloads, nil
+}
+
+// Decode applies codecs to payloads in reverse order.
+func (dc *CodecDataConverter) Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	var err error
+	for i := len(dc.codecs) - 1; i >= 0; i-- {
+		if payloads, err = dc.codecs[i].Decode(payloads); err != nil {
+			return nil, err
+		}
+	}
+	return payloads, nil
+}
+
+// ToPayloads converts a list of values and encodes resulting payloads.
+func (dc *CodecDataConverter) ToPayloads(values ...interface{}) (*commonpb.Payloads, error) {
+	payloads, err := dc.parent.ToPayloads(values...)
+	if err != nil || payloads == nil {
+		return payloads, err
+	}
+
+	encodedPayloads, err := dc.Encode(payloads.GetPayloads())
+	if err != nil {
+		return nil, err
+	}
+
+	return &commonpb.Payloads{Payloads: encodedPayloads}, nil
+}
+
+// FromPayloads decodes payloads and converts them to a list of values of different types.
+func (dc *CodecDataConverter) FromPayloads(payloads *commonpb.Payloads, valuePtrs ...interface{}) error {
+	if payloads == nil {
+		return nil
+	}
+
+	decodedPayloads, err := dc.Decode(payloads.GetPayloads())
+	if err != nil {
+		return err
+	}
+
+	return dc.parent.FromPayloads(&commonpb.Payloads{Payloads: decodedPayloads}, valuePtrs...)
+}
+
+// ToPayload converts single value and encodes resulting payload.
+func (dc *CodecDataConverter) ToPayload(value interface{}) (*commonpb.Payload, error) {
+	payload, err := dc.parent.ToPayload(value)
+	if err != nil || payload == nil {
+		return payload, err
+	}
+
+	encodedPayloads, err := dc.Encode([]*commonpb.Payload{payload})
+	if err != nil {
+		return nil, err
+	}
+	if len(encodedPayloads) != 1 {
+		return nil, fmt.Errorf("codecs returned %d payloads instead of 1: %w", len(encodedPayloads), ErrUnableToEncode)
+	}
+
+	return encodedPayloads[0], nil
+}
+
+// FromPayload decodes payload and converts it to single value.
+func (dc *CodecDataConverter) FromPayload(payload *commonpb.Payload, valuePtr interface{}) error {
+	if payload == nil {
+		return nil
+	}
+
+	decodedPayload, err := dc.decodeSingle(payload)
+	if err != nil {
+		return err
+	}
+
+	return dc.parent.FromPayload(decodedPayload, valuePtr)
+}
+
+// ToString decodes payload and converts it into human readable string.
+func (dc *CodecDataConverter) ToString(payload *commonpb.Payload) string {
+	if payload == nil {
+		return ""
+	}
+
+	decodedPayload, err := dc.decodeSingle(payload)
+	if err != nil {
+		return err.Error()
+	}
+
+	return dc.parent.ToString(decodedPayload)
+}
+
+// ToStrings converts payloads object into human readable strings.
+func (dc *CodecDataConverter) ToStrings(payloads *commonpb.Payloads) []string {
+	if payloads == nil {
+		return nil
+	}
+
+	var result []string
+	for _, payload := range payloads.GetPayloads() {
+		result = append(result, dc.ToString(payload))
+	}
+
+	return result
+}
+
+func (dc *CodecDataConverter) decodeSingle(payload *commonpb.Payload) (*commonpb.Payload, error) {
+	decodedPayloads, err := dc.Decode([]*commonpb.Payload{payload})
+	if err != nil {
+		return nil, err
+	}
+	if len(decodedPayloads) != 1 {
+		return nil, fmt.Errorf("codecs returned %d payloads instead of 1: %w", len(decodedPayloads), ErrUnableToDecode)
+	}
+
+	return decodedPayloads[0], nil
+}
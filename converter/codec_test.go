@@ -0,0 +1,122 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package converter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+)
+
+// suffixCodec appends suffix to payload data and records it in "codecs" metadata.
+type suffixCodec struct {
+	suffix string
+}
+
+func (c *suffixCodec) Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, p := range payloads {
+		metadata := map[string][]byte{}
+		for k, v := range p.GetMetadata() {
+			metadata[k] = v
+		}
+		metadata["codecs"] = append(metadata["codecs"], c.suffix...)
+		result[i] = &commonpb.Payload{Metadata: metadata, Data: append(append([]byte{}, p.GetData()...), c.suffix...)}
+	}
+	return result, nil
+}
+
+func (c *suffixCodec) Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, p := range payloads {
+		data := string(p.GetData())
+		codecs := string(p.GetMetadata()["codecs"])
+		if len(codecs) == 0 || codecs[len(codecs)-1:] != c.suffix || data[len(data)-1:] != c.suffix {
+			return nil, errors.New("codec " + c.suffix + " is applied out of order")
+		}
+		metadata := map[string][]byte{}
+		for k, v := range p.GetMetadata() {
+			metadata[k] = v
+		}
+		metadata["codecs"] = []byte(codecs[:len(codecs)-1])
+		result[i] = &commonpb.Payload{Metadata: metadata, Data: []byte(data[:len(data)-1])}
+	}
+	return result, nil
+}
+
+func TestCodecDataConverter(t *testing.T) {
+	t.Parallel()
+	dc := NewCodecDataConverter(defaultDataConverter, &suffixCodec{suffix: "a"}, &suffixCodec{suffix: "b"})
+
+	payload, err := dc.ToPayload("value")
+	require.NoError(t, err)
+	require.Equal(t, `"value"ab`, string(payload.GetData()))
+	require.Equal(t, "ab", string(payload.GetMetadata()["codecs"]))
+
+	var s string
+	require.NoError(t, dc.FromPayload(payload, &s))
+	require.Equal(t, "value", s)
+	require.Equal(t, "value", dc.ToString(payload))
+
+	payloads, err := dc.ToPayloads("value", 42)
+	require.NoError(t, err)
+	require.Len(t, payloads.GetPayloads(), 2)
+	var i int
+	require.NoError(t, dc.FromPayloads(payloads, &s, &i))
+	require.Equal(t, 42, i)
+	require.Equal(t, []string{"value", "42"}, dc.ToStrings(payloads))
+
+	decoded, err := dc.Decode(payloads.GetPayloads())
+	require.NoError(t, err)
+	require.Equal(t, "42", string(decoded[1].GetData()))
+
+	wrongOrder := NewCodecDataConverter(defaultDataConverter, &suffixCodec{suffix: "b"}, &suffixCodec{suffix: "a"})
+	require.Error(t, wrongOrder.FromPayload(payload, &s))
+	require.Equal(t, "codec a is applied out of order", wrongOrder.ToString(payload))
+
+	empty, err := dc.ToPayloads()
+	require.NoError(t, err)
+	require.Nil(t, empty)
+	require.NoError(t, dc.FromPayloads(nil, &s))
+}
+
+func TestCodecDataConverter_EncryptionAfterCodec(t *testing.T) {
+	t.Parallel()
+	dc := NewCodecDataConverter(defaultDataConverter,
+		&suffixCodec{suffix: "a"},
+		NewEncryptionCodec(NewStaticKeyProvider("key1", map[string][]byte{"key1": testKey1})),
+	)
+
+	payload, err := dc.ToPayload("value")
+	require.NoError(t, err)
+	require.Equal(t, MetadataEncodingEncrypted, string(payload.GetMetadata()[MetadataEncoding]))
+	require.Equal(t, "a", string(payload.GetMetadata()["codecs"]))
+
+	var s string
+	require.NoError(t, dc.FromPayload(payload, &s))
+	require.Equal(t, "value", s)
+}
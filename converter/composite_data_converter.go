@@ -25,6 +25,7 @@
 package converter
 
 import (
+	"errors"
 	"fmt"
 
 	commonpb "go.temporal.io/api/common/v1"
@@ -33,8 +34,8 @@ import (
 type (
 	// CompositeDataConverter applies PayloadConverters in specified order.
 	CompositeDataConverter struct {
-		payloadConverters map[string]PayloadConverter
-		orderedEncodings  []string
+		payloadConverters        map[string][]PayloadConverter
+		orderedPayloadConverters []PayloadConverter
 	}
 )
 
@@ -42,15 +43,17 @@ type (
 // Order is important here because during serialization DataConverter will try PayloadsConverters in
 // that order until PayloadConverter returns non nil payload.
 // Last PayloadConverter should always serialize the value (JSONPayloadConverter is good candidate for it),
+// Several PayloadConverters can share the same encoding if they support different value types (i.e. gogo and
+// APIv2 proto messages). During deserialization the first of them which supports the value type is used.
 func NewCompositeDataConverter(payloadConverters ...PayloadConverter) *CompositeDataConverter {
 	dc := &CompositeDataConverter{
-		payloadConverters: make(map[string]PayloadConverter, len(payloadConverters)),
-		orderedEncodings:  make([]string, len(payloadConverters)),
+		payloadConverters:        make(map[string][]PayloadConverter, len(payloadConverters)),
+		orderedPayloadConverters: payloadConverters,
 	}
 
-	for i, payloadConverter := range payloadConverters {
-		dc.payloadConverters[payloadConverter.Encoding()] = payloadConverter
-		dc.orderedEncodings[i] = payloadConverter.Encoding()
+	for _, payloadConverter := range payloadConverters {
+		enc := payloadConverter.Encoding()
+		dc.payloadConverters[enc] = append(dc.payloadConverters[enc], payloadConverter)
 	}
 
 	return dc
@@ -97,8 +100,7 @@ func (dc *CompositeDataConverter) FromPayloads(payloads *commonpb.Payloads, valu
 
 // ToPayload converts single value to payload.
 func (dc *CompositeDataConverter) ToPayload(value interface{}) (*commonpb.Payload, error) {
-	for _, enc := range dc.orderedEncodings {
-		payloadConverter := dc.payloadConverters[enc]
+	for _, payloadConverter := range dc.orderedPayloadConverters {
 		payload, err := payloadConverter.ToPayload(value)
 		if err != nil {
 			return nil, err
@@ -122,12 +124,22 @@ func (dc *CompositeDataConverter) FromPayload(payload *commonpb.Payload, valuePt
 		return err
 	}
 
-	payloadConverter, ok := dc.payloadConverters[enc]
+	payloadConverters, ok := dc.payloadConverters[enc]
 	if !ok {
 		return fmt.Errorf("encoding %s: %w", enc, ErrEncodingIsNotSupported)
 	}
 
-	return payloadConverter.FromPayload(payload, valuePtr)
+	for i, payloadConverter := range payloadConverters {
+		err = payloadConverter.FromPayload(payload, valuePtr)
+		if i < len(payloadConverters)-1 &&
+			(errors.Is(err, ErrValueDoesntImplementProtoMessage) || errors.Is(err, ErrValueDoesntImplementProtoUnmarshaler)) {
+			// Value type is not supported, try next PayloadConverter with the same encoding.
+			continue
+		}
+		break
+	}
+
+	return err
 }
 
 // ToString converts payload object into human readable string.
@@ -141,12 +153,12 @@ func (dc *CompositeDataConverter) ToString(payload *commonpb.Payload) string {
 		return err.Error()
 	}
 
-	payloadConverter, ok := dc.payloadConverters[enc]
+	payloadConverters, ok := dc.payloadConverters[enc]
 	if !ok {
 		return fmt.Errorf("encoding %s: %w", enc, ErrEncodingIsNotSupported).Error()
 	}
 
-	return payloadConverter.ToString(payload)
+	return payloadConverters[0].ToString(payload)
 }
 
 // ToStrings converts payloads object into human readable strings.
@@ -32,6 +32,10 @@ var (
 		// Although they check for different interfaces (proto.Message and proto.Marshaler) all proto messages implements both interfaces.
 		NewProtoJSONPayloadConverter(),
 		// NewProtoPayloadConverter(),
+		// APIv2 proto converters share encodings with gogo ones, and handle only google.golang.org/protobuf messages.
+		// Binary converter is used only to decode payloads as JSON converter is used first to encode.
+		NewProtoV2JSONPayloadConverter(),
+		NewProtoV2PayloadConverter(),
 		NewJSONPayloadConverter(),
 	)
 )
@@ -0,0 +1,212 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package converter
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	commonpb "go.temporal.io/api/common/v1"
+)
+
+type (
+	// KeyProvider supplies AES keys to encryption codec.
+	// Keys are identified by ID which is stored with every encrypted payload.
+	// To rotate keys, start returning new key from GetEncryptionKey but keep old keys available through
+	// GetDecryptionKey as long as there are workflow histories which were encrypted with them.
+	KeyProvider interface {
+		// GetEncryptionKey returns ID and value of the key which is used to encrypt new payloads.
+		GetEncryptionKey() (keyID string, key []byte, err error)
+		// GetDecryptionKey returns value of the key with specified ID.
+		GetDecryptionKey(keyID string) ([]byte, error)
+	}
+
+	encryptionCodec struct {
+		keyProvider KeyProvider
+	}
+
+	staticKeyProvider struct {
+		encryptionKeyID string
+		keys            map[string][]byte
+	}
+)
+
+// NewEncryptionCodec creates PayloadCodec which encrypts payloads with AES-GCM.
+// Encrypted payload has MetadataEncodingEncrypted encoding, ID of the key and original encoding are stored
+// in MetadataEncryptionKeyID and MetadataEncryptionOriginalEncoding metadata.
+// Payloads which are not encrypted are decoded as is.
+func NewEncryptionCodec(keyProvider KeyProvider) PayloadCodec {
+	return &encryptionCodec{
+		keyProvider: keyProvider,
+	}
+}
+
+// NewEncryptionDataConverter creates DataConverter which encrypts payloads of parent DataConverter
+// with encryption codec.
+func NewEncryptionDataConverter(parent DataConverter, keyProvider KeyProvider) *CodecDataConverter {
+	return NewCodecDataConverter(parent, NewEncryptionCodec(keyProvider))
+}
+
+// NewStaticKeyProvider creates KeyProvider from fixed set of keys.
+// Key with encryptionKeyID is used for encryption, all keys are used for decryption.
+func NewStaticKeyProvider(encryptionKeyID string, keys map[string][]byte) KeyProvider {
+	return &staticKeyProvider{
+		encryptionKeyID: encryptionKeyID,
+		keys:            keys,
+	}
+}
+
+func (c *encryptionCodec) Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, payload := range payloads {
+		encryptedPayload, err := c.encrypt(payload)
+		if err != nil {
+			return nil, fmt.Errorf("payload item %d: %w", i, err)
+		}
+		result[i] = encryptedPayload
+	}
+	return result, nil
+}
+
+func (c *encryptionCodec) Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, payload := range payloads {
+		decryptedPayload, err := c.decrypt(payload)
+		if err != nil {
+			return nil, fmt.Errorf("payload item %d: %w", i, err)
+		}
+		result[i] = decryptedPayload
+	}
+	return result, nil
+}
+
+func (c *encryptionCodec) encrypt(payload *commonpb.Payload) (*commonpb.Payload, error) {
+	enc, err := encoding(payload)
+	if err != nil {
+		return nil, err
+	}
+	originalEncoding := []byte(enc)
+
+	keyID, key, err := c.keyProvider.GetEncryptionKey()
+	if err != nil {
+		return nil, fmt.Errorf("encryption key: %w", err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", keyID, err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrUnableToEncode)
+	}
+
+	metadata := make(map[string][]byte, len(payload.GetMetadata())+2)
+	for k, v := range payload.GetMetadata() {
+		metadata[k] = v
+	}
+	metadata[MetadataEncoding] = []byte(MetadataEncodingEncrypted)
+	metadata[MetadataEncryptionKeyID] = []byte(keyID)
+	metadata[MetadataEncryptionOriginalEncoding] = originalEncoding
+
+	return &commonpb.Payload{
+		Metadata: metadata,
+		// Nonce is stored in front of the cipher text. Original encoding is authenticated to prevent its tampering.
+		Data: aead.Seal(nonce, nonce, payload.GetData(), originalEncoding),
+	}, nil
+}
+
+func (c *encryptionCodec) decrypt(payload *commonpb.Payload) (*commonpb.Payload, error) {
+	if string(payload.GetMetadata()[MetadataEncoding]) != MetadataEncodingEncrypted {
+		return payload, nil
+	}
+
+	metadata := payload.GetMetadata()
+	keyID, ok := metadata[MetadataEncryptionKeyID]
+	if !ok {
+		return nil, ErrEncryptionKeyIDIsNotSet
+	}
+	originalEncoding, ok := metadata[MetadataEncryptionOriginalEncoding]
+	if !ok {
+		return nil, ErrEncodingIsNotSet
+	}
+
+	key, err := c.keyProvider.GetDecryptionKey(string(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", keyID, err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", keyID, err)
+	}
+
+	data := payload.GetData()
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted data is too short: %w", ErrUnableToDecode)
+	}
+	plainText, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], originalEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %v: %w", keyID, err, ErrUnableToDecode)
+	}
+
+	decryptedMetadata := make(map[string][]byte, len(metadata))
+	for k, v := range metadata {
+		if k != MetadataEncryptionKeyID && k != MetadataEncryptionOriginalEncoding {
+			decryptedMetadata[k] = v
+		}
+	}
+	decryptedMetadata[MetadataEncoding] = originalEncoding
+
+	return &commonpb.Payload{
+		Metadata: decryptedMetadata,
+		Data:     plainText,
+	}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (kp *staticKeyProvider) GetEncryptionKey() (string, []byte, error) {
+	key, err := kp.GetDecryptionKey(kp.encryptionKeyID)
+	return kp.encryptionKeyID, key, err
+}
+
+func (kp *staticKeyProvider) GetDecryptionKey(keyID string) ([]byte, error) {
+	key, ok := kp.keys[keyID]
+	if !ok {
+		return nil, ErrEncryptionKeyIsNotFound
+	}
+	return key, nil
+}
@@ -0,0 +1,123 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package converter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+)
+
+var (
+	testKey1 = []byte("0123456789abcdef0123456789abcdef")
+	testKey2 = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func TestEncryptionDataConverter(t *testing.T) {
+	t.Parallel()
+	dc := NewEncryptionDataConverter(defaultDataConverter, NewStaticKeyProvider("key1", map[string][]byte{"key1": testKey1}))
+
+	payloads, err := dc.ToPayloads("secret value", 42, []byte("bytes"), nil)
+	require.NoError(t, err)
+	require.Len(t, payloads.GetPayloads(), 4)
+
+	for _, payload := range payloads.GetPayloads() {
+		require.Equal(t, MetadataEncodingEncrypted, string(payload.GetMetadata()[MetadataEncoding]))
+		require.Equal(t, "key1", string(payload.GetMetadata()[MetadataEncryptionKeyID]))
+	}
+	require.Equal(t, MetadataEncodingJSON, string(payloads.GetPayloads()[0].GetMetadata()[MetadataEncryptionOriginalEncoding]))
+	require.Equal(t, MetadataEncodingNil, string(payloads.GetPayloads()[3].GetMetadata()[MetadataEncryptionOriginalEncoding]))
+	require.NotContains(t, string(payloads.GetPayloads()[0].GetData()), "secret value")
+
+	var s string
+	var i int
+	var b []byte
+	var n *int
+	require.NoError(t, dc.FromPayloads(payloads, &s, &i, &b, &n))
+	require.Equal(t, "secret value", s)
+	require.Equal(t, 42, i)
+	require.Equal(t, []byte("bytes"), b)
+	require.Nil(t, n)
+
+	require.Equal(t, []string{`secret value`, "42", "Ynl0ZXM", "nil"}, dc.ToStrings(payloads))
+}
+
+func TestEncryptionDataConverter_KeyRotation(t *testing.T) {
+	t.Parallel()
+	keys := map[string][]byte{"key1": testKey1}
+	oldPayload, err := NewEncryptionDataConverter(defaultDataConverter, NewStaticKeyProvider("key1", keys)).ToPayload("old")
+	require.NoError(t, err)
+
+	keys["key2"] = testKey2
+	dc := NewEncryptionDataConverter(defaultDataConverter, NewStaticKeyProvider("key2", keys))
+	newPayload, err := dc.ToPayload("new")
+	require.NoError(t, err)
+	require.Equal(t, "key2", string(newPayload.GetMetadata()[MetadataEncryptionKeyID]))
+
+	var s string
+	require.NoError(t, dc.FromPayload(oldPayload, &s))
+	require.Equal(t, "old", s)
+	require.NoError(t, dc.FromPayload(newPayload, &s))
+	require.Equal(t, "new", s)
+
+	delete(keys, "key1")
+	err = dc.FromPayload(oldPayload, &s)
+	require.True(t, errors.Is(err, ErrEncryptionKeyIsNotFound))
+	require.Contains(t, dc.ToString(oldPayload), ErrEncryptionKeyIsNotFound.Error())
+}
+
+func TestEncryptionDataConverter_Tampering(t *testing.T) {
+	t.Parallel()
+	dc := NewEncryptionDataConverter(defaultDataConverter, NewStaticKeyProvider("key1", map[string][]byte{"key1": testKey1}))
+	payload, err := dc.ToPayload("value")
+	require.NoError(t, err)
+
+	payload.Metadata[MetadataEncryptionOriginalEncoding] = []byte(MetadataEncodingBinary)
+	var s string
+	err = dc.FromPayload(payload, &s)
+	require.True(t, errors.Is(err, ErrUnableToDecode))
+
+	wrongKey := NewEncryptionDataConverter(defaultDataConverter, NewStaticKeyProvider("key1", map[string][]byte{"key1": testKey2}))
+	payload, err = dc.ToPayload("value")
+	require.NoError(t, err)
+	err = wrongKey.FromPayload(payload, &s)
+	require.True(t, errors.Is(err, ErrUnableToDecode))
+}
+
+func TestEncryptionDataConverter_NotEncryptedPayload(t *testing.T) {
+	t.Parallel()
+	dc := NewEncryptionDataConverter(defaultDataConverter, NewStaticKeyProvider("key1", map[string][]byte{"key1": testKey1}))
+	payload, err := defaultDataConverter.ToPayload("plain")
+	require.NoError(t, err)
+
+	var s string
+	require.NoError(t, dc.FromPayload(payload, &s))
+	require.Equal(t, "plain", s)
+
+	err = dc.FromPayload(&commonpb.Payload{Metadata: map[string][]byte{MetadataEncoding: []byte(MetadataEncodingEncrypted)}}, &s)
+	require.True(t, errors.Is(err, ErrEncryptionKeyIDIsNotSet))
+}
@@ -47,4 +47,14 @@ var (
 	ErrValueDoesntImplementProtoMessage = errors.New("value doesn't implement proto.Message")
 	// ErrValueDoesntImplementProtoUnmarshaler is returned when value doesn't implement proto.Unmarshaler.
 	ErrValueDoesntImplementProtoUnmarshaler = errors.New("value doesn't implement proto.Unmarshaler")
+	// ErrEncryptionKeyIDIsNotSet is returned when encrypted payload doesn't have encryption key ID metadata.
+	ErrEncryptionKeyIDIsNotSet = errors.New("encryption key ID metadata is not set")
+	// ErrEncryptionKeyIsNotFound is returned when KeyProvider doesn't have requested key.
+	ErrEncryptionKeyIsNotFound = errors.New("encryption key is not found")
+	// ErrSchemaVersionIsNotSupported is returned when payload schema version is newer than the registered one.
+	ErrSchemaVersionIsNotSupported = errors.New("payload schema version is not supported")
+	// ErrDecompressedPayloadTooLarge is returned when a compressed payload decompresses to more than the allowed size.
+	ErrDecompressedPayloadTooLarge = errors.New("decompressed payload is too large")
+	// ErrSchemaUpcasterIsNotFound is returned when there is no upcaster to migrate payload to the registered schema version.
+	ErrSchemaUpcasterIsNotFound = errors.New("payload schema upcaster is not found")
 )
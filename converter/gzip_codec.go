@@ -0,0 +1,186 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package converter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	commonpb "go.temporal.io/api/common/v1"
+)
+
+const (
+	defaultGzipCodecMinBytes = 1024
+	// defaultGzipMaxDecompressedBytes limits decompressed payloads, so a small compressed payload
+	// cannot exhaust worker memory.
+	defaultGzipMaxDecompressedBytes = 64 * 1024 * 1024
+)
+
+type (
+	// GzipCodecOptions are optional parameters for NewGzipCodec.
+	GzipCodecOptions struct {
+		// Optional: Payloads with data smaller than MinBytes are not compressed. Small payloads rarely benefit
+		// from compression but compressing them costs CPU on every replay.
+		// default: 1024
+		MinBytes int
+
+		// Optional: Compression level, see compress/gzip.
+		// default: gzip.DefaultCompression
+		Level int
+
+		// Optional: Maximum size of a decompressed payload. Decoding fails with ErrDecompressedPayloadTooLarge
+		// for payloads that decompress to more data, which protects the worker from compression bombs.
+		// default: 64MB
+		MaxDecompressedBytes int
+	}
+
+	gzipCodec struct {
+		minBytes             int
+		level                int
+		maxDecompressedBytes int
+	}
+)
+
+// NewGzipCodec creates PayloadCodec which compresses payloads with gzip.
+// Compressed payload has MetadataEncodingGzip encoding, original encoding is stored
+// in MetadataCompressionOriginalEncoding metadata.
+// Payloads smaller than GzipCodecOptions.MinBytes and payloads which don't become smaller are left untouched.
+func NewGzipCodec(options GzipCodecOptions) PayloadCodec {
+	if options.MinBytes == 0 {
+		options.MinBytes = defaultGzipCodecMinBytes
+	}
+	if options.Level == 0 {
+		options.Level = gzip.DefaultCompression
+	}
+	if options.MaxDecompressedBytes == 0 {
+		options.MaxDecompressedBytes = defaultGzipMaxDecompressedBytes
+	}
+	return &gzipCodec{
+		minBytes:             options.MinBytes,
+		level:                options.Level,
+		maxDecompressedBytes: options.MaxDecompressedBytes,
+	}
+}
+
+func (c *gzipCodec) Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, payload := range payloads {
+		compressedPayload, err := c.compress(payload)
+		if err != nil {
+			return nil, fmt.Errorf("payload item %d: %w", i, err)
+		}
+		result[i] = compressedPayload
+	}
+	return result, nil
+}
+
+func (c *gzipCodec) Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, payload := range payloads {
+		decompressedPayload, err := c.decompress(payload)
+		if err != nil {
+			return nil, fmt.Errorf("payload item %d: %w", i, err)
+		}
+		result[i] = decompressedPayload
+	}
+	return result, nil
+}
+
+func (c *gzipCodec) compress(payload *commonpb.Payload) (*commonpb.Payload, error) {
+	if len(payload.GetData()) < c.minBytes {
+		return payload, nil
+	}
+	originalEncoding, err := encoding(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, c.level)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrUnableToEncode)
+	}
+	if _, err := w.Write(payload.GetData()); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrUnableToEncode)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrUnableToEncode)
+	}
+	if buf.Len() >= len(payload.GetData()) {
+		return payload, nil
+	}
+
+	metadata := make(map[string][]byte, len(payload.GetMetadata())+1)
+	for k, v := range payload.GetMetadata() {
+		metadata[k] = v
+	}
+	metadata[MetadataEncoding] = []byte(MetadataEncodingGzip)
+	metadata[MetadataCompressionOriginalEncoding] = []byte(originalEncoding)
+
+	return &commonpb.Payload{
+		Metadata: metadata,
+		Data:     buf.Bytes(),
+	}, nil
+}
+
+func (c *gzipCodec) decompress(payload *commonpb.Payload) (*commonpb.Payload, error) {
+	metadata := payload.GetMetadata()
+	if string(metadata[MetadataEncoding]) != MetadataEncodingGzip {
+		return payload, nil
+	}
+	originalEncoding, ok := metadata[MetadataCompressionOriginalEncoding]
+	if !ok {
+		return nil, ErrEncodingIsNotSet
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(payload.GetData()))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrUnableToDecode)
+	}
+	// Read one byte over the limit to tell a payload of exactly the maximum size from a larger one.
+	data, err := ioutil.ReadAll(io.LimitReader(r, int64(c.maxDecompressedBytes)+1))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrUnableToDecode)
+	}
+	if len(data) > c.maxDecompressedBytes {
+		return nil, fmt.Errorf("limit is %d bytes: %w", c.maxDecompressedBytes, ErrDecompressedPayloadTooLarge)
+	}
+
+	decompressedMetadata := make(map[string][]byte, len(metadata))
+	for k, v := range metadata {
+		if k != MetadataCompressionOriginalEncoding {
+			decompressedMetadata[k] = v
+		}
+	}
+	decompressedMetadata[MetadataEncoding] = originalEncoding
+
+	return &commonpb.Payload{
+		Metadata: decompressedMetadata,
+		Data:     data,
+	}, nil
+}
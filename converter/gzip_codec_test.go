@@ -0,0 +1,92 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package converter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzipCodec(t *testing.T) {
+	t.Parallel()
+	dc := NewCodecDataConverter(defaultDataConverter, NewGzipCodec(GzipCodecOptions{MinBytes: 100}))
+	large := strings.Repeat("large value ", 100)
+
+	payloads, err := dc.ToPayloads("small", large)
+	require.NoError(t, err)
+	small, compressed := payloads.GetPayloads()[0], payloads.GetPayloads()[1]
+	require.Equal(t, MetadataEncodingJSON, string(small.GetMetadata()[MetadataEncoding]))
+	require.Equal(t, `"small"`, string(small.GetData()))
+	require.Equal(t, MetadataEncodingGzip, string(compressed.GetMetadata()[MetadataEncoding]))
+	require.Equal(t, MetadataEncodingJSON, string(compressed.GetMetadata()[MetadataCompressionOriginalEncoding]))
+	require.Less(t, len(compressed.GetData()), len(large))
+
+	var s1, s2 string
+	require.NoError(t, dc.FromPayloads(payloads, &s1, &s2))
+	require.Equal(t, "small", s1)
+	require.Equal(t, large, s2)
+	require.Equal(t, []string{"small", large}, dc.ToStrings(payloads))
+
+	// Compressed payloads are not readable without the codec.
+	err = defaultDataConverter.FromPayload(compressed, &s2)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrEncodingIsNotSupported))
+}
+
+func TestGzipCodec_Incompressible(t *testing.T) {
+	t.Parallel()
+	dc := NewCodecDataConverter(defaultDataConverter, NewGzipCodec(GzipCodecOptions{MinBytes: 1}))
+
+	payload, err := dc.ToPayload("x")
+	require.NoError(t, err)
+	require.Equal(t, MetadataEncodingJSON, string(payload.GetMetadata()[MetadataEncoding]))
+
+	payload.Metadata[MetadataEncoding] = []byte(MetadataEncodingGzip)
+	var s string
+	require.Error(t, dc.FromPayload(payload, &s))
+}
+
+func TestGzipCodec_MaxDecompressedBytes(t *testing.T) {
+	t.Parallel()
+	large := strings.Repeat("a", 10000)
+	encoder := NewCodecDataConverter(defaultDataConverter, NewGzipCodec(GzipCodecOptions{}))
+	payload, err := encoder.ToPayload(large)
+	require.NoError(t, err)
+	require.Equal(t, MetadataEncodingGzip, string(payload.GetMetadata()[MetadataEncoding]))
+
+	dc := NewCodecDataConverter(defaultDataConverter, NewGzipCodec(GzipCodecOptions{MaxDecompressedBytes: 1000}))
+	var s string
+	err = dc.FromPayload(payload, &s)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrDecompressedPayloadTooLarge))
+
+	// The limit is inclusive, the JSON string is quoted.
+	dc = NewCodecDataConverter(defaultDataConverter, NewGzipCodec(GzipCodecOptions{MaxDecompressedBytes: len(large) + 2}))
+	require.NoError(t, dc.FromPayload(payload, &s))
+	require.Equal(t, large, s)
+}
@@ -32,27 +32,57 @@ import (
 	commonpb "go.temporal.io/api/common/v1"
 )
 
-// JSONPayloadConverter converts to/from JSON.
-type JSONPayloadConverter struct {
-}
+type (
+	// JSONPayloadConverter converts to/from JSON.
+	JSONPayloadConverter struct {
+		schemas *PayloadSchemaRegistry
+	}
+
+	// JSONPayloadConverterOptions are optional parameters for NewJSONPayloadConverterWithOptions.
+	JSONPayloadConverterOptions struct {
+		// Optional: Schemas of versioned types. Payloads of registered types are stamped with schema name and
+		// version, and documents of older versions are migrated with the registered upcasters when decoded.
+		// default: nil, payloads are not versioned
+		Schemas *PayloadSchemaRegistry
+	}
+)
 
 // NewJSONPayloadConverter creates new instance of JSONPayloadConverter.
 func NewJSONPayloadConverter() *JSONPayloadConverter {
 	return &JSONPayloadConverter{}
 }
 
+// NewJSONPayloadConverterWithOptions creates new instance of JSONPayloadConverter with options.
+// Use it instead of NewJSONPayloadConverter when composing a data converter with NewCompositeDataConverter.
+func NewJSONPayloadConverterWithOptions(options JSONPayloadConverterOptions) *JSONPayloadConverter {
+	return &JSONPayloadConverter{
+		schemas: options.Schemas,
+	}
+}
+
 // ToPayload converts single value to payload.
 func (c *JSONPayloadConverter) ToPayload(value interface{}) (*commonpb.Payload, error) {
 	data, err := json.Marshal(value)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrUnableToEncode, err)
 	}
-	return newPayload(data, c), nil
+	payload := newPayload(data, c)
+	if c.schemas != nil {
+		c.schemas.stamp(payload, value)
+	}
+	return payload, nil
 }
 
 // FromPayload converts single value from payload.
 func (c *JSONPayloadConverter) FromPayload(payload *commonpb.Payload, valuePtr interface{}) error {
-	err := json.Unmarshal(payload.GetData(), valuePtr)
+	data := payload.GetData()
+	if c.schemas != nil {
+		var err error
+		if data, err = c.schemas.upcast(payload, valuePtr); err != nil {
+			return err
+		}
+	}
+	err := json.Unmarshal(data, valuePtr)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrUnableToDecode, err)
 	}
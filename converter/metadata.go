@@ -37,4 +37,23 @@ const (
 	MetadataEncodingProtoJSON = "json/protobuf"
 	// MetadataEncodingProto is "binary/protobuf"
 	MetadataEncodingProto = "binary/protobuf"
+	// MetadataEncodingEncrypted is "binary/encrypted"
+	MetadataEncodingEncrypted = "binary/encrypted"
+	// MetadataEncodingGzip is "binary/gzip"
+	MetadataEncodingGzip = "binary/gzip"
+	// MetadataEncodingBlobReference is "binary/blob-reference"
+	MetadataEncodingBlobReference = "binary/blob-reference"
+
+	// MetadataMessageType is "messageType"
+	MetadataMessageType = "messageType"
+	// MetadataEncryptionKeyID is "encryption-key-id"
+	MetadataEncryptionKeyID = "encryption-key-id"
+	// MetadataEncryptionOriginalEncoding is "encryption-original-encoding"
+	MetadataEncryptionOriginalEncoding = "encryption-original-encoding"
+	// MetadataCompressionOriginalEncoding is "compression-original-encoding"
+	MetadataCompressionOriginalEncoding = "compression-original-encoding"
+	// MetadataSchemaType is "schema-type"
+	MetadataSchemaType = "schema-type"
+	// MetadataSchemaVersion is "schema-version"
+	MetadataSchemaVersion = "schema-version"
 )
@@ -25,11 +25,14 @@
 package converter
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	commonpb "go.temporal.io/api/common/v1"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+	"google.golang.org/protobuf/types/known/typepb"
 )
 
 type testStruct struct {
@@ -77,6 +80,82 @@ func TestProtoPayloadConverter(t *testing.T) {
 	assert.Equal(t, "CgNxd2U", s)
 }
 
+func TestProtoV2JsonPayloadConverter(t *testing.T) {
+	pc := NewProtoV2JSONPayloadConverter()
+
+	tp := &typepb.Type{Name: "qwe", SourceContext: &sourcecontextpb.SourceContext{FileName: "asd"}}
+	payload, err := pc.ToPayload(tp)
+	require.NoError(t, err)
+	assert.Equal(t, "google.protobuf.Type", string(payload.Metadata[MetadataMessageType]))
+	tp2 := &typepb.Type{}
+	err = pc.FromPayload(payload, &tp2)
+	require.NoError(t, err)
+	assert.Equal(t, "qwe", tp2.Name)
+	assert.Equal(t, "asd", tp2.SourceContext.FileName)
+
+	var tp3 *typepb.Type
+	err = pc.FromPayload(payload, &tp3)
+	require.NoError(t, err)
+	assert.Equal(t, "qwe", tp3.Name)
+
+	var sc *sourcecontextpb.SourceContext
+	err = pc.FromPayload(payload, &sc)
+	assert.True(t, errors.Is(err, ErrUnableToDecode))
+
+	s := pc.ToString(payload)
+	assert.JSONEq(t, `{"name":"qwe","sourceContext":{"fileName":"asd"}}`, s)
+
+	payload, err = pc.ToPayload(&commonpb.WorkflowType{Name: "qwe"})
+	require.NoError(t, err)
+	assert.Nil(t, payload)
+}
+
+func TestProtoV2PayloadConverter(t *testing.T) {
+	pc := NewProtoV2PayloadConverter()
+
+	tp := &typepb.Type{Name: "qwe"}
+	payload, err := pc.ToPayload(tp)
+	require.NoError(t, err)
+	assert.Equal(t, "google.protobuf.Type", string(payload.Metadata[MetadataMessageType]))
+	tp2 := &typepb.Type{}
+	err = pc.FromPayload(payload, &tp2)
+	require.NoError(t, err)
+	assert.Equal(t, "qwe", tp2.Name)
+
+	var tp3 *typepb.Type
+	err = pc.FromPayload(payload, &tp3)
+	require.NoError(t, err)
+	assert.Equal(t, "qwe", tp3.Name)
+
+	s := pc.ToString(payload)
+	assert.Equal(t, "CgNxd2U", s)
+}
+
+func TestDefaultDataConverterProtoMessages(t *testing.T) {
+	dc := GetDefaultDataConverter()
+
+	payloads, err := dc.ToPayloads(&commonpb.WorkflowType{Name: "gogo"}, &typepb.Type{Name: "v2"})
+	require.NoError(t, err)
+	for _, payload := range payloads.Payloads {
+		assert.Equal(t, MetadataEncodingProtoJSON, string(payload.Metadata[MetadataEncoding]))
+	}
+	assert.Empty(t, payloads.Payloads[0].Metadata[MetadataMessageType])
+	assert.Equal(t, "google.protobuf.Type", string(payloads.Payloads[1].Metadata[MetadataMessageType]))
+
+	var wt *commonpb.WorkflowType
+	var tp *typepb.Type
+	require.NoError(t, dc.FromPayloads(payloads, &wt, &tp))
+	assert.Equal(t, "gogo", wt.Name)
+	assert.Equal(t, "v2", tp.Name)
+
+	// Binary APIv2 payloads are decoded too.
+	payload, err := NewProtoV2PayloadConverter().ToPayload(&typepb.Type{Name: "binary"})
+	require.NoError(t, err)
+	var tp2 *typepb.Type
+	require.NoError(t, dc.FromPayload(payload, &tp2))
+	assert.Equal(t, "binary", tp2.Name)
+}
+
 func TestJsonPayloadConverter(t *testing.T) {
 	pc := NewJSONPayloadConverter()
 
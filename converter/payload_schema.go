@@ -0,0 +1,199 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+
+	commonpb "go.temporal.io/api/common/v1"
+)
+
+type (
+	// Upcaster migrates JSON document of a schema from one version to the next one.
+	// A common implementation unmarshals data into a copy of the old struct and marshals the new struct built from it.
+	Upcaster func(data json.RawMessage) (json.RawMessage, error)
+
+	// PayloadSchemaRegistry holds the current versions of Go types stored in payloads by JSONPayloadConverter,
+	// together with the upcasters which migrate documents written with older versions.
+	// Payloads of registered types are stamped with MetadataSchemaType and MetadataSchemaVersion.
+	// When a payload with an older version is decoded, upcasters are applied one version at a time before
+	// the document is unmarshalled into the current type. Payloads written before the type was registered
+	// don't have schema metadata and are treated as version 1 when decoded into a registered type.
+	PayloadSchemaRegistry struct {
+		lock   sync.RWMutex
+		byType map[reflect.Type]*payloadSchema
+		byName map[string]*payloadSchema
+	}
+
+	payloadSchema struct {
+		name    string
+		version int
+		// upcasters by the version they migrate from.
+		upcasters map[int]Upcaster
+	}
+)
+
+// NewPayloadSchemaRegistry creates new instance of PayloadSchemaRegistry.
+func NewPayloadSchemaRegistry() *PayloadSchemaRegistry {
+	return &PayloadSchemaRegistry{
+		byType: make(map[reflect.Type]*payloadSchema),
+		byName: make(map[string]*payloadSchema),
+	}
+}
+
+// RegisterSchema registers the current version of the type of value under name.
+// Pointer and non pointer values of the type share the schema. Versions start with 1.
+func (r *PayloadSchemaRegistry) RegisterSchema(value interface{}, name string, version int) error {
+	if value == nil {
+		return fmt.Errorf("schema %q: value must not be nil", name)
+	}
+	if name == "" {
+		return fmt.Errorf("schema of %T: name must not be empty", value)
+	}
+	if version < 1 {
+		return fmt.Errorf("schema %q: version %d must be positive", name, version)
+	}
+
+	valueType := schemaValueType(reflect.TypeOf(value))
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if schema, ok := r.byName[name]; ok {
+		return fmt.Errorf("schema %q is already registered with version %d", name, schema.version)
+	}
+	if schema, ok := r.byType[valueType]; ok {
+		return fmt.Errorf("type %v is already registered as schema %q", valueType, schema.name)
+	}
+	schema := &payloadSchema{
+		name:      name,
+		version:   version,
+		upcasters: make(map[int]Upcaster),
+	}
+	r.byType[valueType] = schema
+	r.byName[name] = schema
+	return nil
+}
+
+// RegisterUpcaster registers upcaster which migrates documents of the schema from fromVersion to fromVersion+1.
+// Schema must be registered first and fromVersion must be lower than its current version.
+func (r *PayloadSchemaRegistry) RegisterUpcaster(name string, fromVersion int, upcaster Upcaster) error {
+	if upcaster == nil {
+		return fmt.Errorf("schema %q: upcaster must not be nil", name)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	schema, ok := r.byName[name]
+	if !ok {
+		return fmt.Errorf("schema %q is not registered", name)
+	}
+	if fromVersion < 1 || fromVersion >= schema.version {
+		return fmt.Errorf("schema %q: upcaster version %d must be between 1 and %d", name, fromVersion, schema.version-1)
+	}
+	if _, ok := schema.upcasters[fromVersion]; ok {
+		return fmt.Errorf("schema %q: upcaster from version %d is already registered", name, fromVersion)
+	}
+	schema.upcasters[fromVersion] = upcaster
+	return nil
+}
+
+func (r *PayloadSchemaRegistry) schemaOf(valueType reflect.Type) *payloadSchema {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.byType[schemaValueType(valueType)]
+}
+
+// stamp sets schema metadata on payload if value type is registered.
+func (r *PayloadSchemaRegistry) stamp(payload *commonpb.Payload, value interface{}) {
+	if value == nil {
+		return
+	}
+	schema := r.schemaOf(reflect.TypeOf(value))
+	if schema == nil {
+		return
+	}
+	payload.Metadata[MetadataSchemaType] = []byte(schema.name)
+	payload.Metadata[MetadataSchemaVersion] = []byte(strconv.Itoa(schema.version))
+}
+
+// upcast migrates payload data to the current version of its schema.
+func (r *PayloadSchemaRegistry) upcast(payload *commonpb.Payload, valuePtr interface{}) ([]byte, error) {
+	data := payload.GetData()
+	var schema *payloadSchema
+	version := 1
+	if name, ok := payload.GetMetadata()[MetadataSchemaType]; ok {
+		r.lock.RLock()
+		schema = r.byName[string(name)]
+		r.lock.RUnlock()
+		if schema == nil {
+			// Schema isn't known to this process, decode the document as is.
+			return data, nil
+		}
+		var err error
+		if version, err = strconv.Atoi(string(payload.GetMetadata()[MetadataSchemaVersion])); err != nil {
+			return nil, fmt.Errorf("%w: schema %q: invalid version: %v", ErrUnableToDecode, schema.name, err)
+		}
+	} else if valuePtr != nil {
+		schema = r.schemaOf(reflect.TypeOf(valuePtr))
+	}
+	if schema == nil {
+		return data, nil
+	}
+
+	if version > schema.version {
+		return nil, fmt.Errorf("%w: schema %q version %d, registered version %d", ErrSchemaVersionIsNotSupported, schema.name, version, schema.version)
+	}
+	r.lock.RLock()
+	var upcasters []Upcaster
+	for v := version; v < schema.version; v++ {
+		upcaster, ok := schema.upcasters[v]
+		if !ok {
+			r.lock.RUnlock()
+			return nil, fmt.Errorf("%w: schema %q from version %d", ErrSchemaUpcasterIsNotFound, schema.name, v)
+		}
+		upcasters = append(upcasters, upcaster)
+	}
+	r.lock.RUnlock()
+
+	for _, upcaster := range upcasters {
+		upcasted, err := upcaster(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: schema %q upcaster from version %d: %v", ErrUnableToDecode, schema.name, version, err)
+		}
+		data = upcasted
+		version++
+	}
+	return data, nil
+}
+
+func schemaValueType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,142 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package converter
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderV1 is the first version of orderV3 kept to write documents of the old schema.
+type orderV1 struct {
+	Item string
+}
+
+// orderV2 is the second version of orderV3.
+type orderV2 struct {
+	Item     string
+	Quantity int
+}
+
+type orderV3 struct {
+	Items    []string
+	Quantity int
+}
+
+func newOrderSchemas(t *testing.T) *PayloadSchemaRegistry {
+	schemas := NewPayloadSchemaRegistry()
+	require.NoError(t, schemas.RegisterSchema(orderV3{}, "order", 3))
+	require.NoError(t, schemas.RegisterUpcaster("order", 1, func(data json.RawMessage) (json.RawMessage, error) {
+		var v1 orderV1
+		if err := json.Unmarshal(data, &v1); err != nil {
+			return nil, err
+		}
+		return json.Marshal(orderV2{Item: v1.Item, Quantity: 1})
+	}))
+	require.NoError(t, schemas.RegisterUpcaster("order", 2, func(data json.RawMessage) (json.RawMessage, error) {
+		var v2 orderV2
+		if err := json.Unmarshal(data, &v2); err != nil {
+			return nil, err
+		}
+		return json.Marshal(orderV3{Items: []string{v2.Item}, Quantity: v2.Quantity})
+	}))
+	return schemas
+}
+
+func TestPayloadSchemaRegistry_Register(t *testing.T) {
+	schemas := NewPayloadSchemaRegistry()
+	require.Error(t, schemas.RegisterSchema(nil, "order", 1))
+	require.Error(t, schemas.RegisterSchema(orderV3{}, "", 1))
+	require.Error(t, schemas.RegisterSchema(orderV3{}, "order", 0))
+	require.NoError(t, schemas.RegisterSchema(&orderV3{}, "order", 2))
+	require.Error(t, schemas.RegisterSchema(orderV2{}, "order", 1))
+	require.Error(t, schemas.RegisterSchema(orderV3{}, "order3", 1))
+
+	noop := func(data json.RawMessage) (json.RawMessage, error) { return data, nil }
+	require.Error(t, schemas.RegisterUpcaster("unknown", 1, noop))
+	require.Error(t, schemas.RegisterUpcaster("order", 2, noop))
+	require.Error(t, schemas.RegisterUpcaster("order", 1, nil))
+	require.NoError(t, schemas.RegisterUpcaster("order", 1, noop))
+	require.Error(t, schemas.RegisterUpcaster("order", 1, noop))
+}
+
+func TestJSONPayloadConverter_Schemas(t *testing.T) {
+	schemas := newOrderSchemas(t)
+	pc := NewJSONPayloadConverterWithOptions(JSONPayloadConverterOptions{Schemas: schemas})
+
+	payload, err := pc.ToPayload(&orderV3{Items: []string{"apple"}, Quantity: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "order", string(payload.Metadata[MetadataSchemaType]))
+	assert.Equal(t, "3", string(payload.Metadata[MetadataSchemaVersion]))
+	var order orderV3
+	require.NoError(t, pc.FromPayload(payload, &order))
+	assert.Equal(t, orderV3{Items: []string{"apple"}, Quantity: 2}, order)
+
+	// Unregistered types are not stamped.
+	payload, err = pc.ToPayload(orderV2{Item: "pear"})
+	require.NoError(t, err)
+	assert.NotContains(t, payload.Metadata, MetadataSchemaType)
+}
+
+func TestJSONPayloadConverter_Upcast(t *testing.T) {
+	schemas := newOrderSchemas(t)
+	pc := NewJSONPayloadConverterWithOptions(JSONPayloadConverterOptions{Schemas: schemas})
+
+	payload, err := NewJSONPayloadConverter().ToPayload(orderV2{Item: "apple", Quantity: 5})
+	require.NoError(t, err)
+	payload.Metadata[MetadataSchemaType] = []byte("order")
+	payload.Metadata[MetadataSchemaVersion] = []byte("2")
+	var order orderV3
+	require.NoError(t, pc.FromPayload(payload, &order))
+	assert.Equal(t, orderV3{Items: []string{"apple"}, Quantity: 5}, order)
+
+	// Payloads written before the schema was registered are version 1.
+	payload, err = NewJSONPayloadConverter().ToPayload(orderV1{Item: "pear"})
+	require.NoError(t, err)
+	var orderPtr *orderV3
+	require.NoError(t, pc.FromPayload(payload, &orderPtr))
+	assert.Equal(t, &orderV3{Items: []string{"pear"}, Quantity: 1}, orderPtr)
+
+	payload.Metadata[MetadataSchemaType] = []byte("order")
+	payload.Metadata[MetadataSchemaVersion] = []byte("4")
+	err = pc.FromPayload(payload, &order)
+	assert.True(t, errors.Is(err, ErrSchemaVersionIsNotSupported))
+}
+
+func TestJSONPayloadConverter_UpcasterIsNotFound(t *testing.T) {
+	schemas := NewPayloadSchemaRegistry()
+	require.NoError(t, schemas.RegisterSchema(orderV3{}, "order", 2))
+	pc := NewJSONPayloadConverterWithOptions(JSONPayloadConverterOptions{Schemas: schemas})
+
+	payload, err := NewJSONPayloadConverter().ToPayload(orderV1{Item: "pear"})
+	require.NoError(t, err)
+	var order orderV3
+	err = pc.FromPayload(payload, &order)
+	assert.True(t, errors.Is(err, ErrSchemaUpcasterIsNotFound))
+}
@@ -52,7 +52,7 @@ func NewProtoJSONPayloadConverter() *ProtoJSONPayloadConverter {
 
 // ToPayload converts single proto value to payload.
 func (c *ProtoJSONPayloadConverter) ToPayload(value interface{}) (*commonpb.Payload, error) {
-	if valueProto, ok := value.(proto.Message); ok {
+	if valueProto, ok := value.(proto.Message); ok && !isProtoV2Message(value) {
 		var buf bytes.Buffer
 		err := c.marshaler.Marshal(&buf, valueProto)
 		if err != nil {
@@ -72,7 +72,7 @@ func (c *ProtoJSONPayloadConverter) FromPayload(payload *commonpb.Payload, value
 
 	protoValue := value.Interface() // protoValue is of type i.e. *commonpb.WorkflowType
 	protoMessage, ok := protoValue.(proto.Message)
-	if !ok {
+	if !ok || isProtoV2Message(protoValue) {
 		return fmt.Errorf("value: %v of type: %T: %w", value, value, ErrValueDoesntImplementProtoMessage)
 	}
 
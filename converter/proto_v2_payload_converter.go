@@ -0,0 +1,178 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package converter
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+
+	commonpb "go.temporal.io/api/common/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"go.temporal.io/sdk/internal/common/util"
+)
+
+// ProtoV2PayloadConverter converts google.golang.org/protobuf (APIv2) proto objects to protobuf binary format.
+type ProtoV2PayloadConverter struct {
+}
+
+// ProtoV2JSONPayloadConverter converts google.golang.org/protobuf (APIv2) proto objects to/from JSON.
+type ProtoV2JSONPayloadConverter struct {
+	marshalOptions   protojson.MarshalOptions
+	unmarshalOptions protojson.UnmarshalOptions
+}
+
+// NewProtoV2PayloadConverter creates new instance of ProtoV2PayloadConverter.
+func NewProtoV2PayloadConverter() *ProtoV2PayloadConverter {
+	return &ProtoV2PayloadConverter{}
+}
+
+// NewProtoV2JSONPayloadConverter creates new instance of ProtoV2JSONPayloadConverter.
+func NewProtoV2JSONPayloadConverter() *ProtoV2JSONPayloadConverter {
+	return &ProtoV2JSONPayloadConverter{
+		marshalOptions:   protojson.MarshalOptions{},
+		unmarshalOptions: protojson.UnmarshalOptions{},
+	}
+}
+
+// ToPayload converts single proto value to payload.
+func (c *ProtoV2PayloadConverter) ToPayload(value interface{}) (*commonpb.Payload, error) {
+	if valueProto, ok := value.(proto.Message); ok {
+		data, err := proto.Marshal(valueProto)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnableToEncode, err)
+		}
+		return newProtoV2Payload(data, c, valueProto), nil
+	}
+	return nil, nil
+}
+
+// FromPayload converts single proto value from payload.
+func (c *ProtoV2PayloadConverter) FromPayload(payload *commonpb.Payload, valuePtr interface{}) error {
+	protoMessage, err := newProtoV2Value(payload, valuePtr)
+	if err != nil {
+		return err
+	}
+
+	err = proto.Unmarshal(payload.GetData(), protoMessage)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnableToDecode, err)
+	}
+
+	return nil
+}
+
+// ToString converts payload object into human readable string.
+func (c *ProtoV2PayloadConverter) ToString(payload *commonpb.Payload) string {
+	// We can't do anything better here.
+	return base64.RawStdEncoding.EncodeToString(payload.GetData())
+}
+
+// Encoding returns MetadataEncodingProto.
+func (c *ProtoV2PayloadConverter) Encoding() string {
+	return MetadataEncodingProto
+}
+
+// ToPayload converts single proto value to payload.
+func (c *ProtoV2JSONPayloadConverter) ToPayload(value interface{}) (*commonpb.Payload, error) {
+	if valueProto, ok := value.(proto.Message); ok {
+		data, err := c.marshalOptions.Marshal(valueProto)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnableToEncode, err)
+		}
+		return newProtoV2Payload(data, c, valueProto), nil
+	}
+	return nil, nil
+}
+
+// FromPayload converts single proto value from payload.
+func (c *ProtoV2JSONPayloadConverter) FromPayload(payload *commonpb.Payload, valuePtr interface{}) error {
+	protoMessage, err := newProtoV2Value(payload, valuePtr)
+	if err != nil {
+		return err
+	}
+
+	err = c.unmarshalOptions.Unmarshal(payload.GetData(), protoMessage)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnableToDecode, err)
+	}
+
+	return nil
+}
+
+// ToString converts payload object into human readable string.
+func (c *ProtoV2JSONPayloadConverter) ToString(payload *commonpb.Payload) string {
+	// We can't do anything better here.
+	return string(payload.GetData())
+}
+
+// Encoding returns MetadataEncodingProtoJSON.
+func (c *ProtoV2JSONPayloadConverter) Encoding() string {
+	return MetadataEncodingProtoJSON
+}
+
+func newProtoV2Payload(data []byte, c PayloadConverter, message proto.Message) *commonpb.Payload {
+	payload := newPayload(data, c)
+	payload.Metadata[MetadataMessageType] = []byte(message.ProtoReflect().Descriptor().FullName())
+	return payload
+}
+
+// newProtoV2Value returns proto message which valuePtr points to, creating a new one if it is nil.
+func newProtoV2Value(payload *commonpb.Payload, valuePtr interface{}) (proto.Message, error) {
+	value := reflect.ValueOf(valuePtr).Elem()
+	if !value.CanSet() {
+		return nil, fmt.Errorf("type: %T: %w", valuePtr, ErrUnableToSetValue)
+	}
+
+	protoValue := value.Interface() // protoValue is of type i.e. *durationpb.Duration
+	protoMessage, ok := protoValue.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("value: %v of type: %T: %w", value, value, ErrValueDoesntImplementProtoMessage)
+	}
+
+	// If nil is passed create new instance
+	if util.IsInterfaceNil(protoValue) {
+		protoType := value.Type().Elem()                         // i.e. durationpb.Duration
+		newProtoValue := reflect.New(protoType)                  // is of type i.e. *durationpb.Duration
+		protoMessage = newProtoValue.Interface().(proto.Message) // type assertion will always succeed
+		value.Set(newProtoValue)                                 // Set newly created value back to passed valuePtr
+	}
+
+	messageType := string(protoMessage.ProtoReflect().Descriptor().FullName())
+	if payloadMessageType, ok := payload.GetMetadata()[MetadataMessageType]; ok && string(payloadMessageType) != messageType {
+		return nil, fmt.Errorf("message type %s doesn't match payload message type %s: %w", messageType, payloadMessageType, ErrUnableToDecode)
+	}
+
+	return protoMessage, nil
+}
+
+// isProtoV2Message returns true for google.golang.org/protobuf (APIv2) messages.
+// They also implement gogo proto.Message interface and must not be handled by gogo based converters.
+func isProtoV2Message(value interface{}) bool {
+	_, ok := value.(proto.Message)
+	return ok
+}
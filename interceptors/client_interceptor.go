@@ -0,0 +1,44 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package interceptors
+
+import (
+	"go.temporal.io/sdk/internal"
+)
+
+type (
+	// ClientInterceptor is used to create a single link in the client interceptor chain. Called once per client creation.
+	ClientInterceptor = internal.ClientInterceptor
+
+	// ClientOutboundCallsInterceptor is an interface that can be implemented to intercept calls done through the Client.
+	// Use ClientOutboundCallsInterceptorBase as a base struct for implementations that do not want to implement every method.
+	// Interceptor implementation must forward calls to the next in the interceptor chain.
+	// Arguments are passed to the interceptors before they are encoded by the DataConverter.
+	ClientOutboundCallsInterceptor = internal.ClientOutboundCallsInterceptor
+
+	// ClientOutboundCallsInterceptorBase is a noop implementation of ClientOutboundCallsInterceptor that just forwards requests
+	// to the next link in an interceptor chain. To be used as base implementation of interceptors.
+	ClientOutboundCallsInterceptorBase = internal.ClientOutboundCallsInterceptorBase
+)
@@ -134,21 +134,7 @@ type (
 
 // GetActivityInfo returns information about currently executing activity.
 func GetActivityInfo(ctx context.Context) ActivityInfo {
-	env := getActivityEnv(ctx)
-	return ActivityInfo{
-		ActivityID:         env.activityID,
-		ActivityType:       env.activityType,
-		TaskToken:          env.taskToken,
-		WorkflowExecution:  env.workflowExecution,
-		HeartbeatTimeout:   env.heartbeatTimeout,
-		Deadline:           env.deadline,
-		ScheduledTimestamp: env.scheduledTimestamp,
-		StartedTimestamp:   env.startedTimestamp,
-		TaskQueue:          env.taskQueue,
-		Attempt:            env.attempt,
-		WorkflowType:       env.workflowType,
-		WorkflowNamespace:  env.workflowNamespace,
-	}
+	return getActivityOutboundInterceptor(ctx).GetInfo(ctx)
 }
 
 // HasHeartbeatDetails checks if there is heartbeat details from last attempt.
@@ -167,20 +153,18 @@ func GetHeartbeatDetails(ctx context.Context, d ...interface{}) error {
 	if env.heartbeatDetails == nil {
 		return ErrNoData
 	}
-	encoded := newEncodedValues(env.heartbeatDetails, env.dataConverter)
+	encoded := newEncodedValues(env.heartbeatDetails, getDataConverterFromActivityCtx(ctx))
 	return encoded.Get(d...)
 }
 
 // GetActivityLogger returns a logger that can be used in activity
 func GetActivityLogger(ctx context.Context) log.Logger {
-	env := getActivityEnv(ctx)
-	return env.logger
+	return getActivityOutboundInterceptor(ctx).GetLogger(ctx)
 }
 
 // GetActivityMetricsScope returns a metrics scope that can be used in activity
 func GetActivityMetricsScope(ctx context.Context) tally.Scope {
-	env := getActivityEnv(ctx)
-	return env.metricsScope
+	return getActivityOutboundInterceptor(ctx).GetMetricsScope(ctx)
 }
 
 // GetWorkerStopChannel returns a read-only channel. The closure of this channel indicates the activity worker is stopping.
@@ -201,26 +185,7 @@ func GetWorkerStopChannel(ctx context.Context) <-chan struct{} {
 // details - the details that you provided here can be seen in the worflow when it receives TimeoutError, you
 // can check error TimeoutType()/Details().
 func RecordActivityHeartbeat(ctx context.Context, details ...interface{}) {
-	env := getActivityEnv(ctx)
-	if env.isLocalActivity {
-		// no-op for local activity
-		return
-	}
-	var data *commonpb.Payloads
-	var err error
-	// We would like to be a able to pass in "nil" as part of details(that is no progress to report to)
-	if len(details) > 1 || (len(details) == 1 && details[0] != nil) {
-		data, err = encodeArgs(getDataConverterFromActivityCtx(ctx), details)
-		if err != nil {
-			panic(err)
-		}
-	}
-
-	err = env.serviceInvoker.Heartbeat(data, false)
-	if err != nil {
-		log := GetActivityLogger(ctx)
-		log.Debug("RecordActivityHeartbeat with error", tagError, err)
-	}
+	getActivityOutboundInterceptor(ctx).RecordHeartbeat(ctx, details...)
 }
 
 // ServiceInvoker abstracts calls to the Temporal service from an activity implementation.
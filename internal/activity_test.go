@@ -37,6 +37,9 @@ import (
 
 	"go.temporal.io/api/workflowservice/v1"
 	"go.temporal.io/api/workflowservicemock/v1"
+
+	ilog "go.temporal.io/sdk/internal/log"
+	"go.temporal.io/sdk/log"
 )
 
 type activityTestSuite struct {
@@ -86,6 +89,35 @@ func (s *activityTestSuite) TestActivityHeartbeat_InternalError() {
 	RecordActivityHeartbeat(ctx, "testDetails")
 }
 
+type loggerActivityOutboundCallsInterceptor struct {
+	ActivityOutboundCallsInterceptorBase
+	logger log.Logger
+}
+
+func (l *loggerActivityOutboundCallsInterceptor) GetLogger(ctx context.Context) log.Logger {
+	return l.logger
+}
+
+func (s *activityTestSuite) TestActivityHeartbeat_ErrorLoggedThroughInterceptor() {
+	ctx, cancel := context.WithCancel(context.Background())
+	invoker := newServiceInvoker([]byte("task-token"), "identity", s.service, cancel, 1, make(chan struct{}))
+	ctx = context.WithValue(ctx, activityEnvContextKey, &activityEnvironment{
+		serviceInvoker: invoker,
+		logger:         ilog.NewNopLogger()})
+	logger := ilog.NewMemoryLogger()
+	ctx = context.WithValue(ctx, activityInterceptorContextKey, &loggerActivityOutboundCallsInterceptor{
+		ActivityOutboundCallsInterceptorBase: ActivityOutboundCallsInterceptorBase{Next: &activityEnvironmentInterceptor{}},
+		logger:                               logger,
+	})
+
+	s.service.EXPECT().RecordActivityTaskHeartbeat(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, serviceerror.NewNotFound("")).Times(1)
+
+	RecordActivityHeartbeat(ctx, "testDetails")
+	s.Len(logger.Lines(), 1)
+	s.Contains(logger.Lines()[0], "RecordActivityHeartbeat with error")
+}
+
 func (s *activityTestSuite) TestActivityHeartbeat_CancelRequested() {
 	ctx, cancel := context.WithCancel(context.Background())
 	invoker := newServiceInvoker([]byte("task-token"), "identity", s.service, cancel, 1, make(chan struct{}))
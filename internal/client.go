@@ -260,6 +260,19 @@ type (
 		//  - InternalServiceError
 		ScanWorkflow(ctx context.Context, request *workflowservice.ScanWorkflowExecutionsRequest) (*workflowservice.ScanWorkflowExecutionsResponse, error)
 
+		// ListWorkflowExecutions returns an iterator over the workflow executions returned by ListWorkflow,
+		// ListOpenWorkflow, ListClosedWorkflow, ListArchivedWorkflow or ScanWorkflow, depending on the request set in
+		// the options. Pages are fetched lazily and next page tokens are handled by the iterator. Memo and search
+		// attributes of the returned executions are decoded through the DataConverter of the client.
+		//	iter := ListWorkflowExecutions(ctx, ListWorkflowExecutionsOptions{ListRequest: request})
+		//	for iter.HasNext() {
+		//		execution, err := iter.Next()
+		//		if err != nil {
+		//			return err
+		//		}
+		//	}
+		ListWorkflowExecutions(ctx context.Context, options ListWorkflowExecutionsOptions) WorkflowExecutionIterator
+
 		// CountWorkflow gets number of workflow executions based on query. This API only works with ElasticSearch,
 		// and will return BadRequestError when using Cassandra or MySQL. The query is basically the SQL WHERE clause
 		// (see ListWorkflow for query examples).
@@ -309,6 +322,25 @@ type (
 		//  - EntityNotExistError
 		DescribeWorkflowExecution(ctx context.Context, workflowID, runID string) (*workflowservice.DescribeWorkflowExecutionResponse, error)
 
+		// ResetWorkflow resets a workflow execution to the reset point selected by the request and starts a new run
+		// from there. See ResetWorkflowRequest and ResetType for more information.
+		// The server reapplies the signals received after the reset point to the new run.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		ResetWorkflow(ctx context.Context, request *ResetWorkflowRequest) (*workflowservice.ResetWorkflowExecutionResponse, error)
+
+		// BatchOperation pages through the workflow executions matching the visibility query of the options using
+		// ListWorkflow, or ScanWorkflow, and signals, cancels or terminates each of them with bounded concurrency
+		// and rate. The returned BatchOperationResult reports per-execution failures and can be used to resume
+		// an interrupted batch through its NextPageToken and ProcessedExecutions.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - context errors if the batch is interrupted
+		BatchOperation(ctx context.Context, options BatchOperationOptions) (*BatchOperationResult, error)
+
 		// DescribeTaskQueue returns information about the target taskqueue, right now this API returns the
 		// pollers which polled this taskqueue in last few minutes.
 		// The errors it can return:
@@ -378,6 +410,15 @@ type (
 		// default: defaultDataConverter, an combination of thriftEncoder and jsonEncoder
 		DataConverter converter.DataConverter
 
+		// Optional: Sets FailureConverter to customize serialization/deserialization of errors.
+		// default: DefaultFailureConverter over DataConverter, which sends messages and stack traces in plain text.
+		FailureConverter FailureConverter
+
+		// Optional: Sets limits of payload sizes checked by the client and workers before payloads are sent to
+		// the server, see PayloadLimitOptions.
+		// default: no limits
+		PayloadLimits PayloadLimitOptions
+
 		// Optional: Sets opentracing Tracer that is to be used to emit tracing information
 		// default: no tracer - opentracing.NoopTracer
 		Tracer opentracing.Tracer
@@ -389,6 +430,16 @@ type (
 		// Optional: Sets options for server connection that allow users to control features of connections such as TLS settings.
 		// default: no extra options
 		ConnectionOptions ConnectionOptions
+
+		// Optional: Sets the WorkflowServiceClient used instead of dialing HostPort, for example an
+		// InMemoryWorkflowService in hermetic tests. HostPort and ConnectionOptions are ignored when it is set.
+		// default: nil
+		WorkflowService workflowservice.WorkflowServiceClient
+
+		// Optional: Sets ClientInterceptors that are invoked, in order, around every workflow start, signal, query,
+		// cancel and terminate call done through the client.
+		// default: no interceptors
+		Interceptors []ClientInterceptor
 	}
 
 	// ConnectionOptions is provided by SDK consumers to control optional connection params.
@@ -545,6 +596,10 @@ func NewClient(options ClientOptions) (Client, error) {
 		options.Logger.Info("No logger configured for temporal client. Created default one.")
 	}
 
+	if options.WorkflowService != nil {
+		return NewServiceClient(options.WorkflowService, nil, options), nil
+	}
+
 	connection, err := dial(newDialParameters(&options))
 	if err != nil {
 		return nil, err
@@ -581,13 +636,17 @@ func NewServiceClient(workflowServiceClient workflowservice.WorkflowServiceClien
 		options.DataConverter = converter.GetDefaultDataConverter()
 	}
 
+	if options.FailureConverter == nil {
+		options.FailureConverter = NewDefaultFailureConverter(DefaultFailureConverterOptions{DataConverter: options.DataConverter})
+	}
+
 	if options.Tracer != nil {
 		options.ContextPropagators = append(options.ContextPropagators, NewTracingContextPropagator(options.Logger, options.Tracer))
 	} else {
 		options.Tracer = opentracing.NoopTracer{}
 	}
 
-	return &WorkflowClient{
+	client := &WorkflowClient{
 		workflowService:    workflowServiceClient,
 		connectionCloser:   connectionCloser,
 		namespace:          options.Namespace,
@@ -596,15 +655,24 @@ func NewServiceClient(workflowServiceClient workflowservice.WorkflowServiceClien
 		logger:             options.Logger,
 		identity:           options.Identity,
 		dataConverter:      options.DataConverter,
+		failureConverter:   options.FailureConverter,
 		contextPropagators: options.ContextPropagators,
 		tracer:             options.Tracer,
+		payloadLimits:      options.PayloadLimits,
 	}
+	client.payloadSizeChecker = newPayloadSizeChecker(options.PayloadLimits, client.logger, client.metricsScope)
+	client.interceptor = newClientInterceptors(client, options.Interceptors)
+	return client
 }
 
 // NewNamespaceClient creates an instance of a namespace client, to manager lifecycle of namespaces.
 func NewNamespaceClient(options ClientOptions) (NamespaceClient, error) {
 	options.MetricsScope = tagScope(options.MetricsScope, clientImplHeaderName, clientImplHeaderValue)
 
+	if options.WorkflowService != nil {
+		return newNamespaceServiceClient(options.WorkflowService, nil, options), nil
+	}
+
 	if options.HostPort == "" {
 		options.HostPort = LocalHostPort
 	}
@@ -621,14 +689,14 @@ func NewNamespaceClient(options ClientOptions) (NamespaceClient, error) {
 	return newNamespaceServiceClient(workflowservice.NewWorkflowServiceClient(connection), connection, options), nil
 }
 
-func newNamespaceServiceClient(workflowServiceClient workflowservice.WorkflowServiceClient, clientConn *grpc.ClientConn, options ClientOptions) NamespaceClient {
+func newNamespaceServiceClient(workflowServiceClient workflowservice.WorkflowServiceClient, connectionCloser io.Closer, options ClientOptions) NamespaceClient {
 	if options.Identity == "" {
 		options.Identity = getWorkerIdentity("")
 	}
 
 	return &namespaceClient{
 		workflowService:  workflowServiceClient,
-		connectionCloser: clientConn,
+		connectionCloser: connectionCloser,
 		metricsScope:     options.MetricsScope,
 		logger:           options.Logger,
 		identity:         options.Identity,
@@ -0,0 +1,507 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package determinism provides an analyzer that reports non-deterministic code in workflow functions.
+//
+// Workflow functions are the functions registered with RegisterWorkflow or RegisterWorkflowWithOptions and the
+// functions that take workflow.Context as their first parameter. The analyzer follows the calls they make, and the
+// functions they run with workflow.Go, and reports every construct that breaks workflow replay together with the
+// workflow API to use instead. Functions passed as values, like activities, are not followed. Calls to other packages
+// are followed through facts exported when those packages are analyzed, except calls to the standard library and to
+// the Temporal SDK, whose functions are deterministic unless the analyzer lists them as forbidden.
+//
+// Audited exceptions are allowed with a "//workflowcheck:ignore" comment, optionally followed by a reason, on the
+// line of the construct or on the line above it. The comment in the doc of a function skips the function and the
+// calls it makes.
+package determinism
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// ignoreDirective marks audited non-deterministic code.
+const ignoreDirective = "//workflowcheck:ignore"
+
+// Analyzer reports non-deterministic constructs reachable from workflow functions.
+var Analyzer = &analysis.Analyzer{
+	Name: "workflowcheck",
+	Doc:  "reports non-deterministic code in Temporal workflow functions",
+	Run:  run,
+	FactTypes: []analysis.Fact{
+		new(nonDeterministicFact),
+	},
+}
+
+var (
+	// workflowContextPackages are the packages that declare workflow.Context.
+	workflowContextPackages = map[string]bool{
+		"go.temporal.io/sdk/workflow": true,
+		"go.temporal.io/sdk/internal": true,
+	}
+
+	// sdkModule is the module whose functions are deterministic in workflow code, it runs coroutines with native
+	// goroutines and channels.
+	sdkModule = "go.temporal.io/sdk"
+
+	// registerFunctions are the functions and methods which register their first argument as a workflow.
+	registerFunctions = map[string]bool{
+		"RegisterWorkflow":            true,
+		"RegisterWorkflowWithOptions": true,
+	}
+
+	// forbiddenFunctions maps non-deterministic functions to their replacement in workflow code.
+	forbiddenFunctions = map[string]string{
+		"time.Now":         "workflow.Now",
+		"time.Since":       "workflow.Now",
+		"time.Until":       "workflow.Now",
+		"time.Sleep":       "workflow.Sleep",
+		"time.After":       "workflow.NewTimer",
+		"time.AfterFunc":   "workflow.NewTimer",
+		"time.NewTimer":    "workflow.NewTimer",
+		"time.NewTicker":   "workflow.NewTimer",
+		"time.Tick":        "workflow.NewTimer",
+		"crypto/rand.Read": "workflow.SideEffect",
+		"crypto/rand.Int":  "workflow.SideEffect",
+	}
+
+	// forbiddenPackages maps packages whose functions are all non-deterministic to their replacement.
+	forbiddenPackages = map[string]string{
+		"math/rand": "workflow.SideEffect",
+	}
+
+	// allowedFunctions are exceptions of forbiddenPackages, a generator with a fixed seed is deterministic.
+	allowedFunctions = map[string]bool{
+		"math/rand.New":       true,
+		"math/rand.NewSource": true,
+	}
+)
+
+type (
+	// checker holds the state of one analysis pass.
+	checker struct {
+		pass *analysis.Pass
+		// decls are the function declarations of the package.
+		decls map[*types.Func]*ast.FuncDecl
+		// ignored are the lines, per file, covered by an ignore directive.
+		ignored map[*token.File]map[int]bool
+		// visited are the functions already checked, diagnostics name the first workflow which reached them.
+		visited map[*ast.FuncDecl]bool
+		// reported avoids duplicate diagnostics for code reachable from several workflows.
+		reported map[token.Pos]bool
+		// summaries are the first non-deterministic construct reachable from functions of the package, nil if none.
+		summaries map[*ast.FuncDecl]*nonDeterministicFact
+	}
+
+	// nonDeterministicFact is exported for functions which reach a non-deterministic construct, so workflows of
+	// other packages which call them are reported.
+	nonDeterministicFact struct {
+		Construct   string
+		Replacement string
+		// Path are the functions called to reach the construct, after the function of the fact.
+		Path []string
+	}
+
+	// workflowRoot is a workflow function, either declared or a function literal passed to RegisterWorkflow.
+	workflowRoot struct {
+		name string
+		decl *ast.FuncDecl
+		lit  *ast.FuncLit
+	}
+)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	c := &checker{
+		pass:      pass,
+		decls:     make(map[*types.Func]*ast.FuncDecl),
+		ignored:   make(map[*token.File]map[int]bool),
+		visited:   make(map[*ast.FuncDecl]bool),
+		reported:  make(map[token.Pos]bool),
+		summaries: make(map[*ast.FuncDecl]*nonDeterministicFact),
+	}
+	for _, file := range pass.Files {
+		c.collectIgnoredLines(file)
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+				if fn, ok := pass.TypesInfo.Defs[funcDecl.Name].(*types.Func); ok {
+					c.decls[fn] = funcDecl
+				}
+			}
+		}
+	}
+
+	for _, root := range c.findWorkflows() {
+		if root.decl != nil {
+			c.checkFunction(root.decl, []string{root.name})
+		} else {
+			c.checkBody(root.lit.Body, []string{root.name})
+		}
+	}
+
+	if exportsFacts(pass.Pkg.Path()) {
+		for fn, funcDecl := range c.decls {
+			if fact := c.summarize(funcDecl); fact != nil {
+				pass.ExportObjectFact(fn, fact)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// AFact implements analysis.Fact.
+func (*nonDeterministicFact) AFact() {}
+
+func (f *nonDeterministicFact) String() string {
+	return fmt.Sprintf("%v via %v", f.Construct, strings.Join(f.Path, " -> "))
+}
+
+// exportsFacts returns false for the standard library and the SDK, their functions aren't followed.
+func exportsFacts(pkgPath string) bool {
+	if pkgPath == sdkModule || strings.HasPrefix(pkgPath, sdkModule+"/") {
+		return false
+	}
+	return strings.Contains(strings.SplitN(pkgPath, "/", 2)[0], ".")
+}
+
+func (c *checker) collectIgnoredLines(file *ast.File) {
+	tokenFile := c.pass.Fset.File(file.Pos())
+	if tokenFile == nil {
+		return
+	}
+	lines := make(map[int]bool)
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			if strings.HasPrefix(comment.Text, ignoreDirective) {
+				line := tokenFile.Line(comment.Pos())
+				lines[line] = true
+				lines[line+1] = true
+			}
+		}
+	}
+	c.ignored[tokenFile] = lines
+}
+
+func (c *checker) isIgnored(node ast.Node) bool {
+	tokenFile := c.pass.Fset.File(node.Pos())
+	return tokenFile != nil && c.ignored[tokenFile][tokenFile.Line(node.Pos())]
+}
+
+// findWorkflows returns the workflow functions of the package in source order.
+func (c *checker) findWorkflows() []workflowRoot {
+	var roots []workflowRoot
+	seen := make(map[ast.Node]bool)
+	add := func(root workflowRoot) {
+		var node ast.Node = root.lit
+		if root.decl != nil {
+			node = root.decl
+		}
+		if !seen[node] {
+			seen[node] = true
+			roots = append(roots, root)
+		}
+	}
+
+	for _, file := range c.pass.Files {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Body != nil && c.takesWorkflowContext(funcDecl) {
+				add(workflowRoot{name: funcDecl.Name.Name, decl: funcDecl})
+			}
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 || !registerFunctions[calleeName(call.Fun)] {
+				return true
+			}
+			switch arg := astutil.Unparen(call.Args[0]).(type) {
+			case *ast.FuncLit:
+				add(workflowRoot{name: "func literal", lit: arg})
+			default:
+				if fn := c.referencedFunc(arg); fn != nil {
+					if funcDecl, ok := c.decls[fn]; ok && funcDecl.Body != nil {
+						add(workflowRoot{name: funcDecl.Name.Name, decl: funcDecl})
+					}
+				}
+			}
+			return true
+		})
+	}
+	return roots
+}
+
+func (c *checker) takesWorkflowContext(funcDecl *ast.FuncDecl) bool {
+	params := funcDecl.Type.Params.List
+	if len(params) == 0 {
+		return false
+	}
+	named, ok := unalias(c.pass.TypesInfo.TypeOf(params[0].Type)).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == "Context" && obj.Pkg() != nil && workflowContextPackages[obj.Pkg().Path()]
+}
+
+// checkFunction checks a function declaration of the package once.
+func (c *checker) checkFunction(funcDecl *ast.FuncDecl, path []string) {
+	if c.visited[funcDecl] || (funcDecl.Doc != nil && hasIgnoreDirective(funcDecl.Doc)) {
+		return
+	}
+	c.visited[funcDecl] = true
+	c.checkBody(funcDecl.Body, path)
+}
+
+func (c *checker) checkBody(body *ast.BlockStmt, path []string) {
+	c.inspectBody(body, func(node ast.Node, construct, replacement string) {
+		c.report(node, path, construct, replacement)
+	}, func(node ast.Node, fn *types.Func) {
+		if funcDecl, ok := c.decls[fn]; ok {
+			c.checkFunction(funcDecl, append(path[:len(path):len(path)], funcDecl.Name.Name))
+			return
+		}
+		var fact nonDeterministicFact
+		if c.pass.ImportObjectFact(fn, &fact) {
+			calls := append(append(path[:len(path):len(path)], qualifiedName(fn)), fact.Path...)
+			c.report(node, calls, fact.Construct, fact.Replacement)
+		}
+	})
+}
+
+// summarize returns the first non-deterministic construct reachable from a function of the package.
+func (c *checker) summarize(funcDecl *ast.FuncDecl) *nonDeterministicFact {
+	if fact, ok := c.summaries[funcDecl]; ok || funcDecl.Body == nil || (funcDecl.Doc != nil && hasIgnoreDirective(funcDecl.Doc)) {
+		return fact
+	}
+	// recursive calls find nothing more
+	c.summaries[funcDecl] = nil
+	var result *nonDeterministicFact
+	c.inspectBody(funcDecl.Body, func(_ ast.Node, construct, replacement string) {
+		if result == nil {
+			result = &nonDeterministicFact{Construct: construct, Replacement: replacement}
+		}
+	}, func(_ ast.Node, fn *types.Func) {
+		if result != nil {
+			return
+		}
+		var fact nonDeterministicFact
+		if callee, ok := c.decls[fn]; ok {
+			if calleeFact := c.summarize(callee); calleeFact != nil {
+				fact = *calleeFact
+			}
+		} else if !c.pass.ImportObjectFact(fn, &fact) {
+			return
+		}
+		if fact.Construct != "" {
+			fact.Path = append([]string{qualifiedName(fn)}, fact.Path...)
+			result = &fact
+		}
+	})
+	c.summaries[funcDecl] = result
+	return result
+}
+
+// inspectBody calls found for every non-deterministic construct of the body which isn't ignored, and call for every
+// function the body calls or runs with workflow.Go.
+func (c *checker) inspectBody(body *ast.BlockStmt, found func(node ast.Node, construct, replacement string), call func(node ast.Node, fn *types.Func)) {
+	// followed are the identifiers of called functions and of functions run by workflow.Go.
+	followed := make(map[*ast.Ident]bool)
+	var visit func(node ast.Node) bool
+	visit = func(node ast.Node) bool {
+		if node == nil || c.isIgnored(node) {
+			return false
+		}
+		switch n := node.(type) {
+		case *ast.GoStmt:
+			found(n, "go statement", "workflow.Go")
+		case *ast.SelectStmt:
+			found(n, "select statement", "workflow.NewSelector")
+			// the send and receive operations of the cases are part of the reported select
+			for _, clause := range n.Body.List {
+				for _, stmt := range clause.(*ast.CommClause).Body {
+					ast.Inspect(stmt, visit)
+				}
+			}
+			return false
+		case *ast.SendStmt:
+			found(n, "native channel send", "workflow.Channel")
+		case *ast.UnaryExpr:
+			// receiving from time.After and alike is reported with the call
+			if call, ok := astutil.Unparen(n.X).(*ast.CallExpr); n.Op == token.ARROW && (!ok || !c.isForbiddenCall(call)) {
+				found(n, "native channel receive", "workflow.Channel")
+			}
+		case *ast.RangeStmt:
+			switch c.pass.TypesInfo.TypeOf(n.X).Underlying().(type) {
+			case *types.Map:
+				found(n, "range over map", "a range over the sorted keys")
+			case *types.Chan:
+				found(n, "range over native channel", "workflow.Channel")
+			}
+		case *ast.CallExpr:
+			if c.isMakeChan(n) {
+				found(n, "native channel", "workflow.NewChannel")
+			}
+			followed[calleeIdent(n.Fun)] = true
+			if name := calleeName(n.Fun); name == "Go" || name == "GoNamed" {
+				// coroutines run workflow code
+				for _, arg := range n.Args {
+					followed[calleeIdent(arg)] = true
+				}
+			}
+		case *ast.Ident:
+			c.inspectReference(n, n, followed[n], found, call)
+		case *ast.SelectorExpr:
+			c.inspectReference(n, n.Sel, followed[n.Sel], found, call)
+			// the selected identifier is handled with its qualifier
+			ast.Inspect(n.X, visit)
+			return false
+		}
+		return true
+	}
+	ast.Inspect(body, visit)
+}
+
+// inspectReference reports references to forbidden functions and calls to other functions.
+func (c *checker) inspectReference(node ast.Node, ident *ast.Ident, isCall bool, found func(node ast.Node, construct, replacement string), call func(node ast.Node, fn *types.Func)) {
+	fn, ok := c.pass.TypesInfo.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return
+	}
+	if replacement, ok := forbiddenReplacement(fn); ok {
+		found(node, fn.Pkg().Path()+"."+fn.Name(), replacement)
+		return
+	}
+	// function values, like activities passed to ExecuteActivity, are not workflow code
+	if isCall {
+		call(node, fn)
+	}
+}
+
+func (c *checker) isMakeChan(call *ast.CallExpr) bool {
+	ident, ok := astutil.Unparen(call.Fun).(*ast.Ident)
+	if !ok || len(call.Args) == 0 {
+		return false
+	}
+	if _, ok := c.pass.TypesInfo.Uses[ident].(*types.Builtin); !ok || ident.Name != "make" {
+		return false
+	}
+	_, ok = c.pass.TypesInfo.TypeOf(call.Args[0]).Underlying().(*types.Chan)
+	return ok
+}
+
+func (c *checker) isForbiddenCall(call *ast.CallExpr) bool {
+	fn := c.referencedFunc(astutil.Unparen(call.Fun))
+	if fn == nil || fn.Pkg() == nil {
+		return false
+	}
+	_, ok := forbiddenReplacement(fn)
+	return ok
+}
+
+// forbiddenReplacement returns the workflow replacement of a non-deterministic package level function.
+func forbiddenReplacement(fn *types.Func) (string, bool) {
+	if signature, ok := fn.Type().(*types.Signature); !ok || signature.Recv() != nil {
+		return "", false
+	}
+	name := fn.Pkg().Path() + "." + fn.Name()
+	if replacement, ok := forbiddenFunctions[name]; ok {
+		return replacement, true
+	}
+	if replacement, ok := forbiddenPackages[fn.Pkg().Path()]; ok && !allowedFunctions[name] {
+		return replacement, true
+	}
+	return "", false
+}
+
+// referencedFunc returns the function an expression like f, pkg.f or value.Method refers to.
+func (c *checker) referencedFunc(expr ast.Expr) *types.Func {
+	var ident *ast.Ident
+	switch e := expr.(type) {
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	default:
+		return nil
+	}
+	fn, _ := c.pass.TypesInfo.Uses[ident].(*types.Func)
+	return fn
+}
+
+func (c *checker) report(node ast.Node, path []string, construct, replacement string) {
+	if c.reported[node.Pos()] {
+		return
+	}
+	c.reported[node.Pos()] = true
+	message := fmt.Sprintf("%v is not deterministic in workflow %v, use %v instead", construct, path[0], replacement)
+	if len(path) > 1 {
+		message += fmt.Sprintf(" (called via %v)", strings.Join(path[1:], " -> "))
+	}
+	c.pass.Reportf(node.Pos(), "%s", message)
+}
+
+// qualifiedName returns the name of a function with its package name, like pkg.Function or pkg.Type.Method.
+func qualifiedName(fn *types.Func) string {
+	name := fn.Name()
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		recvType := recv.Type()
+		if pointer, ok := recvType.(*types.Pointer); ok {
+			recvType = pointer.Elem()
+		}
+		if named, ok := unalias(recvType).(*types.Named); ok {
+			name = named.Obj().Name() + "." + name
+		}
+	}
+	return fn.Pkg().Name() + "." + name
+}
+
+func calleeName(fun ast.Expr) string {
+	if ident := calleeIdent(fun); ident != nil {
+		return ident.Name
+	}
+	return ""
+}
+
+func calleeIdent(fun ast.Expr) *ast.Ident {
+	switch f := astutil.Unparen(fun).(type) {
+	case *ast.Ident:
+		return f
+	case *ast.SelectorExpr:
+		return f.Sel
+	}
+	return nil
+}
+
+func hasIgnoreDirective(group *ast.CommentGroup) bool {
+	for _, comment := range group.List {
+		if strings.HasPrefix(comment.Text, ignoreDirective) {
+			return true
+		}
+	}
+	return false
+}
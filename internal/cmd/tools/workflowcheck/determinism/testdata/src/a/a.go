@@ -0,0 +1,119 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package a
+
+import (
+	"math/rand"
+	"time"
+
+	"example.com/b"
+	"go.temporal.io/sdk/workflow"
+)
+
+type registry struct{}
+
+func (r registry) RegisterWorkflow(w interface{}) {}
+
+func (r registry) RegisterWorkflowWithOptions(w interface{}, options interface{}) {}
+
+func register(r registry) {
+	r.RegisterWorkflow(RegisteredWorkflow)
+	r.RegisterWorkflowWithOptions(func() {
+		time.Sleep(time.Second) // want `time.Sleep is not deterministic in workflow func literal, use workflow.Sleep instead`
+	}, nil)
+}
+
+func ContextWorkflow(ctx workflow.Context, values map[string]int) error {
+	_ = time.Now()          // want `time.Now is not deterministic in workflow ContextWorkflow, use workflow.Now instead`
+	go func() {}()          // want `go statement is not deterministic in workflow ContextWorkflow, use workflow.Go instead`
+	ch := make(chan int, 1) // want `native channel is not deterministic in workflow ContextWorkflow, use workflow.NewChannel instead`
+	ch <- 1                 // want `native channel send is not deterministic in workflow ContextWorkflow, use workflow.Channel instead`
+	<-ch                    // want `native channel receive is not deterministic in workflow ContextWorkflow, use workflow.Channel instead`
+	select {                // want `select statement is not deterministic in workflow ContextWorkflow, use workflow.NewSelector instead`
+	case v := <-ch:
+		_ = v
+	default:
+	}
+	for range values { // want `range over map is not deterministic in workflow ContextWorkflow, use a range over the sorted keys instead`
+	}
+	for range ch { // want `range over native channel is not deterministic in workflow ContextWorkflow, use workflow.Channel instead`
+	}
+	_ = rand.Intn(10) // want `math/rand.Intn is not deterministic in workflow ContextWorkflow, use workflow.SideEffect instead`
+	_ = rand.New(rand.NewSource(42)).Intn(10)
+	helper()
+	workflow.Go(ctx, coroutine)
+	workflow.ExecuteActivity(ctx, activity)
+	return nil
+}
+
+func coroutine(ctx workflow.Context) {
+	time.Sleep(time.Second) // want `time.Sleep is not deterministic in workflow ContextWorkflow, use workflow.Sleep instead \(called via coroutine\)`
+}
+
+func activity() {
+	time.Sleep(time.Second)
+}
+
+func RegisteredWorkflow() error {
+	_ = time.Since(time.Time{}) // want `time.Since is not deterministic in workflow RegisteredWorkflow, use workflow.Now instead`
+	return nil
+}
+
+func helper() {
+	next()
+}
+
+func next() {
+	<-time.After(time.Second) // want `time.After is not deterministic in workflow ContextWorkflow, use workflow.NewTimer instead \(called via helper -> next\)`
+}
+
+func IgnoredWorkflow(ctx workflow.Context) {
+	_ = time.Now() //workflowcheck:ignore only used for logging
+	//workflowcheck:ignore audited
+	go func() {}()
+	audited()
+}
+
+//workflowcheck:ignore the timer is never used for workflow logic
+func audited() {
+	time.Sleep(time.Second)
+}
+
+func CrossPackageWorkflow(ctx workflow.Context) {
+	b.Helper() // want `time.Now is not deterministic in workflow CrossPackageWorkflow, use workflow.Now instead \(called via b.Helper -> b.next\)`
+	_ = b.Deterministic()
+	b.Audited()
+	_ = b.Clock{}.Now() // want `time.Now is not deterministic in workflow CrossPackageWorkflow, use workflow.Now instead \(called via b.Clock.Now\)`
+	crossPackageHelper()
+}
+
+func crossPackageHelper() {
+	b.Helper() // want `time.Now is not deterministic in workflow CrossPackageWorkflow, use workflow.Now instead \(called via crossPackageHelper -> b.Helper -> b.next\)`
+}
+
+func notAWorkflow() {
+	_ = time.Now()
+	go func() {}()
+}
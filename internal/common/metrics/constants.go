@@ -52,6 +52,7 @@ const (
 	WorkflowTaskResponseFailedCounter       = TemporalMetricsPrefix + "workflow_task_response_failed"
 	WorkflowTaskResponseLatency             = TemporalMetricsPrefix + "workflow_task_response_latency"
 	WorkflowTaskPanicCounter                = TemporalMetricsPrefix + "workflow_task_panic"
+	WorkflowTaskDeadlockCounter             = TemporalMetricsPrefix + "workflow_task_deadlock"
 	WorkflowTaskCompletedCounter            = TemporalMetricsPrefix + "workflow_task_completed"
 	WorkflowTaskForceCompleted              = TemporalMetricsPrefix + "workflow_task_force_completed"
 
@@ -87,6 +88,11 @@ const (
 
 	WorkerStartCounter = TemporalMetricsPrefix + "worker_start"
 	PollerStartCounter = TemporalMetricsPrefix + "poller_start"
+	ActivePollersGauge = TemporalMetricsPrefix + "pollers_active"
+
+	WorkerTaskSlotsUsedGauge     = TemporalMetricsPrefix + "worker_task_slots_used"
+	WorkerTaskSlotsDeniedCounter = TemporalMetricsPrefix + "worker_task_slots_denied"
+	WorkerPausedGauge            = TemporalMetricsPrefix + "worker_paused"
 
 	TemporalRequest        = TemporalMetricsPrefix + "request"
 	TemporalError          = TemporalMetricsPrefix + "error"
@@ -100,4 +106,6 @@ const (
 	StickyCacheSize  = TemporalMetricsPrefix + "sticky_cache_size"
 
 	NonDeterministicError = TemporalMetricsPrefix + "non_deterministic_error"
+
+	PayloadSizeWarningCounter = TemporalMetricsPrefix + "payload_size_warning"
 )
@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/opentracing/opentracing-go"
+	commonpb "go.temporal.io/api/common/v1"
 	enumspb "go.temporal.io/api/enums/v1"
 )
 
@@ -255,9 +256,8 @@ func parentCancelCtx(parent Context) (*cancelCtx, bool) {
 		switch c := parent.(type) {
 		case *cancelCtx:
 			return c, true
-		// TODO: Uncomment once timer story is implemented
-		//case *timerCtx:
-		//	return c.cancelCtx, true
+		case *timerCtx:
+			return c.cancelCtx, true
 		case *valueCtx:
 			parent = c.Context
 		default:
@@ -329,82 +329,91 @@ func (c *cancelCtx) cancel(removeFromParent bool, err error) {
 	}
 }
 
-// Commented out until workflow time API is exposed.
 // WithDeadline returns a copy of the parent context with the deadline adjusted
 // to be no later than d.  If the parent's deadline is already earlier than d,
 // WithDeadline(parent, d) is semantically equivalent to parent.  The returned
 // context's Done channel is closed when the deadline expires, when the returned
 // cancel function is called, or when the parent context's Done channel is
 // closed, whichever happens first.
+// The deadline is measured in workflow time (see workflow.Now) and is backed by
+// a workflow timer, so it is deterministic and survives replay.
 //
 // Canceling this context releases resources associated with it, so code should
 // call cancel as soon as the operations running in this Context complete.
-//func WithDeadline(parent Context, deadline time.Time) (Context, CancelFunc) {
-//	if cur, ok := parent.Deadline(); ok && cur.Before(deadline) {
-//		// The current deadline is already sooner than the new one.
-//		return WithCancel(parent)
-//	}
-//	c := &timerCtx{
-//		cancelCtx: newCancelCtx(parent),
-//		deadline:  deadline,
-//	}
-//	propagateCancel(parent, c)
-//	d := deadline.Sub(time.Now())
-//	if d <= 0 {
-//		c.cancel(true, DeadlineExceeded) // deadline has already passed
-//		return c, func() { c.cancel(true, Canceled) }
-//	}
-//	if c.err == nil {
-//		c.timer = time.AfterFunc(d, func() {
-//			c.cancel(true, DeadlineExceeded)
-//		})
-//	}
-//	return c, func() { c.cancel(true, Canceled) }
-//}
-//
-//// A timerCtx carries a timer and a deadline.  It embeds a cancelCtx to
-//// implement Done and Err.  It implements cancel by stopping its timer then
-//// delegating to cancelCtx.cancel.
-//type timerCtx struct {
-//	*cancelCtx
-//	timer *time.Timer // Under cancelCtx.mu.
-//
-//	deadline time.Time
-//}
-//
-//func (c *timerCtx) Deadline() (deadline time.Time, ok bool) {
-//	return c.deadline, true
-//}
-//
-//func (c *timerCtx) String() string {
-//	return fmt.Sprintf("%v.WithDeadline(%s [%s])", c.cancelCtx.Context, c.deadline, c.deadline.Sub(time.Now()))
-//}
-//
-//func (c *timerCtx) cancel(removeFromParent bool, err error) {
-//	c.cancelCtx.cancel(false, err)
-//	if removeFromParent {
-//		// Remove this timerCtx from its parent cancelCtx's children.
-//		removeChild(c.cancelCtx.Context, c)
-//	}
-//	if c.timer != nil {
-//		c.timer.Stop()
-//		c.timer = nil
-//	}
-//}
-//
-// WithTimeout returns WithDeadline(parent, time.Now().Add(timeout)).
+func WithDeadline(parent Context, deadline time.Time) (Context, CancelFunc) {
+	if cur, ok := parent.Deadline(); ok && cur.Before(deadline) {
+		// The current deadline is already sooner than the new one.
+		return WithCancel(parent)
+	}
+	c := &timerCtx{
+		cancelCtx: newCancelCtx(parent),
+		deadline:  deadline,
+	}
+	propagateCancel(parent, c)
+	d := deadline.Sub(Now(parent))
+	if d <= 0 {
+		c.cancel(true, ErrDeadlineExceeded) // deadline has already passed
+		return c, func() { c.cancel(true, ErrCanceled) }
+	}
+	if c.err == nil {
+		c.env = getWorkflowEnvironment(parent)
+		c.timer = c.env.NewTimer(d, func(r *commonpb.Payloads, e error) {
+			if e != nil {
+				// timer was canceled together with the context
+				return
+			}
+			c.timer = nil
+			c.cancel(true, ErrDeadlineExceeded)
+		})
+	}
+	return c, func() { c.cancel(true, ErrCanceled) }
+}
+
+// A timerCtx carries a timer and a deadline.  It embeds a cancelCtx to
+// implement Done and Err.  It implements cancel by stopping its timer then
+// delegating to cancelCtx.cancel.
+type timerCtx struct {
+	*cancelCtx
+	env   WorkflowEnvironment
+	timer *TimerInfo
+
+	deadline time.Time
+}
+
+func (c *timerCtx) Deadline() (deadline time.Time, ok bool) {
+	return c.deadline, true
+}
+
+func (c *timerCtx) String() string {
+	return fmt.Sprintf("%v.WithDeadline(%s)", c.cancelCtx.Context, c.deadline)
+}
+
+func (c *timerCtx) cancel(removeFromParent bool, err error) {
+	c.cancelCtx.cancel(false, err)
+	if removeFromParent {
+		// Remove this timerCtx from its parent cancelCtx's children.
+		removeChild(c.cancelCtx.Context, c)
+	}
+	if c.timer != nil {
+		timer := c.timer
+		c.timer = nil
+		c.env.RequestCancelTimer(timer.timerID)
+	}
+}
+
+// WithTimeout returns WithDeadline(parent, workflow.Now(parent).Add(timeout)).
 //
 // Canceling this context releases resources associated with it, so code should
 // call cancel as soon as the operations running in this Context complete:
 //
-// 	func slowOperationWithTimeout(ctx context.Context) (Result, error) {
-// 		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+// 	func slowOperationWithTimeout(ctx workflow.Context) (Result, error) {
+// 		ctx, cancel := workflow.WithTimeout(ctx, 10*time.Minute)
 // 		defer cancel()  // releases resources if slowOperation completes before timeout elapses
 // 		return slowOperation(ctx)
 // 	}
-//func WithTimeout(parent Context, timeout time.Duration) (Context, CancelFunc) {
-//	return WithDeadline(parent, time.Now().Add(timeout))
-//}
+func WithTimeout(parent Context, timeout time.Duration) (Context, CancelFunc) {
+	return WithDeadline(parent, Now(parent).Add(timeout))
+}
 
 // WithValue returns a copy of parent in which the value associated with key is
 // val.
@@ -0,0 +1,74 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+
+	"go.temporal.io/sdk/converter"
+)
+
+// ContextAwareDataConverter is an optional interface which can be implemented by DataConverter.
+// SDK passes workflow or activity context to it before encoding or decoding values, so DataConverter can tailor
+// its behaviour, for example pick an encryption key per namespace or add workflow ID to its logs.
+// Methods are called frequently and must be fast. In workflow they are called from workflow code,
+// so all the rules and restrictions that apply to the workflow code apply to WithWorkflowContext as well.
+// The markers recorded by the workflow environment, which has no access to the workflow context, are encoded and
+// decoded without context: MutableSideEffect values, and the metadata of SideEffect, GetVersion and local
+// activity markers. SideEffect values and local activity results are encoded with context.
+type ContextAwareDataConverter interface {
+	// WithWorkflowContext returns DataConverter to use within the workflow context.
+	WithWorkflowContext(ctx Context) converter.DataConverter
+	// WithContext returns DataConverter to use within the context, like activity context or context passed to
+	// the client call.
+	WithContext(ctx context.Context) converter.DataConverter
+}
+
+// dataConverterWithWorkflowContext applies workflow context to ContextAwareDataConverter.
+func dataConverterWithWorkflowContext(ctx Context, dc converter.DataConverter) converter.DataConverter {
+	if contextAware, ok := dc.(ContextAwareDataConverter); ok {
+		return contextAware.WithWorkflowContext(ctx)
+	}
+	return dc
+}
+
+// dataConverterWithContext applies context to ContextAwareDataConverter.
+func dataConverterWithContext(ctx context.Context, dc converter.DataConverter) converter.DataConverter {
+	if contextAware, ok := dc.(ContextAwareDataConverter); ok {
+		return contextAware.WithContext(ctx)
+	}
+	return dc
+}
+
+// failureConverterWithContext applies context to ContextAwareDataConverter of DefaultFailureConverter.
+// Custom failure converters are returned as is.
+func failureConverterWithContext(ctx context.Context, fc FailureConverter) FailureConverter {
+	if dfc, ok := fc.(*DefaultFailureConverter); ok {
+		if _, ok := dfc.dataConverter.(ContextAwareDataConverter); ok {
+			return &DefaultFailureConverter{dataConverter: dataConverterWithContext(ctx, dfc.dataConverter)}
+		}
+	}
+	return fc
+}
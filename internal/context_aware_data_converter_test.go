@@ -0,0 +1,187 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+
+	"go.temporal.io/sdk/converter"
+	ilog "go.temporal.io/sdk/internal/log"
+)
+
+type contextAwareTestKey struct{}
+
+type contextRecorder struct {
+	lock  sync.Mutex
+	scope map[string]bool
+}
+
+// contextRecordingDataConverter records the context it was bound to on every encode and decode.
+type contextRecordingDataConverter struct {
+	converter.DataConverter
+	recorder *contextRecorder
+	scope    string
+}
+
+func (dc *contextRecordingDataConverter) WithWorkflowContext(ctx Context) converter.DataConverter {
+	return &contextRecordingDataConverter{
+		DataConverter: dc.DataConverter,
+		recorder:      dc.recorder,
+		scope:         "workflow " + GetWorkflowInfo(ctx).WorkflowExecution.ID,
+	}
+}
+
+func (dc *contextRecordingDataConverter) WithContext(ctx context.Context) converter.DataConverter {
+	scope := "client"
+	if ctx.Value(activityEnvContextKey) != nil {
+		scope = "activity " + GetActivityInfo(ctx).ActivityType.Name
+	} else if value, ok := ctx.Value(contextAwareTestKey{}).(string); ok {
+		scope = "client " + value
+	}
+	return &contextRecordingDataConverter{
+		DataConverter: dc.DataConverter,
+		recorder:      dc.recorder,
+		scope:         scope,
+	}
+}
+
+func (dc *contextRecordingDataConverter) record() {
+	dc.recorder.lock.Lock()
+	defer dc.recorder.lock.Unlock()
+	dc.recorder.scope[dc.scope] = true
+}
+
+func (dc *contextRecordingDataConverter) ToPayloads(values ...interface{}) (*commonpb.Payloads, error) {
+	dc.record()
+	return dc.DataConverter.ToPayloads(values...)
+}
+
+func (dc *contextRecordingDataConverter) FromPayloads(payloads *commonpb.Payloads, valuePtrs ...interface{}) error {
+	dc.record()
+	return dc.DataConverter.FromPayloads(payloads, valuePtrs...)
+}
+
+func (dc *contextRecordingDataConverter) ToPayload(value interface{}) (*commonpb.Payload, error) {
+	dc.record()
+	return dc.DataConverter.ToPayload(value)
+}
+
+func (dc *contextRecordingDataConverter) FromPayload(payload *commonpb.Payload, valuePtr interface{}) error {
+	dc.record()
+	return dc.DataConverter.FromPayload(payload, valuePtr)
+}
+
+func (r *contextRecorder) scopes() map[string]bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	result := map[string]bool{}
+	for k, v := range r.scope {
+		result[k] = v
+	}
+	return result
+}
+
+func contextAwareWorkflow(ctx Context) (string, error) {
+	if err := SetQueryHandler(ctx, "query", func(arg string) (string, error) {
+		return "query " + arg, nil
+	}); err != nil {
+		return "", err
+	}
+	ctx = WithActivityOptions(ctx, ActivityOptions{StartToCloseTimeout: 10 * time.Second})
+	var result string
+	if err := ExecuteActivity(ctx, "contextAwareActivity", "input").Get(ctx, &result); err != nil {
+		return "", err
+	}
+	var signal string
+	GetSignalChannel(ctx, "signal").Receive(ctx, &signal)
+	return result + " " + signal, nil
+}
+
+func contextAwareActivity(ctx context.Context, input string) (string, error) {
+	RecordActivityHeartbeat(ctx, "progress")
+	return "activity " + input, nil
+}
+
+func TestContextAwareDataConverter(t *testing.T) {
+	recorder := &contextRecorder{scope: map[string]bool{}}
+	dc := &contextRecordingDataConverter{DataConverter: converter.GetDefaultDataConverter(), recorder: recorder}
+	client, err := NewClient(ClientOptions{WorkflowService: NewInMemoryWorkflowService(), DataConverter: dc, Logger: ilog.NewNopLogger()})
+	require.NoError(t, err)
+	defer client.Close()
+
+	worker := NewAggregatedWorker(client.(*WorkflowClient), "context-aware-task-queue", WorkerOptions{})
+	worker.RegisterWorkflowWithOptions(contextAwareWorkflow, RegisterWorkflowOptions{Name: "contextAwareWorkflow"})
+	worker.RegisterActivityWithOptions(contextAwareActivity, RegisterActivityOptions{Name: "contextAwareActivity"})
+	require.NoError(t, worker.Start())
+	defer worker.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	run, err := client.ExecuteWorkflow(context.WithValue(ctx, contextAwareTestKey{}, "start"),
+		StartWorkflowOptions{ID: "context-aware", TaskQueue: "context-aware-task-queue"}, "contextAwareWorkflow")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		value, err := client.QueryWorkflow(context.WithValue(ctx, contextAwareTestKey{}, "query"), run.GetID(), run.GetRunID(), "query", "arg")
+		var result string
+		return err == nil && value.Get(&result) == nil && result == "query arg"
+	}, 10*time.Second, 10*time.Millisecond)
+	require.NoError(t, client.SignalWorkflow(context.WithValue(ctx, contextAwareTestKey{}, "signal"), run.GetID(), run.GetRunID(), "signal", "signal"))
+
+	var result string
+	require.NoError(t, run.Get(context.WithValue(ctx, contextAwareTestKey{}, "result"), &result))
+	require.Equal(t, "activity input signal", result)
+
+	require.Equal(t, map[string]bool{
+		"client start":                  true,
+		"client query":                  true,
+		"client signal":                 true,
+		"client result":                 true,
+		"workflow context-aware":        true,
+		"activity contextAwareActivity": true,
+	}, recorder.scopes())
+}
+
+func TestFailureConverterWithContext(t *testing.T) {
+	recorder := &contextRecorder{scope: map[string]bool{}}
+	dc := &contextRecordingDataConverter{DataConverter: converter.GetDefaultDataConverter(), recorder: recorder}
+	fc := NewDefaultFailureConverter(DefaultFailureConverterOptions{DataConverter: dc})
+	ctx := context.WithValue(context.Background(), contextAwareTestKey{}, "failure")
+
+	failure := failureConverterWithContext(ctx, fc).ErrorToFailure(NewApplicationError("message", "", false, nil, "details"))
+	err := failureConverterWithContext(ctx, fc).FailureToError(failure)
+	require.Error(t, err)
+	require.Equal(t, "message", err.Error())
+	require.Equal(t, map[string]bool{"client failure": true}, recorder.scopes())
+
+	// Failure converters without ContextAwareDataConverter are used as is.
+	require.Equal(t, GetDefaultFailureConverter(), failureConverterWithContext(ctx, GetDefaultFailureConverter()))
+}
@@ -126,6 +126,8 @@ type (
 		nonRetryable bool
 		cause        error
 		details      converter.EncodedValues
+		// typedErr is the error of a registered type reconstructed from details, see RegisterErrorType.
+		typedErr error
 	}
 
 	// TimeoutError returned when activity or child workflow timed out.
@@ -391,7 +393,7 @@ func NewContinueAsNewError(ctx Context, wfn interface{}, args ...interface{}) *C
 		panic("context is missing required options for continue as new")
 	}
 	env := getWorkflowEnvironment(ctx)
-	workflowType, input, err := getValidatedWorkflowFunction(wfn, args, options.DataConverter, env.GetRegistry())
+	workflowType, input, err := getValidatedWorkflowFunction(wfn, args, dataConverterWithWorkflowContext(ctx, options.DataConverter), env.GetRegistry())
 	if err != nil {
 		panic(err)
 	}
@@ -441,6 +443,15 @@ func (e *ApplicationError) Unwrap() error {
 	return e.cause
 }
 
+// As finds the first error in the chain of the original error that matches target.
+// It succeeds only if the type of the original error was registered with RegisterErrorType.
+func (e *ApplicationError) As(target interface{}) bool {
+	if e.typedErr == nil {
+		return false
+	}
+	return errors.As(e.typedErr, target)
+}
+
 // Error from error interface
 func (e *TimeoutError) Error() string {
 	return fmt.Sprintf("TimeoutType: %v, Cause: %v", e.timeoutType, e.cause)
@@ -715,6 +726,13 @@ func convertErrorToFailure(err error, dc converter.DataConverter) *failurepb.Fai
 			RetryState:       err.retryState,
 		}
 		failure.FailureInfo = &failurepb.Failure_ActivityFailureInfo{ActivityFailureInfo: failureInfo}
+	case *PayloadSizeError:
+		// Payload of the same size is produced on retry.
+		failureInfo := &failurepb.ApplicationFailureInfo{
+			Type:         getErrType(err),
+			NonRetryable: true,
+		}
+		failure.FailureInfo = &failurepb.Failure_ApplicationFailureInfo{ApplicationFailureInfo: failureInfo}
 	case *ChildWorkflowExecutionError:
 		failureInfo := &failurepb.ChildWorkflowExecutionFailureInfo{
 			Namespace: err.namespace,
@@ -732,6 +750,7 @@ func convertErrorToFailure(err error, dc converter.DataConverter) *failurepb.Fai
 		failureInfo := &failurepb.ApplicationFailureInfo{
 			Type:         getErrType(err),
 			NonRetryable: false,
+			Details:      encodeRegisteredError(err, dc),
 		}
 		failure.FailureInfo = &failurepb.Failure_ApplicationFailureInfo{ApplicationFailureInfo: failureInfo}
 	}
@@ -756,12 +775,14 @@ func convertFailureToError(failure *failurepb.Failure, dc converter.DataConverte
 		case getErrType(&PanicError{}):
 			err = newPanicError(failure.GetMessage(), failure.GetStackTrace())
 		default:
-			err = NewApplicationError(
+			applicationErr := NewApplicationError(
 				failure.GetMessage(),
 				applicationFailureInfo.GetType(),
 				applicationFailureInfo.GetNonRetryable(),
 				convertFailureToError(failure.GetCause(), dc),
 				details)
+			applicationErr.typedErr = decodeRegisteredError(applicationFailureInfo.GetDetails(), dc)
+			err = applicationErr
 		}
 	} else if failure.GetCanceledFailureInfo() != nil {
 		details := newEncodedValues(failure.GetCanceledFailureInfo().GetDetails(), dc)
@@ -0,0 +1,153 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	commonpb "go.temporal.io/api/common/v1"
+
+	"go.temporal.io/sdk/converter"
+)
+
+// errorTypeMetadataKey is the payload metadata key which marks ApplicationFailureInfo details that carry
+// the fields of a registered error type.
+const errorTypeMetadataKey = "errorType"
+
+type errorTypeRegistry struct {
+	sync.RWMutex
+	typeToName map[reflect.Type]string
+	nameToType map[string]reflect.Type
+}
+
+var errorTypes = &errorTypeRegistry{
+	typeToName: make(map[reflect.Type]string),
+	nameToType: make(map[string]reflect.Type),
+}
+
+// RegisterErrorType registers the type of err so that errors of this type returned from activities and workflows
+// are reconstructed on the caller side. Exported fields of the error are serialized into ApplicationError details
+// with the DataConverter. The caller receives the usual ApplicationError, and errors.As(err, &target) with a
+// target of the registered type succeeds and fills target with the deserialized error.
+// Both pointer (RegisterErrorType(&MyError{})) and value (RegisterErrorType(MyError{})) error types can be
+// registered, the error is reconstructed as the registered kind. This method panics if err is nil, isn't a
+// named type, or a different type with the same name is already registered.
+// Register the type in the processes of both the code returning the error and the code handling it.
+func RegisterErrorType(err error) {
+	if err == nil {
+		panic("error must not be nil")
+	}
+	errType := reflect.TypeOf(err)
+	name := getRegisteredErrorTypeName(errType)
+	if name == "" {
+		panic(fmt.Sprintf("error type %v must be a named type", errType))
+	}
+
+	errorTypes.Lock()
+	defer errorTypes.Unlock()
+	if registered, ok := errorTypes.nameToType[name]; ok && registered != errType {
+		panic(fmt.Sprintf("error type name %v is already registered for %v", name, registered))
+	}
+	errorTypes.typeToName[errType] = name
+	errorTypes.nameToType[name] = errType
+}
+
+func getRegisteredErrorTypeName(errType reflect.Type) string {
+	t := errType
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return ""
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// encodeRegisteredError returns details with the fields of the first error in the chain of err, as returned by
+// errors.Unwrap, whose type is registered.
+func encodeRegisteredError(err error, dc converter.DataConverter) *commonpb.Payloads {
+	var name string
+	var ok bool
+	errorTypes.RLock()
+	for ; err != nil; err = errors.Unwrap(err) {
+		if name, ok = errorTypes.typeToName[reflect.TypeOf(err)]; ok {
+			break
+		}
+	}
+	errorTypes.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	payload, encodeErr := dc.ToPayload(err)
+	if encodeErr != nil {
+		// Error is still sent as ApplicationError, just without its fields.
+		return nil
+	}
+	if payload.Metadata == nil {
+		payload.Metadata = make(map[string][]byte)
+	}
+	payload.Metadata[errorTypeMetadataKey] = []byte(name)
+	return &commonpb.Payloads{Payloads: []*commonpb.Payload{payload}}
+}
+
+// decodeRegisteredError reconstructs the error from details encoded by encodeRegisteredError.
+// It returns nil if the details don't carry a registered error type.
+func decodeRegisteredError(details *commonpb.Payloads, dc converter.DataConverter) error {
+	if len(details.GetPayloads()) != 1 {
+		return nil
+	}
+	payload := details.GetPayloads()[0]
+	name, ok := payload.GetMetadata()[errorTypeMetadataKey]
+	if !ok {
+		return nil
+	}
+
+	errorTypes.RLock()
+	errType, ok := errorTypes.nameToType[string(name)]
+	errorTypes.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	elemType := errType
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	value := reflect.New(elemType)
+	if err := dc.FromPayload(payload, value.Interface()); err != nil {
+		return nil
+	}
+	if errType.Kind() != reflect.Ptr {
+		value = value.Elem()
+	}
+	if err, ok := value.Interface().(error); ok {
+		return err
+	}
+	return nil
+}
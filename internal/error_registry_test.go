@@ -0,0 +1,187 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.temporal.io/sdk/converter"
+	ilog "go.temporal.io/sdk/internal/log"
+)
+
+type registeredPtrError struct {
+	Code   int
+	Reason string
+}
+
+func (e *registeredPtrError) Error() string {
+	return fmt.Sprintf("registered error %v: %v", e.Code, e.Reason)
+}
+
+type registeredValueError struct {
+	Code int
+}
+
+func (e registeredValueError) Error() string {
+	return fmt.Sprintf("registered value error %v", e.Code)
+}
+
+type unregisteredError struct {
+	Code int
+}
+
+func (e *unregisteredError) Error() string {
+	return "unregistered error"
+}
+
+func init() {
+	RegisterErrorType(&registeredPtrError{})
+	RegisterErrorType(registeredValueError{})
+}
+
+func TestRegisterErrorType_Panics(t *testing.T) {
+	require.Panics(t, func() { RegisterErrorType(nil) })
+	require.Panics(t, func() { RegisterErrorType(struct{ error }{errors.New("anonymous")}) })
+	// Same name, different kind.
+	require.Panics(t, func() { RegisterErrorType(&registeredValueError{}) })
+	// Registering the same type again is a no-op.
+	require.NotPanics(t, func() { RegisterErrorType(&registeredPtrError{}) })
+}
+
+func TestRegisteredErrorRoundTrip(t *testing.T) {
+	dc := converter.GetDefaultDataConverter()
+
+	err := convertFailureToError(convertErrorToFailure(&registeredPtrError{Code: 42, Reason: "reason"}, dc), dc)
+	var applicationErr *ApplicationError
+	require.True(t, errors.As(err, &applicationErr))
+	require.Equal(t, "registeredPtrError", applicationErr.Type())
+	require.Equal(t, "registered error 42: reason", applicationErr.Error())
+	var ptrErr *registeredPtrError
+	require.True(t, errors.As(err, &ptrErr))
+	require.Equal(t, &registeredPtrError{Code: 42, Reason: "reason"}, ptrErr)
+
+	wrapped := fmt.Errorf("wrapped: %w", registeredValueError{Code: 7})
+	err = convertFailureToError(convertErrorToFailure(wrapped, dc), dc)
+	var valueErr registeredValueError
+	require.True(t, errors.As(err, &valueErr))
+	require.Equal(t, 7, valueErr.Code)
+
+	err = convertFailureToError(convertErrorToFailure(&unregisteredError{Code: 1}, dc), dc)
+	require.True(t, errors.As(err, &applicationErr))
+	require.False(t, applicationErr.HasDetails())
+	var unregisteredErr *unregisteredError
+	require.False(t, errors.As(err, &unregisteredErr))
+
+	// Application error with the same type name but without the metadata isn't reconstructed.
+	err = convertFailureToError(convertErrorToFailure(NewApplicationError("message", "registeredPtrError", false, nil, registeredPtrError{Code: 1}), dc), dc)
+	require.False(t, errors.As(err, &ptrErr))
+}
+
+func TestRegisteredErrorWrapped(t *testing.T) {
+	dc := converter.GetDefaultDataConverter()
+
+	wrapped := fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", &registeredPtrError{Code: 3, Reason: "wrapped"}))
+	failure := convertErrorToFailure(wrapped, dc)
+	// The failure of the wrapping error carries the registered error, so it is reconstructed even without the cause.
+	failure.Cause = nil
+	err := convertFailureToError(failure, dc)
+	var applicationErr *ApplicationError
+	require.True(t, errors.As(err, &applicationErr))
+	require.Equal(t, "outer: inner: registered error 3: wrapped", applicationErr.Error())
+	var ptrErr *registeredPtrError
+	require.True(t, errors.As(err, &ptrErr))
+	require.Equal(t, &registeredPtrError{Code: 3, Reason: "wrapped"}, ptrErr)
+}
+
+func registeredErrorActivity(_ context.Context, code int) error {
+	return &registeredPtrError{Code: code, Reason: "activity"}
+}
+
+func registeredErrorChildWorkflow(_ Context, code int) error {
+	return registeredValueError{Code: code}
+}
+
+func registeredErrorWorkflow(ctx Context) (string, error) {
+	ctx = WithActivityOptions(ctx, ActivityOptions{
+		StartToCloseTimeout: 10 * time.Second,
+		RetryPolicy:         &RetryPolicy{MaximumAttempts: 1},
+	})
+	err := ExecuteActivity(ctx, registeredErrorActivity, 1).Get(ctx, nil)
+	var ptrErr *registeredPtrError
+	if !errors.As(err, &ptrErr) {
+		return "", fmt.Errorf("unexpected activity error: %v", err)
+	}
+
+	ctx = WithChildWorkflowOptions(ctx, ChildWorkflowOptions{WorkflowRunTimeout: 10 * time.Second})
+	err = ExecuteChildWorkflow(ctx, registeredErrorChildWorkflow, 2).Get(ctx, nil)
+	var valueErr registeredValueError
+	if !errors.As(err, &valueErr) {
+		return "", fmt.Errorf("unexpected child workflow error: %v", err)
+	}
+	return fmt.Sprintf("%v %v %v", ptrErr.Code, ptrErr.Reason, valueErr.Code), nil
+}
+
+func TestRegisteredErrorInWorkflow(t *testing.T) {
+	testSuite := &WorkflowTestSuite{}
+	env := testSuite.NewTestWorkflowEnvironment()
+	env.RegisterActivity(registeredErrorActivity)
+	env.RegisterWorkflow(registeredErrorChildWorkflow)
+	env.ExecuteWorkflow(registeredErrorWorkflow)
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	var result string
+	require.NoError(t, env.GetWorkflowResult(&result))
+	require.Equal(t, "1 activity 2", result)
+}
+
+func registeredErrorFailingWorkflow(_ Context) error {
+	return &registeredPtrError{Code: 3, Reason: "workflow"}
+}
+
+func TestRegisteredErrorFromWorkflowRun(t *testing.T) {
+	client, err := NewClient(ClientOptions{WorkflowService: NewInMemoryWorkflowService(), Logger: ilog.NewNopLogger()})
+	require.NoError(t, err)
+	defer client.Close()
+
+	worker := NewAggregatedWorker(client.(*WorkflowClient), "registered-error-task-queue", WorkerOptions{})
+	worker.RegisterWorkflowWithOptions(registeredErrorFailingWorkflow, RegisterWorkflowOptions{Name: "registeredErrorFailingWorkflow"})
+	require.NoError(t, worker.Start())
+	defer worker.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	run, err := client.ExecuteWorkflow(ctx, StartWorkflowOptions{ID: "registered-error", TaskQueue: "registered-error-task-queue"}, "registeredErrorFailingWorkflow")
+	require.NoError(t, err)
+	err = run.Get(ctx, nil)
+	var ptrErr *registeredPtrError
+	require.True(t, errors.As(err, &ptrErr))
+	require.Equal(t, &registeredPtrError{Code: 3, Reason: "workflow"}, ptrErr)
+}
@@ -134,8 +134,9 @@ func Test_TimeoutError_WithDetails(t *testing.T) {
 
 func testTimeoutErrorDetails(t *testing.T, timeoutType enumspb.TimeoutType) {
 	context := &workflowEnvironmentImpl{
-		commandsHelper: newCommandsHelper(),
-		dataConverter:  converter.GetDefaultDataConverter(),
+		commandsHelper:   newCommandsHelper(),
+		dataConverter:    converter.GetDefaultDataConverter(),
+		failureConverter: GetDefaultFailureConverter(),
 	}
 	h := newCommandsHelper()
 	var actualErr error
@@ -454,8 +455,9 @@ func TestErrorDetailsValues(t *testing.T) {
 
 func Test_SignalExternalWorkflowExecutionFailedError(t *testing.T) {
 	context := &workflowEnvironmentImpl{
-		commandsHelper: newCommandsHelper(),
-		dataConverter:  converter.GetDefaultDataConverter(),
+		commandsHelper:   newCommandsHelper(),
+		dataConverter:    converter.GetDefaultDataConverter(),
+		failureConverter: GetDefaultFailureConverter(),
 	}
 	h := newCommandsHelper()
 	var actualErr error
@@ -0,0 +1,85 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	failurepb "go.temporal.io/api/failure/v1"
+
+	"go.temporal.io/sdk/converter"
+)
+
+type (
+	// FailureConverter is used by the sdk to serialize/deserialize errors
+	// that need to be sent over the wire.
+	// To use a custom FailureConverter, set FailureConverter in ClientOptions or WorkerOptions.
+	FailureConverter interface {
+		// ErrorToFailure converts an error to a Failure proto message.
+		ErrorToFailure(err error) *failurepb.Failure
+
+		// FailureToError converts a Failure proto message to a Go Error.
+		FailureToError(failure *failurepb.Failure) error
+	}
+
+	// DefaultFailureConverterOptions are optional parameters for DefaultFailureConverter creation.
+	DefaultFailureConverterOptions struct {
+		// Optional: Sets DataConverter to customize serialization/deserialization of fields.
+		// default: Default data converter
+		DataConverter converter.DataConverter
+	}
+
+	// DefaultFailureConverter converts errors to failures and back, encoding failure details with a DataConverter.
+	// Messages and stack traces are sent in plain text, the Failure proto of the server API this SDK is built
+	// against has no field to carry them encoded.
+	DefaultFailureConverter struct {
+		dataConverter converter.DataConverter
+	}
+)
+
+var defaultFailureConverter = NewDefaultFailureConverter(DefaultFailureConverterOptions{})
+
+// GetDefaultFailureConverter returns the default failure converter used by Temporal.
+func GetDefaultFailureConverter() FailureConverter {
+	return defaultFailureConverter
+}
+
+// NewDefaultFailureConverter creates new instance of DefaultFailureConverter.
+func NewDefaultFailureConverter(opt DefaultFailureConverterOptions) *DefaultFailureConverter {
+	if opt.DataConverter == nil {
+		opt.DataConverter = converter.GetDefaultDataConverter()
+	}
+	return &DefaultFailureConverter{
+		dataConverter: opt.DataConverter,
+	}
+}
+
+// ErrorToFailure converts an error to a Failure.
+func (dfc *DefaultFailureConverter) ErrorToFailure(err error) *failurepb.Failure {
+	return convertErrorToFailure(err, dfc.dataConverter)
+}
+
+// FailureToError converts a Failure to an error.
+func (dfc *DefaultFailureConverter) FailureToError(failure *failurepb.Failure) error {
+	return convertFailureToError(failure, dfc.dataConverter)
+}
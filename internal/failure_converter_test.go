@@ -0,0 +1,99 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+
+	ilog "go.temporal.io/sdk/internal/log"
+)
+
+func TestDefaultFailureConverter(t *testing.T) {
+	fc := NewDefaultFailureConverter(DefaultFailureConverterOptions{})
+	failure := fc.ErrorToFailure(NewApplicationError("message", "MessageType", false, nil, "details"))
+	require.Equal(t, "message", failure.GetMessage())
+
+	err := fc.FailureToError(failure)
+	var applicationErr *ApplicationError
+	require.True(t, errors.As(err, &applicationErr))
+	require.Equal(t, "message", applicationErr.Error())
+	require.Equal(t, "MessageType", applicationErr.Type())
+	var details string
+	require.NoError(t, applicationErr.Details(&details))
+	require.Equal(t, "details", details)
+}
+
+// redactingFailureConverter replaces the messages of the failures it creates.
+type redactingFailureConverter struct {
+	FailureConverter
+}
+
+func (r redactingFailureConverter) ErrorToFailure(err error) *failurepb.Failure {
+	failure := r.FailureConverter.ErrorToFailure(err)
+	for f := failure; f != nil; f = f.GetCause() {
+		f.Message = "redacted"
+	}
+	return failure
+}
+
+func failingWorkflow(Context) error {
+	return NewApplicationError("secret workflow failure", "SecretType", true, nil)
+}
+
+func TestFailureConverterUsedByWorker(t *testing.T) {
+	fc := redactingFailureConverter{FailureConverter: GetDefaultFailureConverter()}
+	client, err := NewClient(ClientOptions{WorkflowService: NewInMemoryWorkflowService(), FailureConverter: fc, Logger: ilog.NewNopLogger()})
+	require.NoError(t, err)
+	defer client.Close()
+
+	worker := NewAggregatedWorker(client.(*WorkflowClient), "failure-task-queue", WorkerOptions{})
+	worker.RegisterWorkflowWithOptions(failingWorkflow, RegisterWorkflowOptions{Name: "failingWorkflow"})
+	require.NoError(t, worker.Start())
+	defer worker.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	run, err := client.ExecuteWorkflow(ctx, StartWorkflowOptions{ID: "failure", TaskQueue: "failure-task-queue"}, "failingWorkflow")
+	require.NoError(t, err)
+	err = run.Get(ctx, nil)
+	var applicationErr *ApplicationError
+	require.True(t, errors.As(err, &applicationErr))
+	require.Equal(t, "redacted", applicationErr.Error())
+	require.Equal(t, "SecretType", applicationErr.Type())
+
+	iter := client.GetWorkflowHistory(ctx, "failure", run.GetRunID(), false, enumspb.HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT)
+	require.True(t, iter.HasNext())
+	event, err := iter.Next()
+	require.NoError(t, err)
+	failure := event.GetWorkflowExecutionFailedEventAttributes().GetFailure()
+	require.Equal(t, "redacted", failure.GetMessage())
+}
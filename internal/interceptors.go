@@ -25,9 +25,11 @@
 package internal
 
 import (
+	"context"
 	"time"
 
 	"github.com/uber-go/tally"
+	commonpb "go.temporal.io/api/common/v1"
 
 	"go.temporal.io/sdk/converter"
 	"go.temporal.io/sdk/log"
@@ -54,7 +56,14 @@ type WorkflowInboundCallsInterceptor interface {
 	// WorkflowType argument is for information purposes only and should not be mutated.
 	ExecuteWorkflow(ctx Context, workflowType string, args ...interface{}) []interface{}
 
-	//TODO(maxim): ProcessSignal(ctx Context, signalName string, arg interface{}) error
+	// HandleSignal intercepts delivery of a signal to the workflow signal channel. Arg is not decoded as the
+	// type of the signal argument is defined by the code that receives from the channel.
+	// Returning an error fails the workflow task.
+	HandleSignal(ctx Context, signalName string, arg *commonpb.Payloads) error
+
+	// HandleQuery intercepts dispatch of a query to the handler registered through SetQueryHandler.
+	// It is called outside of the workflow coroutines, so it must not block.
+	HandleQuery(ctx Context, queryType string, args *commonpb.Payloads) (*commonpb.Payloads, error)
 }
 
 // WorkflowOutboundCallsInterceptor is an interface that can be implemented to intercept calls to the SDK APIs done
@@ -98,15 +107,25 @@ type WorkflowInboundCallsInterceptorBase struct {
 }
 
 // Init called before the workflow function is invoked
-func (w WorkflowInboundCallsInterceptorBase) Init(outbound WorkflowOutboundCallsInterceptor) error {
+func (w *WorkflowInboundCallsInterceptorBase) Init(outbound WorkflowOutboundCallsInterceptor) error {
 	return w.Next.Init(outbound)
 }
 
 // ExecuteWorkflow intercepts invocation of the workflow function
-func (w WorkflowInboundCallsInterceptorBase) ExecuteWorkflow(ctx Context, workflowType string, args ...interface{}) []interface{} {
+func (w *WorkflowInboundCallsInterceptorBase) ExecuteWorkflow(ctx Context, workflowType string, args ...interface{}) []interface{} {
 	return w.Next.ExecuteWorkflow(ctx, workflowType, args...)
 }
 
+// HandleSignal intercepts delivery of a signal to the workflow
+func (w *WorkflowInboundCallsInterceptorBase) HandleSignal(ctx Context, signalName string, arg *commonpb.Payloads) error {
+	return w.Next.HandleSignal(ctx, signalName, arg)
+}
+
+// HandleQuery intercepts dispatch of a query to the workflow
+func (w *WorkflowInboundCallsInterceptorBase) HandleQuery(ctx Context, queryType string, args *commonpb.Payloads) (*commonpb.Payloads, error) {
+	return w.Next.HandleQuery(ctx, queryType, args)
+}
+
 // WorkflowOutboundCallsInterceptorBase is a noop implementation of WorkflowOutboundCallsInterceptor that just forwards requests
 // to the next link in an interceptor chain. To be used as base implementation of interceptors.
 type WorkflowOutboundCallsInterceptorBase struct {
@@ -217,3 +236,148 @@ func (t *WorkflowOutboundCallsInterceptorBase) HasLastCompletionResult(ctx Conte
 func (t *WorkflowOutboundCallsInterceptorBase) GetLastCompletionResult(ctx Context, d ...interface{}) error {
 	return t.Next.GetLastCompletionResult(ctx, d...)
 }
+
+// ActivityInterceptor is used to create a single link in the activity interceptor chain
+type ActivityInterceptor interface {
+	// InterceptActivity creates an interceptor instance. The created instance must delegate every call to
+	// the next parameter for activity code function correctly.
+	InterceptActivity(info *ActivityInfo, next ActivityInboundCallsInterceptor) ActivityInboundCallsInterceptor
+}
+
+// ActivityInboundCallsInterceptor is an interface that can be implemented to intercept calls to the activity.
+// Use ActivityInboundCallsInterceptorBase as a base struct for implementations that do not want to implement every method.
+// Interceptor implementation must forward calls to the next in the interceptor chain.
+type ActivityInboundCallsInterceptor interface {
+	Init(outbound ActivityOutboundCallsInterceptor) error
+
+	// ExecuteActivity intercepts activity function invocation. Args are the already decoded activity arguments
+	// and the returned slice contains all the values returned by the activity function including the error.
+	// ActivityType argument is for information purposes only and should not be mutated.
+	ExecuteActivity(ctx context.Context, activityType string, args ...interface{}) []interface{}
+}
+
+// ActivityOutboundCallsInterceptor is an interface that can be implemented to intercept calls to the SDK APIs done
+// by the activity code.
+// Use ActivityOutboundCallsInterceptorBase as a base struct for implementations that do not want to implement every method.
+// Interceptor implementation must forward calls to the next in the interceptor chain.
+type ActivityOutboundCallsInterceptor interface {
+	GetInfo(ctx context.Context) ActivityInfo
+	GetLogger(ctx context.Context) log.Logger
+	GetMetricsScope(ctx context.Context) tally.Scope
+	RecordHeartbeat(ctx context.Context, details ...interface{})
+}
+
+var _ ActivityOutboundCallsInterceptor = (*ActivityOutboundCallsInterceptorBase)(nil)
+var _ ActivityInboundCallsInterceptor = (*ActivityInboundCallsInterceptorBase)(nil)
+
+// ActivityInboundCallsInterceptorBase is a noop implementation of ActivityInboundCallsInterceptor that just forwards requests
+// to the next link in an interceptor chain. To be used as base implementation of interceptors.
+type ActivityInboundCallsInterceptorBase struct {
+	Next ActivityInboundCallsInterceptor
+}
+
+// Init called before the activity function is invoked
+func (a *ActivityInboundCallsInterceptorBase) Init(outbound ActivityOutboundCallsInterceptor) error {
+	return a.Next.Init(outbound)
+}
+
+// ExecuteActivity intercepts invocation of the activity function
+func (a *ActivityInboundCallsInterceptorBase) ExecuteActivity(ctx context.Context, activityType string, args ...interface{}) []interface{} {
+	return a.Next.ExecuteActivity(ctx, activityType, args...)
+}
+
+// ActivityOutboundCallsInterceptorBase is a noop implementation of ActivityOutboundCallsInterceptor that just forwards requests
+// to the next link in an interceptor chain. To be used as base implementation of interceptors.
+type ActivityOutboundCallsInterceptorBase struct {
+	Next ActivityOutboundCallsInterceptor
+}
+
+// GetInfo forwards to t.Next
+func (t *ActivityOutboundCallsInterceptorBase) GetInfo(ctx context.Context) ActivityInfo {
+	return t.Next.GetInfo(ctx)
+}
+
+// GetLogger forwards to t.Next
+func (t *ActivityOutboundCallsInterceptorBase) GetLogger(ctx context.Context) log.Logger {
+	return t.Next.GetLogger(ctx)
+}
+
+// GetMetricsScope forwards to t.Next
+func (t *ActivityOutboundCallsInterceptorBase) GetMetricsScope(ctx context.Context) tally.Scope {
+	return t.Next.GetMetricsScope(ctx)
+}
+
+// RecordHeartbeat forwards to t.Next
+func (t *ActivityOutboundCallsInterceptorBase) RecordHeartbeat(ctx context.Context, details ...interface{}) {
+	t.Next.RecordHeartbeat(ctx, details...)
+}
+
+// ClientInterceptor is used to create a single link in the client interceptor chain
+type ClientInterceptor interface {
+	// InterceptClient creates an interceptor instance. The created instance must delegate every call to
+	// the next parameter for the client to function correctly.
+	InterceptClient(next ClientOutboundCallsInterceptor) ClientOutboundCallsInterceptor
+}
+
+// ClientOutboundCallsInterceptor is an interface that can be implemented to intercept calls done through the Client.
+// Use ClientOutboundCallsInterceptorBase as a base struct for implementations that do not want to implement every method.
+// Interceptor implementation must forward calls to the next in the interceptor chain.
+// Arguments are passed to the interceptors before they are encoded by the DataConverter. Headers passed to
+// ExecuteWorkflow, StartWorkflow and SignalWithStartWorkflow are already populated by the ContextPropagators and can be
+// mutated by the interceptor before the request is sent to the service.
+// ExecuteWorkflow intercepts Client.ExecuteWorkflow, which returns the running execution when it was already started.
+// StartWorkflow intercepts WorkflowClient.StartWorkflow, which returns the WorkflowExecutionAlreadyStarted error instead.
+type ClientOutboundCallsInterceptor interface {
+	ExecuteWorkflow(ctx context.Context, header *commonpb.Header, options StartWorkflowOptions, workflowType string, args ...interface{}) (WorkflowRun, error)
+	StartWorkflow(ctx context.Context, header *commonpb.Header, options StartWorkflowOptions, workflowType string, args ...interface{}) (*WorkflowExecution, error)
+	SignalWorkflow(ctx context.Context, workflowID, runID, signalName string, arg interface{}) error
+	SignalWithStartWorkflow(ctx context.Context, header *commonpb.Header, workflowID, signalName string, signalArg interface{},
+		options StartWorkflowOptions, workflowType string, workflowArgs ...interface{}) (WorkflowRun, error)
+	CancelWorkflow(ctx context.Context, workflowID, runID string) error
+	TerminateWorkflow(ctx context.Context, workflowID, runID, reason string, details ...interface{}) error
+	QueryWorkflow(ctx context.Context, request *QueryWorkflowWithOptionsRequest) (*QueryWorkflowWithOptionsResponse, error)
+}
+
+var _ ClientOutboundCallsInterceptor = (*ClientOutboundCallsInterceptorBase)(nil)
+
+// ClientOutboundCallsInterceptorBase is a noop implementation of ClientOutboundCallsInterceptor that just forwards requests
+// to the next link in an interceptor chain. To be used as base implementation of interceptors.
+type ClientOutboundCallsInterceptorBase struct {
+	Next ClientOutboundCallsInterceptor
+}
+
+// ExecuteWorkflow forwards to t.Next
+func (t *ClientOutboundCallsInterceptorBase) ExecuteWorkflow(ctx context.Context, header *commonpb.Header, options StartWorkflowOptions, workflowType string, args ...interface{}) (WorkflowRun, error) {
+	return t.Next.ExecuteWorkflow(ctx, header, options, workflowType, args...)
+}
+
+// StartWorkflow forwards to t.Next
+func (t *ClientOutboundCallsInterceptorBase) StartWorkflow(ctx context.Context, header *commonpb.Header, options StartWorkflowOptions, workflowType string, args ...interface{}) (*WorkflowExecution, error) {
+	return t.Next.StartWorkflow(ctx, header, options, workflowType, args...)
+}
+
+// SignalWorkflow forwards to t.Next
+func (t *ClientOutboundCallsInterceptorBase) SignalWorkflow(ctx context.Context, workflowID, runID, signalName string, arg interface{}) error {
+	return t.Next.SignalWorkflow(ctx, workflowID, runID, signalName, arg)
+}
+
+// SignalWithStartWorkflow forwards to t.Next
+func (t *ClientOutboundCallsInterceptorBase) SignalWithStartWorkflow(ctx context.Context, header *commonpb.Header, workflowID, signalName string, signalArg interface{},
+	options StartWorkflowOptions, workflowType string, workflowArgs ...interface{}) (WorkflowRun, error) {
+	return t.Next.SignalWithStartWorkflow(ctx, header, workflowID, signalName, signalArg, options, workflowType, workflowArgs...)
+}
+
+// CancelWorkflow forwards to t.Next
+func (t *ClientOutboundCallsInterceptorBase) CancelWorkflow(ctx context.Context, workflowID, runID string) error {
+	return t.Next.CancelWorkflow(ctx, workflowID, runID)
+}
+
+// TerminateWorkflow forwards to t.Next
+func (t *ClientOutboundCallsInterceptorBase) TerminateWorkflow(ctx context.Context, workflowID, runID, reason string, details ...interface{}) error {
+	return t.Next.TerminateWorkflow(ctx, workflowID, runID, reason, details...)
+}
+
+// QueryWorkflow forwards to t.Next
+func (t *ClientOutboundCallsInterceptorBase) QueryWorkflow(ctx context.Context, request *QueryWorkflowWithOptionsRequest) (*QueryWorkflowWithOptionsResponse, error) {
+	return t.Next.QueryWorkflow(ctx, request)
+}
@@ -151,9 +151,11 @@ type (
 )
 
 const (
-	activityEnvContextKey          contextKey = "activityEnv"
-	activityOptionsContextKey      contextKey = "activityOptions"
-	localActivityOptionsContextKey contextKey = "localActivityOptions"
+	activityEnvContextKey            contextKey = "activityEnv"
+	activityOptionsContextKey        contextKey = "activityOptions"
+	localActivityOptionsContextKey   contextKey = "localActivityOptions"
+	activityEnvInterceptorContextKey contextKey = "activityEnvInterceptor"
+	activityInterceptorContextKey    contextKey = "activityInterceptor"
 )
 
 func getActivityEnv(ctx context.Context) *activityEnvironment {
@@ -164,6 +166,118 @@ func getActivityEnv(ctx context.Context) *activityEnvironment {
 	return env.(*activityEnvironment)
 }
 
+// activityEnvironmentInterceptor is the last link of the activity interceptor chain. On the inbound side it invokes
+// the activity function, on the outbound side it implements the activity APIs on top of the activityEnvironment.
+type activityEnvironmentInterceptor struct {
+	fn                  interface{}
+	inboundInterceptor  ActivityInboundCallsInterceptor
+	outboundInterceptor ActivityOutboundCallsInterceptor
+}
+
+func newActivityInterceptors(ctx context.Context, interceptors []ActivityInterceptor) (context.Context, error) {
+	envInterceptor := &activityEnvironmentInterceptor{}
+	var interceptor ActivityInboundCallsInterceptor = envInterceptor
+	if len(interceptors) > 0 {
+		info := envInterceptor.GetInfo(ctx)
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor = interceptors[i].InterceptActivity(&info, interceptor)
+		}
+	}
+	envInterceptor.inboundInterceptor = interceptor
+	err := interceptor.Init(envInterceptor)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, activityEnvInterceptorContextKey, envInterceptor)
+	ctx = context.WithValue(ctx, activityInterceptorContextKey, envInterceptor.outboundInterceptor)
+	return ctx, nil
+}
+
+// getActivityEnvironmentInterceptor returns the interceptor chain created for the activity execution. Contexts that
+// were not created through newActivityInterceptors get a chain without any user interceptors.
+func getActivityEnvironmentInterceptor(ctx context.Context) *activityEnvironmentInterceptor {
+	if ctx != nil {
+		if ai, ok := ctx.Value(activityEnvInterceptorContextKey).(*activityEnvironmentInterceptor); ok {
+			return ai
+		}
+	}
+	envInterceptor := &activityEnvironmentInterceptor{}
+	envInterceptor.inboundInterceptor = envInterceptor
+	envInterceptor.outboundInterceptor = envInterceptor
+	return envInterceptor
+}
+
+func getActivityOutboundInterceptor(ctx context.Context) ActivityOutboundCallsInterceptor {
+	if ai, ok := ctx.Value(activityInterceptorContextKey).(ActivityOutboundCallsInterceptor); ok {
+		return ai
+	}
+	return &activityEnvironmentInterceptor{}
+}
+
+func (a *activityEnvironmentInterceptor) Init(outbound ActivityOutboundCallsInterceptor) error {
+	a.outboundInterceptor = outbound
+	return nil
+}
+
+func (a *activityEnvironmentInterceptor) ExecuteActivity(ctx context.Context, activityType string, args ...interface{}) (results []interface{}) {
+	ae := activityExecutor{name: activityType, fn: a.fn}
+	retValues := ae.executeWithActualArgsWithoutParseResult(ctx, args)
+	for _, r := range retValues {
+		results = append(results, r.Interface())
+	}
+	return
+}
+
+func (a *activityEnvironmentInterceptor) GetInfo(ctx context.Context) ActivityInfo {
+	env := getActivityEnv(ctx)
+	return ActivityInfo{
+		ActivityID:         env.activityID,
+		ActivityType:       env.activityType,
+		TaskToken:          env.taskToken,
+		WorkflowExecution:  env.workflowExecution,
+		HeartbeatTimeout:   env.heartbeatTimeout,
+		Deadline:           env.deadline,
+		ScheduledTimestamp: env.scheduledTimestamp,
+		StartedTimestamp:   env.startedTimestamp,
+		TaskQueue:          env.taskQueue,
+		Attempt:            env.attempt,
+		WorkflowType:       env.workflowType,
+		WorkflowNamespace:  env.workflowNamespace,
+	}
+}
+
+func (a *activityEnvironmentInterceptor) GetLogger(ctx context.Context) log.Logger {
+	env := getActivityEnv(ctx)
+	return env.logger
+}
+
+func (a *activityEnvironmentInterceptor) GetMetricsScope(ctx context.Context) tally.Scope {
+	env := getActivityEnv(ctx)
+	return env.metricsScope
+}
+
+func (a *activityEnvironmentInterceptor) RecordHeartbeat(ctx context.Context, details ...interface{}) {
+	env := getActivityEnv(ctx)
+	if env.isLocalActivity {
+		// no-op for local activity
+		return
+	}
+	var data *commonpb.Payloads
+	var err error
+	// We would like to be a able to pass in "nil" as part of details(that is no progress to report to)
+	if len(details) > 1 || (len(details) == 1 && details[0] != nil) {
+		data, err = encodeArgs(getDataConverterFromActivityCtx(ctx), details)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	err = env.serviceInvoker.Heartbeat(data, false)
+	if err != nil {
+		getActivityOutboundInterceptor(ctx).GetLogger(ctx).Debug("RecordActivityHeartbeat with error", tagError, err)
+	}
+}
+
 func getActivityOptions(ctx Context) *ExecuteActivityOptions {
 	eap := ctx.Value(activityOptionsContextKey)
 	if eap == nil {
@@ -276,8 +390,8 @@ func isActivityContext(inType reflect.Type) bool {
 	return inType != nil && inType.Implements(contextElem)
 }
 
-func validateFunctionAndGetResults(f interface{}, values []reflect.Value, dataConverter converter.DataConverter) (*commonpb.Payloads, error) {
-	resultSize := len(values)
+func validateFunctionAndGetResults(f interface{}, results []interface{}, dataConverter converter.DataConverter) (*commonpb.Payloads, error) {
+	resultSize := len(results)
 
 	if resultSize < 1 || resultSize > 2 {
 		fnName := getFunctionName(f)
@@ -290,13 +404,13 @@ func validateFunctionAndGetResults(f interface{}, values []reflect.Value, dataCo
 
 	// Parse result
 	if resultSize > 1 {
-		retValue := values[0]
+		retValue := results[0]
 
 		var ok bool
-		if result, ok = retValue.Interface().(*commonpb.Payloads); !ok {
-			if retValue.Kind() != reflect.Ptr || !retValue.IsNil() {
+		if result, ok = retValue.(*commonpb.Payloads); !ok {
+			if v := functionResultValue(f, 0, retValue); v.Kind() != reflect.Ptr || !v.IsNil() {
 				var err error
-				if result, err = encodeArg(dataConverter, retValue.Interface()); err != nil {
+				if result, err = encodeArg(dataConverter, retValue); err != nil {
 					return nil, err
 				}
 			}
@@ -304,19 +418,39 @@ func validateFunctionAndGetResults(f interface{}, values []reflect.Value, dataCo
 	}
 
 	// Parse error.
-	errValue := values[resultSize-1]
-	if errValue.IsNil() {
+	errResult := results[resultSize-1]
+	if v := functionResultValue(f, resultSize-1, errResult); !v.IsValid() ||
+		((v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr) && v.IsNil()) {
 		return result, nil
 	}
-	errInterface, ok := errValue.Interface().(error)
+	errInterface, ok := errResult.(error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"failed to parse error result as it is not of error interface: %v",
-			errValue)
+			errResult)
 	}
 	return result, errInterface
 }
 
+// functionResultValue returns the result at index i of the function f as a value of the declared result type.
+// A typed nil pointer returned through an interface result, like error, is therefore not nil.
+func functionResultValue(f interface{}, i int, r interface{}) reflect.Value {
+	v := reflect.ValueOf(r)
+	fnType := reflect.TypeOf(f)
+	if fnType == nil || fnType.Kind() != reflect.Func || i >= fnType.NumOut() {
+		return v
+	}
+	declared := reflect.New(fnType.Out(i)).Elem()
+	if !v.IsValid() {
+		return declared
+	}
+	if !v.Type().AssignableTo(declared.Type()) {
+		return v
+	}
+	declared.Set(v)
+	return declared
+}
+
 func serializeResults(f interface{}, results []interface{}, dataConverter converter.DataConverter) (result *commonpb.Payloads, err error) {
 	// results contain all results including error
 	resultSize := len(results)
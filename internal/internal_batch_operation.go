@@ -0,0 +1,281 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.temporal.io/api/serviceerror"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultBatchOperationConcurrency = 10
+	defaultBatchOperationPageSize    = 1000
+)
+
+type (
+	// BatchOperationType is the operation BatchOperation applies to every workflow execution matching the query.
+	BatchOperationType int
+
+	// BatchOperationOptions configure BatchOperation.
+	BatchOperationOptions struct {
+		// Query is a required visibility query selecting the workflow executions to operate on.
+		Query string
+
+		// Type is a required field specifying the operation applied to every execution.
+		Type BatchOperationType
+
+		// SignalName and SignalArg are used by BatchOperationSignal.
+		SignalName string
+		SignalArg  interface{}
+
+		// Reason and Details are used by BatchOperationTerminate.
+		Reason  string
+		Details []interface{}
+
+		// Optional: Maximum number of operations running concurrently.
+		// default: 10
+		Concurrency int
+
+		// Optional: Maximum number of operations started per second.
+		// default: no limit
+		RPS float64
+
+		// Optional: Page size of the visibility calls.
+		// default: 1000
+		PageSize int32
+
+		// Optional: Use ScanWorkflow instead of ListWorkflow to page through the executions. Scan doesn't guarantee
+		// ordering but is more efficient and isn't affected by executions leaving the query result while
+		// the batch is running, which is what happens when the query filters on the execution status.
+		// default: false
+		UseScan bool
+
+		// Optional: NextPageToken returned in a BatchOperationResult of a previous, interrupted, batch.
+		// The batch resumes from the page the previous one did not complete.
+		NextPageToken []byte
+
+		// Optional: ProcessedExecutions returned in the same BatchOperationResult as NextPageToken.
+		// The executions of the resumed page listed here are skipped, so the operation isn't applied twice.
+		ProcessedExecutions []WorkflowExecution
+
+		// Optional: Called after every page of executions with the cumulative result of the batch so far.
+		OnProgress func(result BatchOperationResult)
+	}
+
+	// BatchOperationResult is the progress and result report of BatchOperation.
+	BatchOperationResult struct {
+		// Processed is the number of executions the operation was applied to, including the failed ones.
+		Processed int
+
+		// Failures contains every execution the operation failed for.
+		Failures []BatchOperationFailure
+
+		// NextPageToken can be passed to BatchOperationOptions.NextPageToken to resume an interrupted batch.
+		// It is empty once all matching executions were processed.
+		NextPageToken []byte
+
+		// ProcessedExecutions are the executions of the page NextPageToken points to that the operation was
+		// already applied to, or failed for with an error retrying can't fix, like an execution that doesn't exist
+		// anymore. Pass it to BatchOperationOptions.ProcessedExecutions together with NextPageToken, so a resumed
+		// batch retries the other failures.
+		ProcessedExecutions []WorkflowExecution
+	}
+
+	// BatchOperationFailure is the failure of the batch operation for a single workflow execution.
+	BatchOperationFailure struct {
+		Execution WorkflowExecution
+		Err       error
+	}
+)
+
+const (
+	// BatchOperationSignal signals every execution with BatchOperationOptions.SignalName and SignalArg.
+	BatchOperationSignal BatchOperationType = iota + 1
+	// BatchOperationCancel requests cancellation of every execution.
+	BatchOperationCancel
+	// BatchOperationTerminate terminates every execution with BatchOperationOptions.Reason and Details.
+	BatchOperationTerminate
+)
+
+// BatchOperation pages through the executions matching the query and applies the operation to each of them.
+// Progress is tracked per execution: when the batch is interrupted every started operation is waited for, and the
+// executions of the incomplete page that were processed are returned in the result, so a resumed batch skips them.
+func (wc *WorkflowClient) BatchOperation(ctx context.Context, options BatchOperationOptions) (*BatchOperationResult, error) {
+	if options.Query == "" {
+		return nil, errors.New("query is required")
+	}
+	operation, err := wc.getBatchOperationFunc(options)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchOperationConcurrency
+	}
+	pageSize := options.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultBatchOperationPageSize
+	}
+	limiter := rate.NewLimiter(rate.Inf, 1)
+	if options.RPS > 0 {
+		limiter = rate.NewLimiter(rate.Limit(options.RPS), 1)
+	}
+
+	result := &BatchOperationResult{
+		NextPageToken:       options.NextPageToken,
+		ProcessedExecutions: append([]WorkflowExecution(nil), options.ProcessedExecutions...),
+	}
+	for {
+		executions, nextPageToken, err := wc.getBatchOperationPage(ctx, options, pageSize, result.NextPageToken)
+		if err != nil {
+			return result, err
+		}
+
+		processed := make(map[WorkflowExecution]bool, len(result.ProcessedExecutions))
+		for _, execution := range result.ProcessedExecutions {
+			processed[execution] = true
+		}
+		var lock sync.Mutex
+		var wg sync.WaitGroup
+		tokens := make(chan struct{}, concurrency)
+		for _, execution := range executions {
+			if processed[execution] {
+				continue
+			}
+			if err := limiter.Wait(ctx); err != nil {
+				// The page is not complete, so NextPageToken still points to it.
+				wg.Wait()
+				return result, err
+			}
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				wg.Wait()
+				return result, ctx.Err()
+			}
+			wg.Add(1)
+			go func(execution WorkflowExecution) {
+				defer func() {
+					<-tokens
+					wg.Done()
+				}()
+				err := operation(ctx, execution)
+				lock.Lock()
+				defer lock.Unlock()
+				result.Processed++
+				if err == nil || isBatchOperationPermanentError(err) {
+					result.ProcessedExecutions = append(result.ProcessedExecutions, execution)
+				}
+				if err != nil {
+					result.Failures = append(result.Failures, BatchOperationFailure{Execution: execution, Err: err})
+				}
+			}(execution)
+		}
+		wg.Wait()
+
+		result.NextPageToken = nextPageToken
+		result.ProcessedExecutions = nil
+		if options.OnProgress != nil {
+			options.OnProgress(*result)
+		}
+		if len(nextPageToken) == 0 {
+			return result, nil
+		}
+	}
+}
+
+// isBatchOperationPermanentError reports whether the operation failed for a reason resuming the batch can't fix.
+// The server returns NotFound for executions that already completed.
+func isBatchOperationPermanentError(err error) bool {
+	switch err.(type) {
+	case *serviceerror.NotFound, *serviceerror.CancellationAlreadyRequested:
+		return true
+	}
+	return false
+}
+
+func (wc *WorkflowClient) getBatchOperationFunc(options BatchOperationOptions) (func(context.Context, WorkflowExecution) error, error) {
+	switch options.Type {
+	case BatchOperationSignal:
+		if options.SignalName == "" {
+			return nil, errors.New("signalName is required for BatchOperationSignal")
+		}
+		return func(ctx context.Context, execution WorkflowExecution) error {
+			return wc.SignalWorkflow(ctx, execution.ID, execution.RunID, options.SignalName, options.SignalArg)
+		}, nil
+	case BatchOperationCancel:
+		return func(ctx context.Context, execution WorkflowExecution) error {
+			return wc.CancelWorkflow(ctx, execution.ID, execution.RunID)
+		}, nil
+	case BatchOperationTerminate:
+		return func(ctx context.Context, execution WorkflowExecution) error {
+			return wc.TerminateWorkflow(ctx, execution.ID, execution.RunID, options.Reason, options.Details...)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown batch operation type %v", options.Type)
+	}
+}
+
+func (wc *WorkflowClient) getBatchOperationPage(ctx context.Context, options BatchOperationOptions, pageSize int32, pageToken []byte) ([]WorkflowExecution, []byte, error) {
+	var infos []*workflowpb.WorkflowExecutionInfo
+	var nextPageToken []byte
+	if options.UseScan {
+		response, err := wc.ScanWorkflow(ctx, &workflowservice.ScanWorkflowExecutionsRequest{
+			PageSize:      pageSize,
+			NextPageToken: pageToken,
+			Query:         options.Query,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		infos, nextPageToken = response.GetExecutions(), response.GetNextPageToken()
+	} else {
+		response, err := wc.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+			PageSize:      pageSize,
+			NextPageToken: pageToken,
+			Query:         options.Query,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		infos, nextPageToken = response.GetExecutions(), response.GetNextPageToken()
+	}
+
+	executions := make([]WorkflowExecution, 0, len(infos))
+	for _, info := range infos {
+		executions = append(executions, WorkflowExecution{
+			ID:    info.GetExecution().GetWorkflowId(),
+			RunID: info.GetExecution().GetRunId(),
+		})
+	}
+	return executions, nextPageToken, nil
+}
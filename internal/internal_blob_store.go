@@ -0,0 +1,341 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+// All code in this file is private to the package.
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/api/workflowservice/v1"
+
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/log"
+)
+
+const (
+	blobCollectorConcurrency = 2
+	blobCollectorQueueSize   = 1000
+	// blobCollectorMaxScheduled limits the number of executions waiting for the end of retention.
+	blobCollectorMaxScheduled = 100000
+	// blobCollectorRetentionSlack delays deletion past retention, as the server removes histories with a delay.
+	blobCollectorRetentionSlack = time.Hour
+)
+
+var payloadType = reflect.TypeOf((*commonpb.Payload)(nil))
+
+// isWorkflowClosedByRequest returns true if workflow task completion closes workflow execution.
+func isWorkflowClosedByRequest(completedRequest interface{}) bool {
+	request, ok := completedRequest.(*workflowservice.RespondWorkflowTaskCompletedRequest)
+	if !ok {
+		return false
+	}
+	for _, command := range request.GetCommands() {
+		switch command.GetCommandType() {
+		case enumspb.COMMAND_TYPE_COMPLETE_WORKFLOW_EXECUTION,
+			enumspb.COMMAND_TYPE_FAIL_WORKFLOW_EXECUTION,
+			enumspb.COMMAND_TYPE_CANCEL_WORKFLOW_EXECUTION,
+			enumspb.COMMAND_TYPE_CONTINUE_AS_NEW_WORKFLOW_EXECUTION:
+			return true
+		}
+	}
+	return false
+}
+
+// blobCollector deletes blobs of workflow executions closed by the worker once the namespace retention period
+// removed their history, so queries, replay and reset of closed executions can still read them. References are
+// read from the history in the background when the execution is closed and are kept in memory until retention
+// ends, they are lost when the worker stops. At most blobCollectorQueueSize executions wait for their history
+// to be read and at most blobCollectorMaxScheduled wait for retention, the blobs of other executions are not
+// deleted.
+type blobCollector struct {
+	service   workflowservice.WorkflowServiceClient
+	namespace string
+	store     converter.BlobStore
+	logger    log.Logger
+	stopC     <-chan struct{}
+	queue     chan *commonpb.WorkflowExecution
+	scheduled chan *scheduledBlobDeletion
+	startOnce sync.Once
+
+	retentionLock sync.Mutex
+	retention     time.Duration
+}
+
+type scheduledBlobDeletion struct {
+	execution  *commonpb.WorkflowExecution
+	references []string
+	deleteTime time.Time
+}
+
+func newBlobCollector(service workflowservice.WorkflowServiceClient, params workerExecutionParameters) *blobCollector {
+	return &blobCollector{
+		service:   service,
+		namespace: params.Namespace,
+		store:     params.BlobStore,
+		logger:    params.Logger,
+		stopC:     params.WorkerStopChannel,
+		queue:     make(chan *commonpb.WorkflowExecution, blobCollectorQueueSize),
+		scheduled: make(chan *scheduledBlobDeletion, blobCollectorMaxScheduled),
+	}
+}
+
+// collect schedules deletion of the blobs of the execution the worker requested to close. It never blocks.
+func (c *blobCollector) collect(execution *commonpb.WorkflowExecution) {
+	c.startOnce.Do(func() {
+		for i := 0; i < blobCollectorConcurrency; i++ {
+			go c.run()
+		}
+		go c.runDeletion()
+	})
+	select {
+	case c.queue <- execution:
+	default:
+		c.logger.Warn("Too many closed workflows waiting for blob collection, their blobs are not deleted.",
+			tagWorkflowID, execution.GetWorkflowId(),
+			tagRunID, execution.GetRunId())
+	}
+}
+
+func (c *blobCollector) run() {
+	for {
+		select {
+		case <-c.stopC:
+			return
+		case execution := <-c.queue:
+			c.schedule(execution)
+		}
+	}
+}
+
+func (c *blobCollector) schedule(execution *commonpb.WorkflowExecution) {
+	references, ok := getWorkflowBlobReferences(c.service, c.namespace, execution, c.logger)
+	if !ok || len(references) == 0 {
+		return
+	}
+	retention, err := c.getRetention()
+	if err != nil {
+		c.logger.Warn("Unable to get retention of namespace, blobs of closed workflow are not deleted.",
+			tagWorkflowID, execution.GetWorkflowId(),
+			tagRunID, execution.GetRunId(),
+			tagError, err)
+		return
+	}
+	if retention == 0 {
+		c.logger.Debug("Namespace has no retention, blobs of closed workflow are not deleted.",
+			tagWorkflowID, execution.GetWorkflowId(),
+			tagRunID, execution.GetRunId())
+		return
+	}
+	deletion := &scheduledBlobDeletion{
+		execution:  execution,
+		references: references,
+		deleteTime: time.Now().Add(retention + blobCollectorRetentionSlack),
+	}
+	select {
+	case c.scheduled <- deletion:
+	default:
+		c.logger.Warn("Too many closed workflows waiting for retention, their blobs are not deleted.",
+			tagWorkflowID, execution.GetWorkflowId(),
+			tagRunID, execution.GetRunId())
+	}
+}
+
+// runDeletion deletes scheduled blobs in order, the retention of the namespace is the same for all executions.
+func (c *blobCollector) runDeletion() {
+	for {
+		select {
+		case <-c.stopC:
+			return
+		case deletion := <-c.scheduled:
+			timer := time.NewTimer(time.Until(deletion.deleteTime))
+			select {
+			case <-c.stopC:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			deleteBlobs(c.store, deletion.execution, deletion.references, c.logger)
+		}
+	}
+}
+
+// getRetention returns the retention period of the namespace, zero if it has none.
+func (c *blobCollector) getRetention() (time.Duration, error) {
+	c.retentionLock.Lock()
+	defer c.retentionLock.Unlock()
+	if c.retention != 0 {
+		return c.retention, nil
+	}
+	ctx, cancel := newChannelContext(context.Background())
+	defer cancel()
+	response, err := c.service.DescribeNamespace(ctx, &workflowservice.DescribeNamespaceRequest{Name: c.namespace})
+	if err != nil {
+		return 0, err
+	}
+	c.retention = time.Duration(response.GetConfig().GetWorkflowExecutionRetentionPeriodInDays()) * 24 * time.Hour
+	return c.retention, nil
+}
+
+// getWorkflowBlobReferences returns references to blobs which can be deleted once history of the closed workflow
+// execution is removed. It returns false unless the history ends with a close event, as the server can reject the
+// close command. Blobs which can be shared with other executions are not returned. These are blobs of the close
+// event, of the start event of cron, retried, continued as new and child runs, and inputs and results of child
+// workflows and signals, which are copied to the history of the other execution.
+func getWorkflowBlobReferences(
+	service workflowservice.WorkflowServiceClient,
+	namespace string,
+	execution *commonpb.WorkflowExecution,
+	logger log.Logger,
+) ([]string, bool) {
+	var references []string
+	var lastEvent *historypb.HistoryEvent
+	var nextPageToken []byte
+	for {
+		ctx, cancel := newChannelContext(context.Background())
+		response, err := service.GetWorkflowExecutionHistory(ctx, &workflowservice.GetWorkflowExecutionHistoryRequest{
+			Namespace:     namespace,
+			Execution:     execution,
+			NextPageToken: nextPageToken,
+		})
+		cancel()
+		if err != nil {
+			logger.Warn("Unable to get history of closed workflow to collect its blobs.",
+				tagWorkflowID, execution.GetWorkflowId(),
+				tagRunID, execution.GetRunId(),
+				tagError, err)
+			return nil, false
+		}
+		for _, event := range response.GetHistory().GetEvents() {
+			lastEvent = event
+			if isBlobSharedByEvent(event) {
+				continue
+			}
+			references = append(references, converter.GetBlobReferences(collectPayloads(event))...)
+		}
+		nextPageToken = response.GetNextPageToken()
+		if len(nextPageToken) == 0 {
+			break
+		}
+	}
+	if !isWorkflowCloseEvent(lastEvent) {
+		logger.Debug("Workflow is not closed, its blobs are not deleted.",
+			tagWorkflowID, execution.GetWorkflowId(),
+			tagRunID, execution.GetRunId())
+		return nil, false
+	}
+	return references, true
+}
+
+func deleteBlobs(store converter.BlobStore, execution *commonpb.WorkflowExecution, references []string, logger log.Logger) {
+	for _, reference := range references {
+		if err := store.Delete(reference); err != nil {
+			logger.Warn("Unable to delete blob of closed workflow.",
+				tagWorkflowID, execution.GetWorkflowId(),
+				tagRunID, execution.GetRunId(),
+				tagBlobReference, reference,
+				tagError, err)
+		}
+	}
+}
+
+// isBlobSharedByEvent returns true if blobs referenced from the event must outlive the execution.
+func isBlobSharedByEvent(event *historypb.HistoryEvent) bool {
+	switch event.GetEventType() {
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED:
+		attributes := event.GetWorkflowExecutionStartedEventAttributes()
+		return attributes.GetCronSchedule() != "" ||
+			attributes.GetRetryPolicy() != nil ||
+			attributes.GetContinuedExecutionRunId() != "" ||
+			attributes.GetParentWorkflowExecution() != nil
+	case enumspb.EVENT_TYPE_START_CHILD_WORKFLOW_EXECUTION_INITIATED,
+		enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_COMPLETED,
+		enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_FAILED,
+		enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_CANCELED,
+		enumspb.EVENT_TYPE_SIGNAL_EXTERNAL_WORKFLOW_EXECUTION_INITIATED,
+		enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED:
+		return true
+	}
+	return isWorkflowCloseEvent(event)
+}
+
+func isWorkflowCloseEvent(event *historypb.HistoryEvent) bool {
+	switch event.GetEventType() {
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED,
+		enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED,
+		enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CANCELED,
+		enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CONTINUED_AS_NEW,
+		enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED,
+		enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TIMED_OUT:
+		return true
+	}
+	return false
+}
+
+// collectPayloads returns all payloads of a proto message, history events have too many attribute types
+// to enumerate them.
+func collectPayloads(message interface{}) []*commonpb.Payload {
+	var result []*commonpb.Payload
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			if v.IsNil() {
+				return
+			}
+			if v.Type() == payloadType {
+				result = append(result, v.Interface().(*commonpb.Payload))
+				return
+			}
+			walk(v.Elem())
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				if v.Type().Field(i).PkgPath == "" {
+					walk(v.Field(i))
+				}
+			}
+		case reflect.Slice:
+			if v.Type().Elem().Kind() == reflect.Uint8 {
+				return
+			}
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		case reflect.Map:
+			iter := v.MapRange()
+			for iter.Next() {
+				walk(iter.Value())
+			}
+		}
+	}
+	walk(reflect.ValueOf(message))
+	return result
+}
@@ -0,0 +1,244 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	namespacepb "go.temporal.io/api/namespace/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/api/workflowservicemock/v1"
+
+	"go.temporal.io/sdk/converter"
+	ilog "go.temporal.io/sdk/internal/log"
+)
+
+type testBlobStore struct {
+	lock  sync.Mutex
+	blobs map[string][]byte
+	gets  int
+	next  int
+}
+
+func newTestBlobStore() *testBlobStore {
+	return &testBlobStore{blobs: map[string][]byte{}}
+}
+
+func (s *testBlobStore) Put(data []byte) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.next++
+	reference := strconv.Itoa(s.next)
+	s.blobs[reference] = data
+	return reference, nil
+}
+
+func (s *testBlobStore) Get(reference string) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.gets++
+	data, ok := s.blobs[reference]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return data, nil
+}
+
+func (s *testBlobStore) Delete(reference string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.blobs, reference)
+	return nil
+}
+
+func (s *testBlobStore) values(dc converter.DataConverter) []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var result []string
+	for _, data := range s.blobs {
+		payload := &commonpb.Payload{}
+		if err := payload.Unmarshal(data); err != nil {
+			panic(err)
+		}
+		var value string
+		if err := dc.FromPayload(payload, &value); err != nil {
+			panic(err)
+		}
+		result = append(result, value)
+	}
+	return result
+}
+
+func blobWorkflow(ctx Context, input string) (string, error) {
+	var result string
+	if err := SetQueryHandler(ctx, "result", func() (string, error) { return result, nil }); err != nil {
+		return "", err
+	}
+	ctx = WithActivityOptions(ctx, ActivityOptions{StartToCloseTimeout: 10 * time.Second})
+	if err := ExecuteActivity(ctx, "blobActivity", input).Get(ctx, &result); err != nil {
+		return "", err
+	}
+	return "result " + result, nil
+}
+
+func blobActivity(_ context.Context, input string) (string, error) {
+	return "activity " + input, nil
+}
+
+func TestBlobStoreQueryClosedWorkflow(t *testing.T) {
+	store := newTestBlobStore()
+	dc := converter.NewCodecDataConverter(converter.GetDefaultDataConverter(), converter.NewBlobStoreCodec(store, converter.BlobStoreCodecOptions{MaxInlineBytes: 10}))
+	client, err := NewClient(ClientOptions{WorkflowService: NewInMemoryWorkflowService(), DataConverter: dc, Logger: ilog.NewNopLogger()})
+	require.NoError(t, err)
+	defer client.Close()
+
+	worker := NewAggregatedWorker(client.(*WorkflowClient), "blob-task-queue", WorkerOptions{BlobStore: store})
+	worker.RegisterWorkflowWithOptions(blobWorkflow, RegisterWorkflowOptions{Name: "blobWorkflow"})
+	worker.RegisterActivityWithOptions(blobActivity, RegisterActivityOptions{Name: "blobActivity"})
+	require.NoError(t, worker.Start())
+	defer worker.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	input := strings.Repeat("x", 20)
+	run, err := client.ExecuteWorkflow(ctx, StartWorkflowOptions{ID: "blob", TaskQueue: "blob-task-queue"}, "blobWorkflow", input)
+	require.NoError(t, err)
+	var result string
+	require.NoError(t, run.Get(ctx, &result))
+	require.Equal(t, "result activity "+input, result)
+
+	// The query replays the closed workflow from history, which references the offloaded activity result.
+	value, err := client.QueryWorkflow(ctx, "blob", run.GetRunID(), "result")
+	require.NoError(t, err)
+	require.NoError(t, value.Get(&result))
+	require.Equal(t, "activity "+input, result)
+	require.Contains(t, store.values(dc), "activity "+input)
+}
+
+func TestBlobCollector(t *testing.T) {
+	store := newTestBlobStore()
+	dc := converter.NewCodecDataConverter(converter.GetDefaultDataConverter(), converter.NewBlobStoreCodec(store, converter.BlobStoreCodecOptions{MaxInlineBytes: 10}))
+	payloads := func(value string) *commonpb.Payloads {
+		result, err := dc.ToPayloads(value)
+		require.NoError(t, err)
+		return result
+	}
+	events := []*historypb.HistoryEvent{
+		createTestEventWorkflowExecutionStarted(1, &historypb.WorkflowExecutionStartedEventAttributes{
+			Input:        payloads("cron workflow input"),
+			CronSchedule: "@every 1h",
+		}),
+		createTestEventActivityTaskScheduled(5, &historypb.ActivityTaskScheduledEventAttributes{
+			Input: payloads("activity input"),
+		}),
+		createTestEventStartChildWorkflowExecutionInitiated(6, &historypb.StartChildWorkflowExecutionInitiatedEventAttributes{
+			Input: payloads("child workflow input"),
+		}),
+		{
+			EventId:   7,
+			EventType: enumspb.EVENT_TYPE_SIGNAL_EXTERNAL_WORKFLOW_EXECUTION_INITIATED,
+			Attributes: &historypb.HistoryEvent_SignalExternalWorkflowExecutionInitiatedEventAttributes{SignalExternalWorkflowExecutionInitiatedEventAttributes: &historypb.SignalExternalWorkflowExecutionInitiatedEventAttributes{
+				Input: payloads("external signal input"),
+			}},
+		},
+		createTestEventWorkflowExecutionSignaledWithPayload(8, "signal", payloads("signal input")),
+		createTestEventWorkflowExecutionCompleted(12, &historypb.WorkflowExecutionCompletedEventAttributes{
+			Result: payloads("workflow result"),
+		}),
+	}
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	service := workflowservicemock.NewMockWorkflowServiceClient(mockCtrl)
+	var history []*historypb.HistoryEvent
+	service.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *workflowservice.GetWorkflowExecutionHistoryRequest, _ ...interface{}) (*workflowservice.GetWorkflowExecutionHistoryResponse, error) {
+			return &workflowservice.GetWorkflowExecutionHistoryResponse{History: &historypb.History{Events: history}}, nil
+		}).Times(2)
+	service.EXPECT().DescribeNamespace(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&workflowservice.DescribeNamespaceResponse{Config: &namespacepb.NamespaceConfig{WorkflowExecutionRetentionPeriodInDays: 1}}, nil)
+	stopC := make(chan struct{})
+	defer close(stopC)
+	collector := newBlobCollector(service, workerExecutionParameters{
+		Namespace:         "namespace",
+		BlobStore:         store,
+		Logger:            ilog.NewNopLogger(),
+		WorkerStopChannel: stopC,
+	})
+	execution := &commonpb.WorkflowExecution{WorkflowId: "wid", RunId: "rid"}
+
+	// The server rejected the close command, the workflow is still open.
+	history = events[:len(events)-1]
+	collector.schedule(execution)
+	require.Len(t, collector.scheduled, 0)
+
+	// Blobs are deleted once retention removed the history of the closed workflow.
+	history = events
+	collector.schedule(execution)
+	require.Len(t, collector.scheduled, 1)
+	deletion := <-collector.scheduled
+	require.WithinDuration(t, time.Now().Add(24*time.Hour+blobCollectorRetentionSlack), deletion.deleteTime, time.Minute)
+	require.Len(t, store.values(dc), 6)
+
+	deletion.deleteTime = time.Now()
+	collector.scheduled <- deletion
+	go collector.runDeletion()
+	require.Eventually(t, func() bool {
+		return len(store.values(dc)) == 5
+	}, 10*time.Second, 10*time.Millisecond)
+	require.ElementsMatch(t, []string{
+		"cron workflow input",
+		"child workflow input",
+		"external signal input",
+		"signal input",
+		"workflow result",
+	}, store.values(dc))
+}
+
+func TestBlobStoreCodecResolvesReferencesLazily(t *testing.T) {
+	store := newTestBlobStore()
+	dc := converter.NewCodecDataConverter(converter.GetDefaultDataConverter(), converter.NewBlobStoreCodec(store, converter.BlobStoreCodecOptions{MaxInlineBytes: 10}))
+	payloads, err := dc.ToPayloads(strings.Repeat("x", 20))
+	require.NoError(t, err)
+
+	value := newEncodedValue(payloads, dc)
+	require.True(t, value.HasValue())
+	require.Equal(t, 0, store.gets)
+
+	var s string
+	require.NoError(t, value.Get(&s))
+	require.Equal(t, strings.Repeat("x", 20), s)
+	require.Equal(t, 1, store.gets)
+}
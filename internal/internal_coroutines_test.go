@@ -27,6 +27,7 @@ package internal
 import (
 	"errors"
 	"fmt"
+	"os"
 	"runtime"
 	"strings"
 	"testing"
@@ -34,6 +35,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 
 	"go.temporal.io/sdk/converter"
 )
@@ -71,6 +73,62 @@ func TestDispatcher(t *testing.T) {
 	require.Equal(t, "bar", value)
 }
 
+func TestDeadlockDetection(t *testing.T) {
+	var stop atomic.Bool
+	d := createNewDispatcher(func(ctx Context) {
+		Go(ctx, func(ctx Context) {
+			for !stop.Load() {
+			}
+		})
+	})
+	d.(*dispatcherImpl).deadlockDetectionTimeout = 10 * time.Millisecond
+	defer d.Close()
+	defer stop.Store(true)
+	err := d.ExecuteUntilAllBlocked()
+	require.Error(t, err)
+	require.IsType(t, (*workflowPanicError)(nil), err)
+	require.Contains(t, err.Error(), `potential deadlock detected: workflow coroutine "2" didn't yield for over 10ms`)
+	require.Contains(t, err.(*workflowPanicError).StackTrace(), "coroutine 2 [deadlock detected]:")
+	require.Contains(t, err.(*workflowPanicError).StackTrace(), "TestDeadlockDetection")
+}
+
+func TestDeadlockDetectionDebugMode(t *testing.T) {
+	require.NoError(t, os.Setenv(debugModeEnvVar, "true"))
+	defer func() { _ = os.Unsetenv(debugModeEnvVar) }()
+
+	d := createNewDispatcher(func(ctx Context) {
+		time.Sleep(50 * time.Millisecond)
+	})
+	d.(*dispatcherImpl).deadlockDetectionTimeout = 10 * time.Millisecond
+	defer d.Close()
+	requireNoExecuteErr(t, d.ExecuteUntilAllBlocked())
+	require.True(t, d.IsDone())
+}
+
+func TestDeadlockDetectionTimerReused(t *testing.T) {
+	var c Channel
+	var received int
+	d := createNewDispatcher(func(ctx Context) {
+		c = NewChannel(ctx)
+		for {
+			c.Receive(ctx, nil)
+			received++
+		}
+	})
+	d.(*dispatcherImpl).deadlockDetectionTimeout = 10 * time.Millisecond
+	defer d.Close()
+	requireNoExecuteErr(t, d.ExecuteUntilAllBlocked())
+	timer := d.(*dispatcherImpl).deadlockTimer
+	require.NotNil(t, timer)
+
+	// The timer fires while the coroutines are blocked, which must not be reported as a deadlock by the next call.
+	time.Sleep(20 * time.Millisecond)
+	c.SendAsync(true)
+	requireNoExecuteErr(t, d.ExecuteUntilAllBlocked())
+	require.Equal(t, 1, received)
+	require.Same(t, timer, d.(*dispatcherImpl).deadlockTimer)
+}
+
 func TestNonBlockingChildren(t *testing.T) {
 	var history []string
 	d := createNewDispatcher(func(ctx Context) {
@@ -125,8 +125,12 @@ type (
 		metricsScope       tally.Scope
 		registry           *registry
 		dataConverter      converter.DataConverter
+		failureConverter   FailureConverter
+		payloadSizeChecker *payloadSizeChecker
 		contextPropagators []ContextPropagator
 		tracer             opentracing.Tracer
+
+		deadlockDetectionTimeout time.Duration // time a coroutine can run without yielding
 	}
 
 	localActivityTask struct {
@@ -170,24 +174,30 @@ func newWorkflowExecutionEventHandler(
 	scope tally.Scope,
 	registry *registry,
 	dataConverter converter.DataConverter,
+	failureConverter FailureConverter,
+	payloadSizeChecker *payloadSizeChecker,
 	contextPropagators []ContextPropagator,
 	tracer opentracing.Tracer,
+	deadlockDetectionTimeout time.Duration,
 ) workflowExecutionEventHandler {
 	context := &workflowEnvironmentImpl{
-		workflowInfo:          workflowInfo,
-		commandsHelper:        newCommandsHelper(),
-		sideEffectResult:      make(map[int64]*commonpb.Payloads),
-		mutableSideEffect:     make(map[string]*commonpb.Payloads),
-		changeVersions:        make(map[string]Version),
-		pendingLaTasks:        make(map[string]*localActivityTask),
-		unstartedLaTasks:      make(map[string]struct{}),
-		openSessions:          make(map[string]*SessionInfo),
-		completeHandler:       completeHandler,
-		enableLoggingInReplay: enableLoggingInReplay,
-		registry:              registry,
-		dataConverter:         dataConverter,
-		contextPropagators:    contextPropagators,
-		tracer:                tracer,
+		workflowInfo:             workflowInfo,
+		commandsHelper:           newCommandsHelper(),
+		sideEffectResult:         make(map[int64]*commonpb.Payloads),
+		mutableSideEffect:        make(map[string]*commonpb.Payloads),
+		changeVersions:           make(map[string]Version),
+		pendingLaTasks:           make(map[string]*localActivityTask),
+		unstartedLaTasks:         make(map[string]struct{}),
+		openSessions:             make(map[string]*SessionInfo),
+		completeHandler:          completeHandler,
+		enableLoggingInReplay:    enableLoggingInReplay,
+		registry:                 registry,
+		dataConverter:            dataConverter,
+		failureConverter:         failureConverter,
+		payloadSizeChecker:       payloadSizeChecker,
+		contextPropagators:       contextPropagators,
+		tracer:                   tracer,
+		deadlockDetectionTimeout: deadlockDetectionTimeout,
 	}
 	context.logger = ilog.NewReplayLogger(
 		ilog.With(logger,
@@ -407,6 +417,14 @@ func (wc *workflowEnvironmentImpl) GetDataConverter() converter.DataConverter {
 	return wc.dataConverter
 }
 
+func (wc *workflowEnvironmentImpl) GetDeadlockDetectionTimeout() time.Duration {
+	return wc.deadlockDetectionTimeout
+}
+
+func (wc *workflowEnvironmentImpl) GetPayloadSizeChecker() *payloadSizeChecker {
+	return wc.payloadSizeChecker
+}
+
 func (wc *workflowEnvironmentImpl) GetContextPropagators() []ContextPropagator {
 	return wc.contextPropagators
 }
@@ -915,6 +933,9 @@ func (weh *workflowExecutionEventHandlerImpl) ProcessQuery(queryType string, que
 				tagRunID, weh.workflowInfo.WorkflowExecution.RunID)
 			return nil, fmt.Errorf("query result size (%v) exceeds limit (%v)", result.Size(), queryResultSizeLimit)
 		}
+		if err := weh.payloadSizeChecker.check(result, payloadOperationQueryResult, queryType); err != nil {
+			return nil, err
+		}
 
 		return result, nil
 	}
@@ -972,7 +993,7 @@ func (weh *workflowExecutionEventHandlerImpl) handleActivityTaskFailed(event *hi
 		&commonpb.ActivityType{Name: activity.activityType.Name},
 		activityID,
 		attributes.GetRetryState(),
-		convertFailureToError(attributes.GetFailure(), weh.GetDataConverter()),
+		weh.failureConverter.FailureToError(attributes.GetFailure()),
 	)
 
 	activity.handle(nil, activityTaskErr)
@@ -988,7 +1009,7 @@ func (weh *workflowExecutionEventHandlerImpl) handleActivityTaskTimedOut(event *
 	}
 
 	attributes := event.GetActivityTaskTimedOutEventAttributes()
-	timeoutError := convertFailureToError(attributes.GetFailure(), weh.GetDataConverter())
+	timeoutError := weh.failureConverter.FailureToError(attributes.GetFailure())
 
 	activityTaskErr := NewActivityError(
 		attributes.GetScheduledEventId(),
@@ -1136,7 +1157,7 @@ func (weh *workflowExecutionEventHandlerImpl) handleLocalActivityMarker(details
 		if failure != nil {
 			lar.Attempt = lamd.Attempt
 			lar.Backoff = lamd.Backoff
-			lar.Err = convertFailureToError(failure, weh.GetDataConverter())
+			lar.Err = weh.failureConverter.FailureToError(failure)
 		} else {
 			var result *commonpb.Payloads
 			var ok bool
@@ -1167,6 +1188,12 @@ func (weh *workflowExecutionEventHandlerImpl) ProcessLocalActivityResult(lar *lo
 		ReplayTime:   weh.currentReplayTime.Add(time.Since(weh.currentLocalTime)),
 		Attempt:      lar.task.attempt,
 	}
+	if lar.err == nil {
+		// The result is recorded in the marker, a result which is too large fails the local activity.
+		if err := weh.payloadSizeChecker.check(lar.result, payloadOperationLocalActivityResult, lar.task.params.ActivityType); err != nil {
+			lar.err, lar.result = err, nil
+		}
+	}
 	if lar.err != nil {
 		lamd.Backoff = lar.backoff
 	} else {
@@ -1185,7 +1212,7 @@ func (weh *workflowExecutionEventHandlerImpl) ProcessLocalActivityResult(lar *lo
 		EventType: enumspb.EVENT_TYPE_MARKER_RECORDED,
 		Attributes: &historypb.HistoryEvent_MarkerRecordedEventAttributes{MarkerRecordedEventAttributes: &historypb.MarkerRecordedEventAttributes{
 			MarkerName: localActivityMarkerName,
-			Failure:    convertErrorToFailure(lar.err, weh.GetDataConverter()),
+			Failure:    weh.failureConverter.ErrorToFailure(lar.err),
 			Details:    details,
 		}},
 	}
@@ -1263,7 +1290,7 @@ func (weh *workflowExecutionEventHandlerImpl) handleChildWorkflowExecutionFailed
 		attributes.GetInitiatedEventId(),
 		attributes.GetStartedEventId(),
 		attributes.GetRetryState(),
-		convertFailureToError(attributes.GetFailure(), weh.GetDataConverter()),
+		weh.failureConverter.FailureToError(attributes.GetFailure()),
 	)
 	childWorkflow.handle(nil, childWorkflowExecutionError)
 	return nil
@@ -0,0 +1,285 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pborman/uuid"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+	namespacepb "go.temporal.io/api/namespace/v1"
+	querypb "go.temporal.io/api/query/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/workflowservice/v1"
+	"google.golang.org/grpc"
+)
+
+const (
+	inMemoryDefaultWorkflowTaskTimeout          = 10 * time.Second
+	inMemoryDefaultStickyScheduleToStartTimeout = 5 * time.Second
+	// inMemoryMaxTimeoutSeconds is used for activity timeouts of workflows without a run timeout.
+	inMemoryMaxTimeoutSeconds = 10 * 365 * 24 * 60 * 60
+)
+
+type (
+	// InMemoryWorkflowService is an in-process implementation of workflowservice.WorkflowServiceClient. It keeps
+	// workflow executions, their histories and task queues in memory, so a Client and workers created through
+	// ClientOptions.WorkflowService run end-to-end without a Temporal server or network access.
+	// It supports starting, signaling, querying, canceling and terminating workflows, long polling of workflow
+	// and activity task queues including sticky queues, timers, activity timeouts, heartbeats and retries,
+	// continue as new, markers and history long polling. Child workflows, external workflow signals and
+	// cancellations, workflow retries, cron schedules, visibility queries and archival are not supported. Workflow
+	// tasks with commands that aren't supported fail with a message naming the command, and requests that aren't
+	// supported return serviceerror.Unimplemented.
+	InMemoryWorkflowService struct {
+		lock       sync.Mutex
+		namespaces map[string]*namespacepb.NamespaceInfo
+		executions map[inMemoryExecutionKey]*inMemoryExecution
+		current    map[inMemoryWorkflowKey]*inMemoryExecution
+		taskQueues map[inMemoryWorkflowKey]*inMemoryTaskQueue
+		queries    map[string]*inMemoryQuery
+	}
+
+	// inMemoryWorkflowKey identifies a workflow ID, or a task queue name, within a namespace.
+	inMemoryWorkflowKey struct {
+		namespace string
+		id        string
+	}
+
+	inMemoryExecutionKey struct {
+		namespace  string
+		workflowID string
+		runID      string
+	}
+
+	inMemoryExecution struct {
+		namespace         string
+		execution         *commonpb.WorkflowExecution
+		firstRunID        string
+		workflowType      *commonpb.WorkflowType
+		taskQueue         string
+		requestID         string
+		startTime         time.Time
+		closeTime         time.Time
+		status            enumspb.WorkflowExecutionStatus
+		memo              *commonpb.Memo
+		searchAttributes  *commonpb.SearchAttributes
+		executionTimeout  int32
+		runTimeout        int32
+		taskTimeout       time.Duration
+		runTimer          *time.Timer
+		history           []*historypb.HistoryEvent
+		historyUpdated    chan struct{}
+		lastCompletionRes *commonpb.Payloads
+
+		// events which arrived while a workflow task was started, they are written after the task completes
+		bufferedEvents []func()
+		// scheduled or started workflow task, nil if there is none
+		workflowTask           *inMemoryWorkflowTask
+		workflowTaskAttempt    int64
+		previousStartedEventID int64
+		stickyTaskQueue        string
+		stickyTimeout          time.Duration
+
+		activities map[int64]*inMemoryActivity
+		timers     map[string]*inMemoryTimer
+	}
+
+	inMemoryWorkflowTask struct {
+		scheduledEventID int64
+		startedEventID   int64
+		attempt          int64
+		taskQueue        string
+		scheduledTime    time.Time
+		timer            *time.Timer
+	}
+
+	inMemoryActivity struct {
+		scheduledEventID     int64
+		attributes           *historypb.ActivityTaskScheduledEventAttributes
+		attempt              int32
+		scheduledTime        time.Time
+		attemptScheduledTime time.Time
+		startedTime          time.Time
+		started              bool
+		identity             string
+		heartbeatDetails     *commonpb.Payloads
+		lastHeartbeatTime    time.Time
+		lastFailure          *failurepb.Failure
+		cancelRequestedID    int64
+		scheduleToClose      *time.Timer
+		attemptTimers        []*time.Timer
+		heartbeatTimer       *time.Timer
+	}
+
+	inMemoryTimer struct {
+		startedEventID int64
+		timer          *time.Timer
+	}
+
+	inMemoryTaskQueue struct {
+		workflowTasks []inMemoryQueuedWorkflowTask
+		activityTasks []inMemoryQueuedActivityTask
+		// closed and replaced whenever a task is added to wake up the long polls
+		notify chan struct{}
+	}
+
+	// inMemoryQueuedWorkflowTask is either a workflow task or a query task. Entries are validated on dispatch, so
+	// tasks which were started, moved to another queue or completed in the meantime are skipped.
+	inMemoryQueuedWorkflowTask struct {
+		execution    *inMemoryExecution
+		workflowTask *inMemoryWorkflowTask
+		query        *inMemoryQuery
+	}
+
+	inMemoryQueuedActivityTask struct {
+		execution *inMemoryExecution
+		activity  *inMemoryActivity
+		attempt   int32
+	}
+
+	inMemoryQuery struct {
+		id         string
+		execution  *inMemoryExecution
+		query      *querypb.WorkflowQuery
+		dispatched bool
+		result     chan *workflowservice.RespondQueryTaskCompletedRequest
+	}
+
+	inMemoryTaskToken struct {
+		Namespace        string `json:"namespace"`
+		WorkflowID       string `json:"workflowId"`
+		RunID            string `json:"runId"`
+		ScheduledEventID int64  `json:"scheduledEventId,omitempty"`
+		Attempt          int64  `json:"attempt,omitempty"`
+		QueryID          string `json:"queryId,omitempty"`
+	}
+)
+
+var _ workflowservice.WorkflowServiceClient = (*InMemoryWorkflowService)(nil)
+
+// NewInMemoryWorkflowService creates an empty InMemoryWorkflowService. Every namespace is implicitly registered.
+func NewInMemoryWorkflowService() *InMemoryWorkflowService {
+	return &InMemoryWorkflowService{
+		namespaces: make(map[string]*namespacepb.NamespaceInfo),
+		executions: make(map[inMemoryExecutionKey]*inMemoryExecution),
+		current:    make(map[inMemoryWorkflowKey]*inMemoryExecution),
+		taskQueues: make(map[inMemoryWorkflowKey]*inMemoryTaskQueue),
+		queries:    make(map[string]*inMemoryQuery),
+	}
+}
+
+// RegisterNamespace implementation
+func (s *InMemoryWorkflowService) RegisterNamespace(_ context.Context, request *workflowservice.RegisterNamespaceRequest, _ ...grpc.CallOption) (*workflowservice.RegisterNamespaceResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.namespaces[request.GetName()]; ok {
+		return nil, serviceerror.NewNamespaceAlreadyExists(fmt.Sprintf("namespace %v already exists", request.GetName()))
+	}
+	s.namespaces[request.GetName()] = &namespacepb.NamespaceInfo{
+		Name:        request.GetName(),
+		State:       enumspb.NAMESPACE_STATE_REGISTERED,
+		Description: request.GetDescription(),
+		OwnerEmail:  request.GetOwnerEmail(),
+		Data:        request.GetData(),
+		Id:          uuid.New(),
+	}
+	return &workflowservice.RegisterNamespaceResponse{}, nil
+}
+
+// DescribeNamespace implementation
+func (s *InMemoryWorkflowService) DescribeNamespace(_ context.Context, request *workflowservice.DescribeNamespaceRequest, _ ...grpc.CallOption) (*workflowservice.DescribeNamespaceResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return &workflowservice.DescribeNamespaceResponse{
+		NamespaceInfo: s.getNamespace(request.GetName()),
+		Config:        &namespacepb.NamespaceConfig{},
+	}, nil
+}
+
+// ListNamespaces implementation
+func (s *InMemoryWorkflowService) ListNamespaces(_ context.Context, _ *workflowservice.ListNamespacesRequest, _ ...grpc.CallOption) (*workflowservice.ListNamespacesResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	response := &workflowservice.ListNamespacesResponse{}
+	for _, info := range s.namespaces {
+		response.Namespaces = append(response.Namespaces, &workflowservice.DescribeNamespaceResponse{
+			NamespaceInfo: info,
+			Config:        &namespacepb.NamespaceConfig{},
+		})
+	}
+	return response, nil
+}
+
+// UpdateNamespace implementation
+func (s *InMemoryWorkflowService) UpdateNamespace(_ context.Context, request *workflowservice.UpdateNamespaceRequest, _ ...grpc.CallOption) (*workflowservice.UpdateNamespaceResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	info := s.getNamespace(request.GetName())
+	if update := request.GetUpdateInfo(); update != nil {
+		info.Description = update.GetDescription()
+		info.OwnerEmail = update.GetOwnerEmail()
+		info.Data = update.GetData()
+	}
+	return &workflowservice.UpdateNamespaceResponse{
+		NamespaceInfo: info,
+		Config:        &namespacepb.NamespaceConfig{},
+	}, nil
+}
+
+// DeprecateNamespace implementation
+func (s *InMemoryWorkflowService) DeprecateNamespace(_ context.Context, request *workflowservice.DeprecateNamespaceRequest, _ ...grpc.CallOption) (*workflowservice.DeprecateNamespaceResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.getNamespace(request.GetName()).State = enumspb.NAMESPACE_STATE_DEPRECATED
+	return &workflowservice.DeprecateNamespaceResponse{}, nil
+}
+
+// GetClusterInfo implementation
+func (s *InMemoryWorkflowService) GetClusterInfo(_ context.Context, _ *workflowservice.GetClusterInfoRequest, _ ...grpc.CallOption) (*workflowservice.GetClusterInfoResponse, error) {
+	return &workflowservice.GetClusterInfoResponse{}, nil
+}
+
+func (s *InMemoryWorkflowService) getNamespace(name string) *namespacepb.NamespaceInfo {
+	info, ok := s.namespaces[name]
+	if !ok {
+		info = &namespacepb.NamespaceInfo{Name: name, State: enumspb.NAMESPACE_STATE_REGISTERED, Id: uuid.New()}
+		s.namespaces[name] = info
+	}
+	return info
+}
+
+func unixNanoOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
@@ -0,0 +1,432 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"math"
+	"time"
+
+	commandpb "go.temporal.io/api/command/v1"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/api/serviceerror"
+	taskqueuepb "go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"google.golang.org/grpc"
+
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/internal/common/backoff"
+)
+
+// RecordActivityTaskHeartbeat implementation
+func (s *InMemoryWorkflowService) RecordActivityTaskHeartbeat(_ context.Context, request *workflowservice.RecordActivityTaskHeartbeatRequest, _ ...grpc.CallOption) (*workflowservice.RecordActivityTaskHeartbeatResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, activity, err := s.getStartedActivity(request.GetTaskToken())
+	if err != nil {
+		return nil, err
+	}
+	return s.recordActivityHeartbeat(e, activity, request.GetDetails()), nil
+}
+
+// RecordActivityTaskHeartbeatById implementation
+func (s *InMemoryWorkflowService) RecordActivityTaskHeartbeatById(_ context.Context, request *workflowservice.RecordActivityTaskHeartbeatByIdRequest, _ ...grpc.CallOption) (*workflowservice.RecordActivityTaskHeartbeatByIdResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, activity, err := s.getStartedActivityByID(request.GetNamespace(), request.GetWorkflowId(), request.GetRunId(), request.GetActivityId())
+	if err != nil {
+		return nil, err
+	}
+	response := s.recordActivityHeartbeat(e, activity, request.GetDetails())
+	return &workflowservice.RecordActivityTaskHeartbeatByIdResponse{CancelRequested: response.GetCancelRequested()}, nil
+}
+
+// RespondActivityTaskCompleted implementation
+func (s *InMemoryWorkflowService) RespondActivityTaskCompleted(_ context.Context, request *workflowservice.RespondActivityTaskCompletedRequest, _ ...grpc.CallOption) (*workflowservice.RespondActivityTaskCompletedResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, activity, err := s.getStartedActivity(request.GetTaskToken())
+	if err != nil {
+		return nil, err
+	}
+	s.completeActivity(e, activity, request.GetResult(), request.GetIdentity())
+	return &workflowservice.RespondActivityTaskCompletedResponse{}, nil
+}
+
+// RespondActivityTaskCompletedById implementation
+func (s *InMemoryWorkflowService) RespondActivityTaskCompletedById(_ context.Context, request *workflowservice.RespondActivityTaskCompletedByIdRequest, _ ...grpc.CallOption) (*workflowservice.RespondActivityTaskCompletedByIdResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, activity, err := s.getStartedActivityByID(request.GetNamespace(), request.GetWorkflowId(), request.GetRunId(), request.GetActivityId())
+	if err != nil {
+		return nil, err
+	}
+	s.completeActivity(e, activity, request.GetResult(), request.GetIdentity())
+	return &workflowservice.RespondActivityTaskCompletedByIdResponse{}, nil
+}
+
+// RespondActivityTaskFailed implementation
+func (s *InMemoryWorkflowService) RespondActivityTaskFailed(_ context.Context, request *workflowservice.RespondActivityTaskFailedRequest, _ ...grpc.CallOption) (*workflowservice.RespondActivityTaskFailedResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, activity, err := s.getStartedActivity(request.GetTaskToken())
+	if err != nil {
+		return nil, err
+	}
+	s.failActivity(e, activity, request.GetFailure(), request.GetIdentity())
+	return &workflowservice.RespondActivityTaskFailedResponse{}, nil
+}
+
+// RespondActivityTaskFailedById implementation
+func (s *InMemoryWorkflowService) RespondActivityTaskFailedById(_ context.Context, request *workflowservice.RespondActivityTaskFailedByIdRequest, _ ...grpc.CallOption) (*workflowservice.RespondActivityTaskFailedByIdResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, activity, err := s.getStartedActivityByID(request.GetNamespace(), request.GetWorkflowId(), request.GetRunId(), request.GetActivityId())
+	if err != nil {
+		return nil, err
+	}
+	s.failActivity(e, activity, request.GetFailure(), request.GetIdentity())
+	return &workflowservice.RespondActivityTaskFailedByIdResponse{}, nil
+}
+
+// RespondActivityTaskCanceled implementation
+func (s *InMemoryWorkflowService) RespondActivityTaskCanceled(_ context.Context, request *workflowservice.RespondActivityTaskCanceledRequest, _ ...grpc.CallOption) (*workflowservice.RespondActivityTaskCanceledResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, activity, err := s.getStartedActivity(request.GetTaskToken())
+	if err != nil {
+		return nil, err
+	}
+	s.cancelActivity(e, activity, request.GetDetails(), request.GetIdentity())
+	return &workflowservice.RespondActivityTaskCanceledResponse{}, nil
+}
+
+// RespondActivityTaskCanceledById implementation
+func (s *InMemoryWorkflowService) RespondActivityTaskCanceledById(_ context.Context, request *workflowservice.RespondActivityTaskCanceledByIdRequest, _ ...grpc.CallOption) (*workflowservice.RespondActivityTaskCanceledByIdResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, activity, err := s.getStartedActivityByID(request.GetNamespace(), request.GetWorkflowId(), request.GetRunId(), request.GetActivityId())
+	if err != nil {
+		return nil, err
+	}
+	s.cancelActivity(e, activity, request.GetDetails(), request.GetIdentity())
+	return &workflowservice.RespondActivityTaskCanceledByIdResponse{}, nil
+}
+
+func (s *InMemoryWorkflowService) getStartedActivity(taskToken []byte) (*inMemoryExecution, *inMemoryActivity, error) {
+	token, err := decodeInMemoryTaskToken(taskToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	e := s.executions[inMemoryExecutionKey{namespace: token.Namespace, workflowID: token.WorkflowID, runID: token.RunID}]
+	if e == nil || e.isClosed() {
+		return nil, nil, serviceerror.NewNotFound("workflow execution already completed")
+	}
+	activity := e.activities[token.ScheduledEventID]
+	if activity == nil || !activity.started || int64(activity.attempt) != token.Attempt {
+		return nil, nil, serviceerror.NewNotFound("activity task not found")
+	}
+	return e, activity, nil
+}
+
+func (s *InMemoryWorkflowService) getStartedActivityByID(namespace, workflowID, runID, activityID string) (*inMemoryExecution, *inMemoryActivity, error) {
+	e, err := s.getRunningExecution(namespace, &commonpb.WorkflowExecution{WorkflowId: workflowID, RunId: runID})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, activity := range e.activities {
+		if activity.attributes.GetActivityId() == activityID && activity.started {
+			return e, activity, nil
+		}
+	}
+	return nil, nil, serviceerror.NewNotFound("activity task not found")
+}
+
+func (s *InMemoryWorkflowService) scheduleActivity(e *inMemoryExecution, completedEventID int64, command *commandpb.ScheduleActivityTaskCommandAttributes) {
+	attributes := &historypb.ActivityTaskScheduledEventAttributes{
+		ActivityId:                    command.GetActivityId(),
+		ActivityType:                  command.GetActivityType(),
+		Namespace:                     e.namespace,
+		TaskQueue:                     command.GetTaskQueue(),
+		Header:                        command.GetHeader(),
+		Input:                         command.GetInput(),
+		ScheduleToCloseTimeoutSeconds: command.GetScheduleToCloseTimeoutSeconds(),
+		ScheduleToStartTimeoutSeconds: command.GetScheduleToStartTimeoutSeconds(),
+		StartToCloseTimeoutSeconds:    command.GetStartToCloseTimeoutSeconds(),
+		HeartbeatTimeoutSeconds:       command.GetHeartbeatTimeoutSeconds(),
+		WorkflowTaskCompletedEventId:  completedEventID,
+		RetryPolicy:                   command.GetRetryPolicy(),
+	}
+	if attributes.TaskQueue.GetName() == "" {
+		attributes.TaskQueue = &taskqueuepb.TaskQueue{Name: e.taskQueue, Kind: enumspb.TASK_QUEUE_KIND_NORMAL}
+	}
+	if attributes.ScheduleToCloseTimeoutSeconds == 0 {
+		// workers derive the activity deadline from it, so it is always set like the server does
+		attributes.ScheduleToCloseTimeoutSeconds = e.runTimeout
+		if attributes.ScheduleToCloseTimeoutSeconds == 0 {
+			attributes.ScheduleToCloseTimeoutSeconds = inMemoryMaxTimeoutSeconds
+		}
+	}
+	if attributes.StartToCloseTimeoutSeconds == 0 {
+		attributes.StartToCloseTimeoutSeconds = attributes.ScheduleToCloseTimeoutSeconds
+	}
+	if attributes.ScheduleToStartTimeoutSeconds == 0 {
+		attributes.ScheduleToStartTimeoutSeconds = attributes.ScheduleToCloseTimeoutSeconds
+	}
+	if policy := attributes.RetryPolicy; policy != nil {
+		// fill in the defaults the same way the server does
+		attributes.RetryPolicy = &commonpb.RetryPolicy{
+			InitialIntervalInSeconds: policy.GetInitialIntervalInSeconds(),
+			BackoffCoefficient:       policy.GetBackoffCoefficient(),
+			MaximumIntervalInSeconds: policy.GetMaximumIntervalInSeconds(),
+			MaximumAttempts:          policy.GetMaximumAttempts(),
+			NonRetryableErrorTypes:   policy.GetNonRetryableErrorTypes(),
+		}
+		if attributes.RetryPolicy.InitialIntervalInSeconds <= 0 {
+			attributes.RetryPolicy.InitialIntervalInSeconds = 1
+		}
+		if attributes.RetryPolicy.BackoffCoefficient < 1 {
+			attributes.RetryPolicy.BackoffCoefficient = backoff.DefaultBackoffCoefficient
+		}
+		if attributes.RetryPolicy.MaximumIntervalInSeconds <= 0 {
+			attributes.RetryPolicy.MaximumIntervalInSeconds = 100 * attributes.RetryPolicy.InitialIntervalInSeconds
+		}
+	}
+
+	event := e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED, Attributes: &historypb.HistoryEvent_ActivityTaskScheduledEventAttributes{ActivityTaskScheduledEventAttributes: attributes}})
+	activity := &inMemoryActivity{
+		scheduledEventID: event.GetEventId(),
+		attributes:       attributes,
+		attempt:          1,
+		scheduledTime:    time.Now(),
+	}
+	e.activities[activity.scheduledEventID] = activity
+	if timeout := attributes.GetScheduleToCloseTimeoutSeconds(); timeout > 0 {
+		activity.scheduleToClose = time.AfterFunc(time.Duration(timeout)*time.Second, func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			if e.activities[activity.scheduledEventID] == activity {
+				s.timeoutActivity(e, activity, enumspb.TIMEOUT_TYPE_SCHEDULE_TO_CLOSE)
+			}
+		})
+	}
+	s.scheduleActivityAttempt(e, activity)
+}
+
+func (s *InMemoryWorkflowService) scheduleActivityAttempt(e *inMemoryExecution, activity *inMemoryActivity) {
+	activity.started = false
+	activity.attemptScheduledTime = time.Now()
+	s.enqueueActivityTask(e.namespace, activity.attributes.GetTaskQueue().GetName(), inMemoryQueuedActivityTask{
+		execution: e,
+		activity:  activity,
+		attempt:   activity.attempt,
+	})
+	if timeout := activity.attributes.GetScheduleToStartTimeoutSeconds(); timeout > 0 {
+		attempt := activity.attempt
+		activity.attemptTimers = append(activity.attemptTimers, time.AfterFunc(time.Duration(timeout)*time.Second, func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			if e.activities[activity.scheduledEventID] == activity && activity.attempt == attempt && !activity.started {
+				s.timeoutActivity(e, activity, enumspb.TIMEOUT_TYPE_SCHEDULE_TO_START)
+			}
+		}))
+	}
+}
+
+func (s *InMemoryWorkflowService) startHeartbeatTimer(e *inMemoryExecution, activity *inMemoryActivity) {
+	if activity.heartbeatTimer != nil {
+		activity.heartbeatTimer.Stop()
+		activity.heartbeatTimer = nil
+	}
+	timeout := activity.attributes.GetHeartbeatTimeoutSeconds()
+	if timeout <= 0 {
+		return
+	}
+	attempt := activity.attempt
+	activity.heartbeatTimer = time.AfterFunc(time.Duration(timeout)*time.Second, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if e.activities[activity.scheduledEventID] == activity && activity.attempt == attempt && activity.started {
+			s.timeoutActivity(e, activity, enumspb.TIMEOUT_TYPE_HEARTBEAT)
+		}
+	})
+}
+
+func (s *InMemoryWorkflowService) recordActivityHeartbeat(e *inMemoryExecution, activity *inMemoryActivity, details *commonpb.Payloads) *workflowservice.RecordActivityTaskHeartbeatResponse {
+	activity.heartbeatDetails = details
+	activity.lastHeartbeatTime = time.Now()
+	s.startHeartbeatTimer(e, activity)
+	return &workflowservice.RecordActivityTaskHeartbeatResponse{CancelRequested: activity.cancelRequestedID != 0}
+}
+
+func (s *InMemoryWorkflowService) removeActivity(e *inMemoryExecution, activity *inMemoryActivity) {
+	activity.stopAttemptTimers()
+	if activity.scheduleToClose != nil {
+		activity.scheduleToClose.Stop()
+	}
+	delete(e.activities, activity.scheduledEventID)
+}
+
+func (s *InMemoryWorkflowService) completeActivity(e *inMemoryExecution, activity *inMemoryActivity, result *commonpb.Payloads, identity string) {
+	s.removeActivity(e, activity)
+	s.addExternalEvents(e, func() {
+		startedEvent := e.addActivityStartedEvent(activity)
+		e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_COMPLETED, Attributes: &historypb.HistoryEvent_ActivityTaskCompletedEventAttributes{ActivityTaskCompletedEventAttributes: &historypb.ActivityTaskCompletedEventAttributes{
+			Result:           result,
+			ScheduledEventId: activity.scheduledEventID,
+			StartedEventId:   startedEvent.GetEventId(),
+			Identity:         identity,
+		}}})
+	})
+}
+
+func (s *InMemoryWorkflowService) failActivity(e *inMemoryExecution, activity *inMemoryActivity, failure *failurepb.Failure, identity string) {
+	retried, retryState := s.retryActivity(e, activity, failure)
+	if retried {
+		return
+	}
+	s.removeActivity(e, activity)
+	s.addExternalEvents(e, func() {
+		startedEvent := e.addActivityStartedEvent(activity)
+		e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_FAILED, Attributes: &historypb.HistoryEvent_ActivityTaskFailedEventAttributes{ActivityTaskFailedEventAttributes: &historypb.ActivityTaskFailedEventAttributes{
+			Failure:          failure,
+			ScheduledEventId: activity.scheduledEventID,
+			StartedEventId:   startedEvent.GetEventId(),
+			Identity:         identity,
+			RetryState:       retryState,
+		}}})
+	})
+}
+
+func (s *InMemoryWorkflowService) cancelActivity(e *inMemoryExecution, activity *inMemoryActivity, details *commonpb.Payloads, identity string) {
+	s.removeActivity(e, activity)
+	s.addExternalEvents(e, func() {
+		startedEvent := e.addActivityStartedEvent(activity)
+		e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_CANCELED, Attributes: &historypb.HistoryEvent_ActivityTaskCanceledEventAttributes{ActivityTaskCanceledEventAttributes: &historypb.ActivityTaskCanceledEventAttributes{
+			Details:                      details,
+			LatestCancelRequestedEventId: activity.cancelRequestedID,
+			ScheduledEventId:             activity.scheduledEventID,
+			StartedEventId:               startedEvent.GetEventId(),
+			Identity:                     identity,
+		}}})
+	})
+}
+
+func (s *InMemoryWorkflowService) timeoutActivity(e *inMemoryExecution, activity *inMemoryActivity, timeoutType enumspb.TimeoutType) {
+	failure := &failurepb.Failure{
+		Message: "activity timeout",
+		FailureInfo: &failurepb.Failure_TimeoutFailureInfo{TimeoutFailureInfo: &failurepb.TimeoutFailureInfo{
+			TimeoutType:          timeoutType,
+			LastHeartbeatDetails: activity.heartbeatDetails,
+		}},
+	}
+	retryState := enumspb.RETRY_STATE_TIMEOUT
+	if timeoutType == enumspb.TIMEOUT_TYPE_START_TO_CLOSE || timeoutType == enumspb.TIMEOUT_TYPE_HEARTBEAT {
+		var retried bool
+		if retried, retryState = s.retryActivity(e, activity, failure); retried {
+			return
+		}
+	}
+	started := activity.started
+	s.removeActivity(e, activity)
+	s.addExternalEvents(e, func() {
+		var startedEventID int64
+		if started {
+			startedEventID = e.addActivityStartedEvent(activity).GetEventId()
+		}
+		e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_TIMED_OUT, Attributes: &historypb.HistoryEvent_ActivityTaskTimedOutEventAttributes{ActivityTaskTimedOutEventAttributes: &historypb.ActivityTaskTimedOutEventAttributes{
+			Failure:          failure,
+			ScheduledEventId: activity.scheduledEventID,
+			StartedEventId:   startedEventID,
+			RetryState:       retryState,
+		}}})
+	})
+}
+
+// retryActivity schedules the next attempt of a failed activity after the backoff of its retry policy. It returns
+// false and the retry state to report if the activity is not retried.
+func (s *InMemoryWorkflowService) retryActivity(e *inMemoryExecution, activity *inMemoryActivity, failure *failurepb.Failure) (bool, enumspb.RetryState) {
+	if activity.cancelRequestedID != 0 {
+		return false, enumspb.RETRY_STATE_CANCEL_REQUESTED
+	}
+	var expireTime time.Time
+	if timeout := activity.attributes.GetScheduleToCloseTimeoutSeconds(); timeout > 0 {
+		expireTime = activity.scheduledTime.Add(time.Duration(timeout) * time.Second)
+	}
+	err := convertFailureToError(failure, converter.GetDefaultDataConverter())
+	backoffInterval, retryState := getInMemoryRetryBackoff(activity.attributes.GetRetryPolicy(), activity.attempt, err, expireTime)
+	if retryState != enumspb.RETRY_STATE_IN_PROGRESS {
+		return false, retryState
+	}
+
+	activity.stopAttemptTimers()
+	activity.started = false
+	activity.lastFailure = failure
+	activity.attempt++
+	attempt := activity.attempt
+	activity.attemptTimers = append(activity.attemptTimers, time.AfterFunc(backoffInterval, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if e.activities[activity.scheduledEventID] == activity && activity.attempt == attempt {
+			s.scheduleActivityAttempt(e, activity)
+		}
+	}))
+	return true, retryState
+}
+
+// getInMemoryRetryBackoff returns the backoff before the attempt following the given one, attempts start at 1.
+func getInMemoryRetryBackoff(policy *commonpb.RetryPolicy, attempt int32, err error, expireTime time.Time) (time.Duration, enumspb.RetryState) {
+	if policy == nil {
+		return noRetryBackoff, enumspb.RETRY_STATE_RETRY_POLICY_NOT_SET
+	}
+	if !IsRetryable(err, policy.GetNonRetryableErrorTypes()) {
+		return noRetryBackoff, enumspb.RETRY_STATE_NON_RETRYABLE_FAILURE
+	}
+	if policy.GetMaximumAttempts() > 0 && attempt >= policy.GetMaximumAttempts() {
+		return noRetryBackoff, enumspb.RETRY_STATE_MAXIMUM_ATTEMPTS_REACHED
+	}
+	interval := time.Duration(float64(policy.GetInitialIntervalInSeconds()) * math.Pow(policy.GetBackoffCoefficient(), float64(attempt-1)) * float64(time.Second))
+	if maxInterval := time.Duration(policy.GetMaximumIntervalInSeconds()) * time.Second; maxInterval > 0 && (interval > maxInterval || interval <= 0) {
+		interval = maxInterval
+	}
+	if !expireTime.IsZero() && time.Now().Add(interval).After(expireTime) {
+		return noRetryBackoff, enumspb.RETRY_STATE_TIMEOUT
+	}
+	return interval, enumspb.RETRY_STATE_IN_PROGRESS
+}
+
+func (a *inMemoryActivity) stopAttemptTimers() {
+	for _, timer := range a.attemptTimers {
+		timer.Stop()
+	}
+	a.attemptTimers = nil
+	if a.heartbeatTimer != nil {
+		a.heartbeatTimer.Stop()
+		a.heartbeatTimer = nil
+	}
+}
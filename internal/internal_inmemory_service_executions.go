@@ -0,0 +1,658 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pborman/uuid"
+	commandpb "go.temporal.io/api/command/v1"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/api/serviceerror"
+	taskqueuepb "go.temporal.io/api/taskqueue/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"google.golang.org/grpc"
+)
+
+// StartWorkflowExecution implementation
+func (s *InMemoryWorkflowService) StartWorkflowExecution(_ context.Context, request *workflowservice.StartWorkflowExecutionRequest, _ ...grpc.CallOption) (*workflowservice.StartWorkflowExecutionResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, err := s.startExecution(request)
+	if err != nil {
+		return nil, err
+	}
+	s.scheduleWorkflowTask(e)
+	return &workflowservice.StartWorkflowExecutionResponse{RunId: e.execution.GetRunId()}, nil
+}
+
+// SignalWithStartWorkflowExecution implementation
+func (s *InMemoryWorkflowService) SignalWithStartWorkflowExecution(_ context.Context, request *workflowservice.SignalWithStartWorkflowExecutionRequest, _ ...grpc.CallOption) (*workflowservice.SignalWithStartWorkflowExecutionResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, ok := s.current[inMemoryWorkflowKey{namespace: request.GetNamespace(), id: request.GetWorkflowId()}]
+	if !ok || e.isClosed() {
+		var err error
+		e, err = s.startExecution(&workflowservice.StartWorkflowExecutionRequest{
+			Namespace:                       request.GetNamespace(),
+			WorkflowId:                      request.GetWorkflowId(),
+			WorkflowType:                    request.GetWorkflowType(),
+			TaskQueue:                       request.GetTaskQueue(),
+			Input:                           request.GetInput(),
+			WorkflowExecutionTimeoutSeconds: request.GetWorkflowExecutionTimeoutSeconds(),
+			WorkflowRunTimeoutSeconds:       request.GetWorkflowRunTimeoutSeconds(),
+			WorkflowTaskTimeoutSeconds:      request.GetWorkflowTaskTimeoutSeconds(),
+			Identity:                        request.GetIdentity(),
+			RequestId:                       request.GetRequestId(),
+			WorkflowIdReusePolicy:           request.GetWorkflowIdReusePolicy(),
+			RetryPolicy:                     request.GetRetryPolicy(),
+			CronSchedule:                    request.GetCronSchedule(),
+			Memo:                            request.GetMemo(),
+			SearchAttributes:                request.GetSearchAttributes(),
+			Header:                          request.GetHeader(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.addExternalEvents(e, func() {
+		e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED, Attributes: &historypb.HistoryEvent_WorkflowExecutionSignaledEventAttributes{WorkflowExecutionSignaledEventAttributes: &historypb.WorkflowExecutionSignaledEventAttributes{
+			SignalName: request.GetSignalName(),
+			Input:      request.GetSignalInput(),
+			Identity:   request.GetIdentity(),
+		}}})
+	})
+	return &workflowservice.SignalWithStartWorkflowExecutionResponse{RunId: e.execution.GetRunId()}, nil
+}
+
+// GetWorkflowExecutionHistory implementation. Long polls wait for new events, or for the close event when
+// HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT is requested, until the context is done.
+func (s *InMemoryWorkflowService) GetWorkflowExecutionHistory(ctx context.Context, request *workflowservice.GetWorkflowExecutionHistoryRequest, _ ...grpc.CallOption) (*workflowservice.GetWorkflowExecutionHistoryResponse, error) {
+	var firstEventID int64 = 1
+	if len(request.GetNextPageToken()) != 0 {
+		if err := json.Unmarshal(request.GetNextPageToken(), &firstEventID); err != nil {
+			return nil, serviceerror.NewInvalidArgument("invalid next page token")
+		}
+	}
+
+	s.lock.Lock()
+	e, err := s.getExecution(request.GetNamespace(), request.GetExecution())
+	if err != nil {
+		s.lock.Unlock()
+		return nil, err
+	}
+	closeEventOnly := request.GetHistoryEventFilterType() == enumspb.HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT
+	for request.GetWaitNewEvent() && !e.isClosed() && (closeEventOnly || int64(len(e.history)) < firstEventID) {
+		updated := e.historyUpdated
+		s.lock.Unlock()
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		s.lock.Lock()
+	}
+	defer s.lock.Unlock()
+
+	if closeEventOnly {
+		history := &historypb.History{}
+		if e.isClosed() {
+			history.Events = e.history[len(e.history)-1:]
+		}
+		return &workflowservice.GetWorkflowExecutionHistoryResponse{History: history}, nil
+	}
+
+	events := e.history[firstEventID-1:]
+	if pageSize := int(request.GetMaximumPageSize()); pageSize > 0 && len(events) > pageSize {
+		events = events[:pageSize]
+	}
+	response := &workflowservice.GetWorkflowExecutionHistoryResponse{
+		History: &historypb.History{Events: append([]*historypb.HistoryEvent(nil), events...)},
+	}
+	nextEventID := firstEventID + int64(len(events))
+	if nextEventID <= int64(len(e.history)) || (request.GetWaitNewEvent() && !e.isClosed()) {
+		response.NextPageToken, _ = json.Marshal(nextEventID)
+	}
+	return response, nil
+}
+
+// RequestCancelWorkflowExecution implementation
+func (s *InMemoryWorkflowService) RequestCancelWorkflowExecution(_ context.Context, request *workflowservice.RequestCancelWorkflowExecutionRequest, _ ...grpc.CallOption) (*workflowservice.RequestCancelWorkflowExecutionResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, err := s.getRunningExecution(request.GetNamespace(), request.GetWorkflowExecution())
+	if err != nil {
+		return nil, err
+	}
+	s.addExternalEvents(e, func() {
+		e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CANCEL_REQUESTED, Attributes: &historypb.HistoryEvent_WorkflowExecutionCancelRequestedEventAttributes{WorkflowExecutionCancelRequestedEventAttributes: &historypb.WorkflowExecutionCancelRequestedEventAttributes{
+			Identity: request.GetIdentity(),
+		}}})
+	})
+	return &workflowservice.RequestCancelWorkflowExecutionResponse{}, nil
+}
+
+// SignalWorkflowExecution implementation
+func (s *InMemoryWorkflowService) SignalWorkflowExecution(_ context.Context, request *workflowservice.SignalWorkflowExecutionRequest, _ ...grpc.CallOption) (*workflowservice.SignalWorkflowExecutionResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, err := s.getRunningExecution(request.GetNamespace(), request.GetWorkflowExecution())
+	if err != nil {
+		return nil, err
+	}
+	s.addExternalEvents(e, func() {
+		e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED, Attributes: &historypb.HistoryEvent_WorkflowExecutionSignaledEventAttributes{WorkflowExecutionSignaledEventAttributes: &historypb.WorkflowExecutionSignaledEventAttributes{
+			SignalName: request.GetSignalName(),
+			Input:      request.GetInput(),
+			Identity:   request.GetIdentity(),
+		}}})
+	})
+	return &workflowservice.SignalWorkflowExecutionResponse{}, nil
+}
+
+// TerminateWorkflowExecution implementation
+func (s *InMemoryWorkflowService) TerminateWorkflowExecution(_ context.Context, request *workflowservice.TerminateWorkflowExecutionRequest, _ ...grpc.CallOption) (*workflowservice.TerminateWorkflowExecutionResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, err := s.getRunningExecution(request.GetNamespace(), request.GetWorkflowExecution())
+	if err != nil {
+		return nil, err
+	}
+	e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED, Attributes: &historypb.HistoryEvent_WorkflowExecutionTerminatedEventAttributes{WorkflowExecutionTerminatedEventAttributes: &historypb.WorkflowExecutionTerminatedEventAttributes{
+		Reason:   request.GetReason(),
+		Details:  request.GetDetails(),
+		Identity: request.GetIdentity(),
+	}}})
+	s.closeExecution(e, enumspb.WORKFLOW_EXECUTION_STATUS_TERMINATED)
+	return &workflowservice.TerminateWorkflowExecutionResponse{}, nil
+}
+
+// ResetWorkflowExecution is not supported by InMemoryWorkflowService.
+func (s *InMemoryWorkflowService) ResetWorkflowExecution(_ context.Context, _ *workflowservice.ResetWorkflowExecutionRequest, _ ...grpc.CallOption) (*workflowservice.ResetWorkflowExecutionResponse, error) {
+	return nil, serviceerror.NewUnimplemented("ResetWorkflowExecution is not supported by InMemoryWorkflowService")
+}
+
+// DescribeWorkflowExecution implementation
+func (s *InMemoryWorkflowService) DescribeWorkflowExecution(_ context.Context, request *workflowservice.DescribeWorkflowExecutionRequest, _ ...grpc.CallOption) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, err := s.getExecution(request.GetNamespace(), request.GetExecution())
+	if err != nil {
+		return nil, err
+	}
+	response := &workflowservice.DescribeWorkflowExecutionResponse{
+		ExecutionConfig: &workflowpb.WorkflowExecutionConfig{
+			TaskQueue:                         &taskqueuepb.TaskQueue{Name: e.taskQueue, Kind: enumspb.TASK_QUEUE_KIND_NORMAL},
+			WorkflowExecutionTimeoutSeconds:   e.executionTimeout,
+			WorkflowRunTimeoutSeconds:         e.runTimeout,
+			DefaultWorkflowTaskTimeoutSeconds: int32(e.taskTimeout / time.Second),
+		},
+		WorkflowExecutionInfo: e.info(),
+	}
+	for _, activity := range e.activities {
+		state := enumspb.PENDING_ACTIVITY_STATE_SCHEDULED
+		if activity.cancelRequestedID != 0 {
+			state = enumspb.PENDING_ACTIVITY_STATE_CANCEL_REQUESTED
+		} else if activity.started {
+			state = enumspb.PENDING_ACTIVITY_STATE_STARTED
+		}
+		response.PendingActivities = append(response.PendingActivities, &workflowpb.PendingActivityInfo{
+			ActivityId:             activity.attributes.GetActivityId(),
+			ActivityType:           activity.attributes.GetActivityType(),
+			State:                  state,
+			HeartbeatDetails:       activity.heartbeatDetails,
+			LastHeartbeatTimestamp: unixNanoOrZero(activity.lastHeartbeatTime),
+			LastStartedTimestamp:   unixNanoOrZero(activity.startedTime),
+			Attempt:                activity.attempt,
+			MaximumAttempts:        activity.attributes.GetRetryPolicy().GetMaximumAttempts(),
+			ScheduledTimestamp:     unixNanoOrZero(activity.attemptScheduledTime),
+			LastFailure:            activity.lastFailure,
+			LastWorkerIdentity:     activity.identity,
+		})
+	}
+	return response, nil
+}
+
+func (s *InMemoryWorkflowService) getExecution(namespace string, execution *commonpb.WorkflowExecution) (*inMemoryExecution, error) {
+	var e *inMemoryExecution
+	if execution.GetRunId() == "" {
+		e = s.current[inMemoryWorkflowKey{namespace: namespace, id: execution.GetWorkflowId()}]
+	} else {
+		e = s.executions[inMemoryExecutionKey{namespace: namespace, workflowID: execution.GetWorkflowId(), runID: execution.GetRunId()}]
+	}
+	if e == nil {
+		return nil, serviceerror.NewNotFound(fmt.Sprintf("workflow execution not found, WorkflowId: %v, RunId: %v", execution.GetWorkflowId(), execution.GetRunId()))
+	}
+	return e, nil
+}
+
+func (s *InMemoryWorkflowService) getRunningExecution(namespace string, execution *commonpb.WorkflowExecution) (*inMemoryExecution, error) {
+	e, err := s.getExecution(namespace, execution)
+	if err != nil {
+		return nil, err
+	}
+	if e.isClosed() {
+		return nil, serviceerror.NewNotFound("workflow execution already completed")
+	}
+	return e, nil
+}
+
+func (s *InMemoryWorkflowService) startExecution(request *workflowservice.StartWorkflowExecutionRequest) (*inMemoryExecution, error) {
+	if request.GetWorkflowId() == "" {
+		return nil, serviceerror.NewInvalidArgument("WorkflowId is not set on request.")
+	}
+	if request.GetWorkflowType().GetName() == "" {
+		return nil, serviceerror.NewInvalidArgument("WorkflowType is not set on request.")
+	}
+	if request.GetTaskQueue().GetName() == "" {
+		return nil, serviceerror.NewInvalidArgument("TaskQueue is not set on request.")
+	}
+
+	if previous, ok := s.current[inMemoryWorkflowKey{namespace: request.GetNamespace(), id: request.GetWorkflowId()}]; ok {
+		alreadyStarted := serviceerror.NewWorkflowExecutionAlreadyStarted(
+			fmt.Sprintf("Workflow execution already started. WorkflowId: %v, RunId: %v.", request.GetWorkflowId(), previous.execution.GetRunId()),
+			previous.requestID, previous.execution.GetRunId())
+		if !previous.isClosed() {
+			if request.GetRequestId() != "" && request.GetRequestId() == previous.requestID {
+				return previous, nil
+			}
+			return nil, alreadyStarted
+		}
+		switch request.GetWorkflowIdReusePolicy() {
+		case enumspb.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE:
+			return nil, alreadyStarted
+		case enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY:
+			if previous.status == enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED {
+				return nil, alreadyStarted
+			}
+		}
+	}
+
+	return s.newExecution(request.GetNamespace(), request.GetWorkflowId(), uuid.New(), request.GetRequestId(), &historypb.WorkflowExecutionStartedEventAttributes{
+		WorkflowType:                    request.GetWorkflowType(),
+		TaskQueue:                       request.GetTaskQueue(),
+		Input:                           request.GetInput(),
+		WorkflowExecutionTimeoutSeconds: request.GetWorkflowExecutionTimeoutSeconds(),
+		WorkflowRunTimeoutSeconds:       request.GetWorkflowRunTimeoutSeconds(),
+		WorkflowTaskTimeoutSeconds:      request.GetWorkflowTaskTimeoutSeconds(),
+		Identity:                        request.GetIdentity(),
+		RetryPolicy:                     request.GetRetryPolicy(),
+		CronSchedule:                    request.GetCronSchedule(),
+		Memo:                            request.GetMemo(),
+		SearchAttributes:                request.GetSearchAttributes(),
+		Header:                          request.GetHeader(),
+	}), nil
+}
+
+// newExecution creates a new run and writes its started event. The caller schedules the first workflow task.
+func (s *InMemoryWorkflowService) newExecution(namespace, workflowID, runID, requestID string, attributes *historypb.WorkflowExecutionStartedEventAttributes) *inMemoryExecution {
+	attributes.OriginalExecutionRunId = runID
+	if attributes.FirstExecutionRunId == "" {
+		attributes.FirstExecutionRunId = runID
+	}
+	if attributes.Attempt == 0 {
+		attributes.Attempt = 1
+	}
+	if attributes.WorkflowTaskTimeoutSeconds <= 0 {
+		attributes.WorkflowTaskTimeoutSeconds = int32(inMemoryDefaultWorkflowTaskTimeout / time.Second)
+	}
+	runTimeout := attributes.WorkflowRunTimeoutSeconds
+	if runTimeout == 0 {
+		runTimeout = attributes.WorkflowExecutionTimeoutSeconds
+	}
+
+	e := &inMemoryExecution{
+		namespace:           namespace,
+		execution:           &commonpb.WorkflowExecution{WorkflowId: workflowID, RunId: runID},
+		firstRunID:          attributes.FirstExecutionRunId,
+		workflowType:        attributes.WorkflowType,
+		taskQueue:           attributes.TaskQueue.GetName(),
+		requestID:           requestID,
+		startTime:           time.Now(),
+		status:              enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING,
+		memo:                attributes.Memo,
+		searchAttributes:    attributes.SearchAttributes,
+		executionTimeout:    attributes.WorkflowExecutionTimeoutSeconds,
+		runTimeout:          runTimeout,
+		taskTimeout:         time.Duration(attributes.WorkflowTaskTimeoutSeconds) * time.Second,
+		historyUpdated:      make(chan struct{}),
+		workflowTaskAttempt: 1,
+		activities:          make(map[int64]*inMemoryActivity),
+		timers:              make(map[string]*inMemoryTimer),
+	}
+	e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED, Attributes: &historypb.HistoryEvent_WorkflowExecutionStartedEventAttributes{WorkflowExecutionStartedEventAttributes: attributes}})
+	s.executions[inMemoryExecutionKey{namespace: namespace, workflowID: workflowID, runID: runID}] = e
+	s.current[inMemoryWorkflowKey{namespace: namespace, id: workflowID}] = e
+
+	if runTimeout > 0 {
+		e.runTimer = time.AfterFunc(time.Duration(runTimeout)*time.Second, func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			if e.isClosed() {
+				return
+			}
+			e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TIMED_OUT, Attributes: &historypb.HistoryEvent_WorkflowExecutionTimedOutEventAttributes{WorkflowExecutionTimedOutEventAttributes: &historypb.WorkflowExecutionTimedOutEventAttributes{
+				RetryState: enumspb.RETRY_STATE_TIMEOUT,
+			}}})
+			s.closeExecution(e, enumspb.WORKFLOW_EXECUTION_STATUS_TIMED_OUT)
+		})
+	}
+	return e
+}
+
+func (s *InMemoryWorkflowService) closeExecution(e *inMemoryExecution, status enumspb.WorkflowExecutionStatus) {
+	e.status = status
+	e.closeTime = time.Now()
+	if e.runTimer != nil {
+		e.runTimer.Stop()
+	}
+	if e.workflowTask != nil && e.workflowTask.timer != nil {
+		e.workflowTask.timer.Stop()
+	}
+	e.workflowTask = nil
+	e.bufferedEvents = nil
+	for _, activity := range e.activities {
+		s.removeActivity(e, activity)
+	}
+	for id, timer := range e.timers {
+		timer.timer.Stop()
+		delete(e.timers, id)
+	}
+}
+
+// addExternalEvents writes events which are not the result of a command. They are buffered while a workflow task
+// is started and a new workflow task is scheduled for them.
+func (s *InMemoryWorkflowService) addExternalEvents(e *inMemoryExecution, write func()) {
+	if e.workflowTask != nil && e.workflowTask.startedEventID != 0 {
+		e.bufferedEvents = append(e.bufferedEvents, write)
+		return
+	}
+	write()
+	s.scheduleWorkflowTask(e)
+}
+
+// handleCommand applies a command of a completed workflow task and returns whether a new workflow task is needed.
+func (s *InMemoryWorkflowService) handleCommand(e *inMemoryExecution, completedEventID int64, command *commandpb.Command, identity string) bool {
+	switch command.GetCommandType() {
+	case enumspb.COMMAND_TYPE_SCHEDULE_ACTIVITY_TASK:
+		s.scheduleActivity(e, completedEventID, command.GetScheduleActivityTaskCommandAttributes())
+
+	case enumspb.COMMAND_TYPE_REQUEST_CANCEL_ACTIVITY_TASK:
+		scheduledEventID := command.GetRequestCancelActivityTaskCommandAttributes().GetScheduledEventId()
+		event := e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_CANCEL_REQUESTED, Attributes: &historypb.HistoryEvent_ActivityTaskCancelRequestedEventAttributes{ActivityTaskCancelRequestedEventAttributes: &historypb.ActivityTaskCancelRequestedEventAttributes{
+			ScheduledEventId:             scheduledEventID,
+			WorkflowTaskCompletedEventId: completedEventID,
+		}}})
+		activity, ok := e.activities[scheduledEventID]
+		if !ok {
+			return false
+		}
+		activity.cancelRequestedID = event.GetEventId()
+		if !activity.started {
+			// not picked up by a worker, it is canceled right away
+			s.removeActivity(e, activity)
+			e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_CANCELED, Attributes: &historypb.HistoryEvent_ActivityTaskCanceledEventAttributes{ActivityTaskCanceledEventAttributes: &historypb.ActivityTaskCanceledEventAttributes{
+				LatestCancelRequestedEventId: event.GetEventId(),
+				ScheduledEventId:             scheduledEventID,
+				Identity:                     identity,
+			}}})
+			return true
+		}
+
+	case enumspb.COMMAND_TYPE_START_TIMER:
+		attributes := command.GetStartTimerCommandAttributes()
+		event := e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_TIMER_STARTED, Attributes: &historypb.HistoryEvent_TimerStartedEventAttributes{TimerStartedEventAttributes: &historypb.TimerStartedEventAttributes{
+			TimerId:                      attributes.GetTimerId(),
+			StartToFireTimeoutSeconds:    attributes.GetStartToFireTimeoutSeconds(),
+			WorkflowTaskCompletedEventId: completedEventID,
+		}}})
+		timer := &inMemoryTimer{startedEventID: event.GetEventId()}
+		timer.timer = time.AfterFunc(time.Duration(attributes.GetStartToFireTimeoutSeconds())*time.Second, func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			if e.timers[attributes.GetTimerId()] != timer {
+				return
+			}
+			delete(e.timers, attributes.GetTimerId())
+			s.addExternalEvents(e, func() {
+				e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_TIMER_FIRED, Attributes: &historypb.HistoryEvent_TimerFiredEventAttributes{TimerFiredEventAttributes: &historypb.TimerFiredEventAttributes{
+					TimerId:        attributes.GetTimerId(),
+					StartedEventId: timer.startedEventID,
+				}}})
+			})
+		})
+		e.timers[attributes.GetTimerId()] = timer
+
+	case enumspb.COMMAND_TYPE_CANCEL_TIMER:
+		timerID := command.GetCancelTimerCommandAttributes().GetTimerId()
+		timer, ok := e.timers[timerID]
+		if !ok {
+			return false
+		}
+		timer.timer.Stop()
+		delete(e.timers, timerID)
+		e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_TIMER_CANCELED, Attributes: &historypb.HistoryEvent_TimerCanceledEventAttributes{TimerCanceledEventAttributes: &historypb.TimerCanceledEventAttributes{
+			TimerId:                      timerID,
+			StartedEventId:               timer.startedEventID,
+			WorkflowTaskCompletedEventId: completedEventID,
+			Identity:                     identity,
+		}}})
+
+	case enumspb.COMMAND_TYPE_RECORD_MARKER:
+		attributes := command.GetRecordMarkerCommandAttributes()
+		e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_MARKER_RECORDED, Attributes: &historypb.HistoryEvent_MarkerRecordedEventAttributes{MarkerRecordedEventAttributes: &historypb.MarkerRecordedEventAttributes{
+			MarkerName:                   attributes.GetMarkerName(),
+			Details:                      attributes.GetDetails(),
+			WorkflowTaskCompletedEventId: completedEventID,
+			Header:                       attributes.GetHeader(),
+			Failure:                      attributes.GetFailure(),
+		}}})
+
+	case enumspb.COMMAND_TYPE_UPSERT_WORKFLOW_SEARCH_ATTRIBUTES:
+		searchAttributes := command.GetUpsertWorkflowSearchAttributesCommandAttributes().GetSearchAttributes()
+		e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_UPSERT_WORKFLOW_SEARCH_ATTRIBUTES, Attributes: &historypb.HistoryEvent_UpsertWorkflowSearchAttributesEventAttributes{UpsertWorkflowSearchAttributesEventAttributes: &historypb.UpsertWorkflowSearchAttributesEventAttributes{
+			WorkflowTaskCompletedEventId: completedEventID,
+			SearchAttributes:             searchAttributes,
+		}}})
+		fields := make(map[string]*commonpb.Payload)
+		for k, v := range e.searchAttributes.GetIndexedFields() {
+			fields[k] = v
+		}
+		for k, v := range searchAttributes.GetIndexedFields() {
+			fields[k] = v
+		}
+		e.searchAttributes = &commonpb.SearchAttributes{IndexedFields: fields}
+
+	case enumspb.COMMAND_TYPE_COMPLETE_WORKFLOW_EXECUTION:
+		e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED, Attributes: &historypb.HistoryEvent_WorkflowExecutionCompletedEventAttributes{WorkflowExecutionCompletedEventAttributes: &historypb.WorkflowExecutionCompletedEventAttributes{
+			Result:                       command.GetCompleteWorkflowExecutionCommandAttributes().GetResult(),
+			WorkflowTaskCompletedEventId: completedEventID,
+		}}})
+		s.closeExecution(e, enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED)
+
+	case enumspb.COMMAND_TYPE_FAIL_WORKFLOW_EXECUTION:
+		e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED, Attributes: &historypb.HistoryEvent_WorkflowExecutionFailedEventAttributes{WorkflowExecutionFailedEventAttributes: &historypb.WorkflowExecutionFailedEventAttributes{
+			Failure:                      command.GetFailWorkflowExecutionCommandAttributes().GetFailure(),
+			RetryState:                   enumspb.RETRY_STATE_RETRY_POLICY_NOT_SET,
+			WorkflowTaskCompletedEventId: completedEventID,
+		}}})
+		s.closeExecution(e, enumspb.WORKFLOW_EXECUTION_STATUS_FAILED)
+
+	case enumspb.COMMAND_TYPE_CANCEL_WORKFLOW_EXECUTION:
+		e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CANCELED, Attributes: &historypb.HistoryEvent_WorkflowExecutionCanceledEventAttributes{WorkflowExecutionCanceledEventAttributes: &historypb.WorkflowExecutionCanceledEventAttributes{
+			WorkflowTaskCompletedEventId: completedEventID,
+			Details:                      command.GetCancelWorkflowExecutionCommandAttributes().GetDetails(),
+		}}})
+		s.closeExecution(e, enumspb.WORKFLOW_EXECUTION_STATUS_CANCELED)
+
+	case enumspb.COMMAND_TYPE_CONTINUE_AS_NEW_WORKFLOW_EXECUTION:
+		s.continueAsNew(e, completedEventID, command.GetContinueAsNewWorkflowExecutionCommandAttributes())
+
+	default:
+		// RespondWorkflowTaskCompleted fails the workflow task before any command of it is applied
+		panic(fmt.Sprintf("command %v is not supported by InMemoryWorkflowService", command.GetCommandType()))
+	}
+	return false
+}
+
+// unsupportedCommandCause returns the cause of the workflow task failure for a command the service doesn't support.
+func unsupportedCommandCause(commandType enumspb.CommandType) enumspb.WorkflowTaskFailedCause {
+	switch commandType {
+	case enumspb.COMMAND_TYPE_START_CHILD_WORKFLOW_EXECUTION:
+		return enumspb.WORKFLOW_TASK_FAILED_CAUSE_BAD_START_CHILD_EXECUTION_ATTRIBUTES
+	case enumspb.COMMAND_TYPE_SIGNAL_EXTERNAL_WORKFLOW_EXECUTION:
+		return enumspb.WORKFLOW_TASK_FAILED_CAUSE_BAD_SIGNAL_WORKFLOW_EXECUTION_ATTRIBUTES
+	case enumspb.COMMAND_TYPE_REQUEST_CANCEL_EXTERNAL_WORKFLOW_EXECUTION:
+		return enumspb.WORKFLOW_TASK_FAILED_CAUSE_BAD_REQUEST_CANCEL_EXTERNAL_WORKFLOW_EXECUTION_ATTRIBUTES
+	}
+	return enumspb.WORKFLOW_TASK_FAILED_CAUSE_UNSPECIFIED
+}
+
+func (s *InMemoryWorkflowService) continueAsNew(e *inMemoryExecution, completedEventID int64, attributes *commandpb.ContinueAsNewWorkflowExecutionCommandAttributes) {
+	taskQueue := attributes.GetTaskQueue()
+	if taskQueue.GetName() == "" {
+		taskQueue = &taskqueuepb.TaskQueue{Name: e.taskQueue, Kind: enumspb.TASK_QUEUE_KIND_NORMAL}
+	}
+	runTimeout := attributes.GetWorkflowRunTimeoutSeconds()
+	if runTimeout == 0 {
+		runTimeout = e.runTimeout
+	}
+	taskTimeout := attributes.GetWorkflowTaskTimeoutSeconds()
+	if taskTimeout == 0 {
+		taskTimeout = int32(e.taskTimeout / time.Second)
+	}
+	memo := attributes.GetMemo()
+	if memo == nil {
+		memo = e.memo
+	}
+	searchAttributes := attributes.GetSearchAttributes()
+	if searchAttributes == nil {
+		searchAttributes = e.searchAttributes
+	}
+
+	newRunID := uuid.New()
+	e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CONTINUED_AS_NEW, Attributes: &historypb.HistoryEvent_WorkflowExecutionContinuedAsNewEventAttributes{WorkflowExecutionContinuedAsNewEventAttributes: &historypb.WorkflowExecutionContinuedAsNewEventAttributes{
+		NewExecutionRunId:             newRunID,
+		WorkflowType:                  attributes.GetWorkflowType(),
+		TaskQueue:                     taskQueue,
+		Input:                         attributes.GetInput(),
+		WorkflowRunTimeoutSeconds:     runTimeout,
+		WorkflowTaskTimeoutSeconds:    taskTimeout,
+		WorkflowTaskCompletedEventId:  completedEventID,
+		BackoffStartIntervalInSeconds: attributes.GetBackoffStartIntervalInSeconds(),
+		Initiator:                     attributes.GetInitiator(),
+		Failure:                       attributes.GetFailure(),
+		LastCompletionResult:          attributes.GetLastCompletionResult(),
+		Header:                        attributes.GetHeader(),
+		Memo:                          memo,
+		SearchAttributes:              searchAttributes,
+	}}})
+	s.closeExecution(e, enumspb.WORKFLOW_EXECUTION_STATUS_CONTINUED_AS_NEW)
+
+	next := s.newExecution(e.namespace, e.execution.GetWorkflowId(), newRunID, "", &historypb.WorkflowExecutionStartedEventAttributes{
+		WorkflowType:                    attributes.GetWorkflowType(),
+		TaskQueue:                       taskQueue,
+		Input:                           attributes.GetInput(),
+		WorkflowExecutionTimeoutSeconds: e.executionTimeout,
+		WorkflowRunTimeoutSeconds:       runTimeout,
+		WorkflowTaskTimeoutSeconds:      taskTimeout,
+		ContinuedExecutionRunId:         e.execution.GetRunId(),
+		Initiator:                       attributes.GetInitiator(),
+		ContinuedFailure:                attributes.GetFailure(),
+		LastCompletionResult:            attributes.GetLastCompletionResult(),
+		FirstExecutionRunId:             e.firstRunID,
+		RetryPolicy:                     attributes.GetRetryPolicy(),
+		CronSchedule:                    attributes.GetCronSchedule(),
+		FirstWorkflowTaskBackoffSeconds: attributes.GetBackoffStartIntervalInSeconds(),
+		Memo:                            memo,
+		SearchAttributes:                searchAttributes,
+		Header:                          attributes.GetHeader(),
+	})
+	if backoff := attributes.GetBackoffStartIntervalInSeconds(); backoff > 0 {
+		time.AfterFunc(time.Duration(backoff)*time.Second, func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			s.scheduleWorkflowTask(next)
+		})
+		return
+	}
+	s.scheduleWorkflowTask(next)
+}
+
+func (e *inMemoryExecution) isClosed() bool {
+	return e.status != enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING
+}
+
+func (e *inMemoryExecution) addEvent(event *historypb.HistoryEvent) *historypb.HistoryEvent {
+	event.EventId = int64(len(e.history)) + 1
+	event.Timestamp = time.Now().UnixNano()
+	e.history = append(e.history, event)
+	close(e.historyUpdated)
+	e.historyUpdated = make(chan struct{})
+	return event
+}
+
+func (e *inMemoryExecution) addActivityStartedEvent(activity *inMemoryActivity) *historypb.HistoryEvent {
+	return e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_STARTED, Attributes: &historypb.HistoryEvent_ActivityTaskStartedEventAttributes{ActivityTaskStartedEventAttributes: &historypb.ActivityTaskStartedEventAttributes{
+		ScheduledEventId: activity.scheduledEventID,
+		Identity:         activity.identity,
+		RequestId:        uuid.New(),
+		Attempt:          activity.attempt,
+		LastFailure:      activity.lastFailure,
+	}}})
+}
+
+func (e *inMemoryExecution) flushBufferedEvents() {
+	buffered := e.bufferedEvents
+	e.bufferedEvents = nil
+	for _, write := range buffered {
+		write()
+	}
+}
+
+func (e *inMemoryExecution) info() *workflowpb.WorkflowExecutionInfo {
+	info := &workflowpb.WorkflowExecutionInfo{
+		Execution:        e.execution,
+		Type:             e.workflowType,
+		StartTime:        &types.Int64Value{Value: e.startTime.UnixNano()},
+		Status:           e.status,
+		HistoryLength:    int64(len(e.history)),
+		ExecutionTime:    e.startTime.UnixNano(),
+		Memo:             e.memo,
+		SearchAttributes: e.searchAttributes,
+		TaskQueue:        e.taskQueue,
+	}
+	if e.isClosed() {
+		info.CloseTime = &types.Int64Value{Value: e.closeTime.UnixNano()}
+	}
+	return info
+}
@@ -0,0 +1,542 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pborman/uuid"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/api/serviceerror"
+	taskqueuepb "go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"google.golang.org/grpc"
+)
+
+// PollWorkflowTaskQueue implementation. It returns workflow tasks and query tasks, or an empty response once
+// the context is done.
+func (s *InMemoryWorkflowService) PollWorkflowTaskQueue(ctx context.Context, request *workflowservice.PollWorkflowTaskQueueRequest, _ ...grpc.CallOption) (*workflowservice.PollWorkflowTaskQueueResponse, error) {
+	key := inMemoryWorkflowKey{namespace: request.GetNamespace(), id: request.GetTaskQueue().GetName()}
+	s.lock.Lock()
+	for {
+		queue := s.getTaskQueue(key)
+		for ctx.Err() == nil && len(queue.workflowTasks) > 0 {
+			task := queue.workflowTasks[0]
+			queue.workflowTasks = queue.workflowTasks[1:]
+			var response *workflowservice.PollWorkflowTaskQueueResponse
+			if task.query != nil {
+				response = s.startQueryTask(task.query, key.id)
+			} else {
+				response = s.startWorkflowTask(task.execution, task.workflowTask, key.id, request.GetIdentity())
+			}
+			if response != nil {
+				response.BacklogCountHint = int64(len(queue.workflowTasks))
+				s.lock.Unlock()
+				return response, nil
+			}
+		}
+		notify := queue.notify
+		s.lock.Unlock()
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return &workflowservice.PollWorkflowTaskQueueResponse{}, nil
+		}
+		s.lock.Lock()
+	}
+}
+
+// RespondWorkflowTaskCompleted implementation
+func (s *InMemoryWorkflowService) RespondWorkflowTaskCompleted(_ context.Context, request *workflowservice.RespondWorkflowTaskCompletedRequest, _ ...grpc.CallOption) (*workflowservice.RespondWorkflowTaskCompletedResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, task, err := s.getStartedWorkflowTask(request.GetTaskToken())
+	if err != nil {
+		return nil, err
+	}
+	closing := false
+	for _, command := range request.GetCommands() {
+		switch command.GetCommandType() {
+		case enumspb.COMMAND_TYPE_SCHEDULE_ACTIVITY_TASK,
+			enumspb.COMMAND_TYPE_REQUEST_CANCEL_ACTIVITY_TASK,
+			enumspb.COMMAND_TYPE_START_TIMER,
+			enumspb.COMMAND_TYPE_CANCEL_TIMER,
+			enumspb.COMMAND_TYPE_RECORD_MARKER,
+			enumspb.COMMAND_TYPE_UPSERT_WORKFLOW_SEARCH_ATTRIBUTES:
+		case enumspb.COMMAND_TYPE_COMPLETE_WORKFLOW_EXECUTION,
+			enumspb.COMMAND_TYPE_FAIL_WORKFLOW_EXECUTION,
+			enumspb.COMMAND_TYPE_CANCEL_WORKFLOW_EXECUTION,
+			enumspb.COMMAND_TYPE_CONTINUE_AS_NEW_WORKFLOW_EXECUTION:
+			closing = true
+		default:
+			// like the server rejecting bad command attributes, the workflow task fails and none of its
+			// commands are applied, so the failure shows up in history instead of the workflow waiting forever
+			message := fmt.Sprintf("command %v is not supported by InMemoryWorkflowService", command.GetCommandType())
+			task.timer.Stop()
+			s.failWorkflowTask(e, task, unsupportedCommandCause(command.GetCommandType()), &failurepb.Failure{
+				Message: message,
+				Source:  "InMemoryWorkflowService",
+			}, request.GetIdentity())
+			return nil, serviceerror.NewInvalidArgument(message)
+		}
+	}
+	task.timer.Stop()
+
+	if closing && len(e.bufferedEvents) > 0 {
+		// new events arrived while the workflow was closing, let the workflow handle them first
+		s.failWorkflowTask(e, task, enumspb.WORKFLOW_TASK_FAILED_CAUSE_UNHANDLED_COMMAND, nil, request.GetIdentity())
+		return &workflowservice.RespondWorkflowTaskCompletedResponse{}, nil
+	}
+
+	completedEvent := e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_TASK_COMPLETED, Attributes: &historypb.HistoryEvent_WorkflowTaskCompletedEventAttributes{WorkflowTaskCompletedEventAttributes: &historypb.WorkflowTaskCompletedEventAttributes{
+		ScheduledEventId: task.scheduledEventID,
+		StartedEventId:   task.startedEventID,
+		Identity:         request.GetIdentity(),
+		BinaryChecksum:   request.GetBinaryChecksum(),
+	}}})
+	e.workflowTask = nil
+	e.workflowTaskAttempt = 1
+	e.previousStartedEventID = task.startedEventID
+	if sticky := request.GetStickyAttributes(); sticky.GetWorkerTaskQueue().GetName() != "" {
+		e.stickyTaskQueue = sticky.GetWorkerTaskQueue().GetName()
+		e.stickyTimeout = time.Duration(sticky.GetScheduleToStartTimeoutSeconds()) * time.Second
+		if e.stickyTimeout <= 0 {
+			e.stickyTimeout = inMemoryDefaultStickyScheduleToStartTimeout
+		}
+	}
+
+	newWorkflowTask := request.GetForceCreateNewWorkflowTask()
+	for _, command := range request.GetCommands() {
+		if e.isClosed() {
+			break
+		}
+		if s.handleCommand(e, completedEvent.GetEventId(), command, request.GetIdentity()) {
+			newWorkflowTask = true
+		}
+	}
+	if e.isClosed() {
+		return &workflowservice.RespondWorkflowTaskCompletedResponse{}, nil
+	}
+
+	if len(e.bufferedEvents) > 0 {
+		e.flushBufferedEvents()
+		newWorkflowTask = true
+	}
+	if !newWorkflowTask {
+		return &workflowservice.RespondWorkflowTaskCompletedResponse{}, nil
+	}
+
+	s.scheduleWorkflowTask(e)
+	response := &workflowservice.RespondWorkflowTaskCompletedResponse{}
+	if request.GetReturnNewWorkflowTask() && e.workflowTask != nil {
+		response.WorkflowTask = s.startWorkflowTask(e, e.workflowTask, e.workflowTask.taskQueue, request.GetIdentity())
+	}
+	return response, nil
+}
+
+// RespondWorkflowTaskFailed implementation
+func (s *InMemoryWorkflowService) RespondWorkflowTaskFailed(_ context.Context, request *workflowservice.RespondWorkflowTaskFailedRequest, _ ...grpc.CallOption) (*workflowservice.RespondWorkflowTaskFailedResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, task, err := s.getStartedWorkflowTask(request.GetTaskToken())
+	if err != nil {
+		return nil, err
+	}
+	task.timer.Stop()
+	s.failWorkflowTask(e, task, request.GetCause(), request.GetFailure(), request.GetIdentity())
+	return &workflowservice.RespondWorkflowTaskFailedResponse{}, nil
+}
+
+// RespondQueryTaskCompleted implementation
+func (s *InMemoryWorkflowService) RespondQueryTaskCompleted(_ context.Context, request *workflowservice.RespondQueryTaskCompletedRequest, _ ...grpc.CallOption) (*workflowservice.RespondQueryTaskCompletedResponse, error) {
+	token, err := decodeInMemoryTaskToken(request.GetTaskToken())
+	if err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	query, ok := s.queries[token.QueryID]
+	if !ok {
+		return nil, serviceerror.NewNotFound("query task not found")
+	}
+	delete(s.queries, token.QueryID)
+	query.result <- request
+	return &workflowservice.RespondQueryTaskCompletedResponse{}, nil
+}
+
+// QueryWorkflow implementation. The query is dispatched as a query task to the sticky task queue of the workflow,
+// or to its task queue, and waits for the worker to answer it.
+func (s *InMemoryWorkflowService) QueryWorkflow(ctx context.Context, request *workflowservice.QueryWorkflowRequest, _ ...grpc.CallOption) (*workflowservice.QueryWorkflowResponse, error) {
+	s.lock.Lock()
+	e, err := s.getExecution(request.GetNamespace(), request.GetExecution())
+	if err != nil {
+		s.lock.Unlock()
+		return nil, err
+	}
+	query := &inMemoryQuery{
+		id:        uuid.New(),
+		execution: e,
+		query:     request.GetQuery(),
+		result:    make(chan *workflowservice.RespondQueryTaskCompletedRequest, 1),
+	}
+	s.queries[query.id] = query
+	if e.stickyTaskQueue != "" && !e.isClosed() {
+		s.enqueueWorkflowTask(e.namespace, e.stickyTaskQueue, inMemoryQueuedWorkflowTask{execution: e, query: query})
+		time.AfterFunc(e.stickyTimeout, func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			if !query.dispatched {
+				s.enqueueWorkflowTask(e.namespace, e.taskQueue, inMemoryQueuedWorkflowTask{execution: e, query: query})
+			}
+		})
+	} else {
+		s.enqueueWorkflowTask(e.namespace, e.taskQueue, inMemoryQueuedWorkflowTask{execution: e, query: query})
+	}
+	s.lock.Unlock()
+
+	select {
+	case result := <-query.result:
+		if result.GetCompletedType() != enumspb.QUERY_RESULT_TYPE_ANSWERED {
+			return nil, serviceerror.NewQueryFailed(result.GetErrorMessage())
+		}
+		return &workflowservice.QueryWorkflowResponse{QueryResult: result.GetQueryResult()}, nil
+	case <-ctx.Done():
+		s.lock.Lock()
+		delete(s.queries, query.id)
+		query.dispatched = true
+		s.lock.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// ResetStickyTaskQueue implementation
+func (s *InMemoryWorkflowService) ResetStickyTaskQueue(_ context.Context, request *workflowservice.ResetStickyTaskQueueRequest, _ ...grpc.CallOption) (*workflowservice.ResetStickyTaskQueueResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, err := s.getExecution(request.GetNamespace(), request.GetExecution())
+	if err != nil {
+		return nil, err
+	}
+	s.clearStickyTaskQueue(e)
+	return &workflowservice.ResetStickyTaskQueueResponse{}, nil
+}
+
+// PollActivityTaskQueue implementation. It returns an empty response once the context is done.
+func (s *InMemoryWorkflowService) PollActivityTaskQueue(ctx context.Context, request *workflowservice.PollActivityTaskQueueRequest, _ ...grpc.CallOption) (*workflowservice.PollActivityTaskQueueResponse, error) {
+	key := inMemoryWorkflowKey{namespace: request.GetNamespace(), id: request.GetTaskQueue().GetName()}
+	s.lock.Lock()
+	for {
+		queue := s.getTaskQueue(key)
+		for ctx.Err() == nil && len(queue.activityTasks) > 0 {
+			task := queue.activityTasks[0]
+			queue.activityTasks = queue.activityTasks[1:]
+			if response := s.startActivityTask(task, request.GetIdentity()); response != nil {
+				s.lock.Unlock()
+				return response, nil
+			}
+		}
+		notify := queue.notify
+		s.lock.Unlock()
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return &workflowservice.PollActivityTaskQueueResponse{}, nil
+		}
+		s.lock.Lock()
+	}
+}
+
+// DescribeTaskQueue implementation. Pollers are not tracked.
+func (s *InMemoryWorkflowService) DescribeTaskQueue(_ context.Context, _ *workflowservice.DescribeTaskQueueRequest, _ ...grpc.CallOption) (*workflowservice.DescribeTaskQueueResponse, error) {
+	return &workflowservice.DescribeTaskQueueResponse{}, nil
+}
+
+// ListTaskQueuePartitions implementation
+func (s *InMemoryWorkflowService) ListTaskQueuePartitions(_ context.Context, _ *workflowservice.ListTaskQueuePartitionsRequest, _ ...grpc.CallOption) (*workflowservice.ListTaskQueuePartitionsResponse, error) {
+	return &workflowservice.ListTaskQueuePartitionsResponse{}, nil
+}
+
+func (s *InMemoryWorkflowService) getTaskQueue(key inMemoryWorkflowKey) *inMemoryTaskQueue {
+	queue, ok := s.taskQueues[key]
+	if !ok {
+		queue = &inMemoryTaskQueue{notify: make(chan struct{})}
+		s.taskQueues[key] = queue
+	}
+	return queue
+}
+
+func (s *InMemoryWorkflowService) enqueueWorkflowTask(namespace, taskQueue string, task inMemoryQueuedWorkflowTask) {
+	queue := s.getTaskQueue(inMemoryWorkflowKey{namespace: namespace, id: taskQueue})
+	queue.workflowTasks = append(queue.workflowTasks, task)
+	close(queue.notify)
+	queue.notify = make(chan struct{})
+}
+
+func (s *InMemoryWorkflowService) enqueueActivityTask(namespace, taskQueue string, task inMemoryQueuedActivityTask) {
+	queue := s.getTaskQueue(inMemoryWorkflowKey{namespace: namespace, id: taskQueue})
+	queue.activityTasks = append(queue.activityTasks, task)
+	close(queue.notify)
+	queue.notify = make(chan struct{})
+}
+
+func (s *InMemoryWorkflowService) getStartedWorkflowTask(taskToken []byte) (*inMemoryExecution, *inMemoryWorkflowTask, error) {
+	token, err := decodeInMemoryTaskToken(taskToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	e := s.executions[inMemoryExecutionKey{namespace: token.Namespace, workflowID: token.WorkflowID, runID: token.RunID}]
+	if e == nil || e.isClosed() {
+		return nil, nil, serviceerror.NewNotFound("workflow execution already completed")
+	}
+	task := e.workflowTask
+	if task == nil || task.startedEventID == 0 || task.scheduledEventID != token.ScheduledEventID || task.attempt != token.Attempt {
+		return nil, nil, serviceerror.NewNotFound("workflow task not found")
+	}
+	return e, task, nil
+}
+
+func (s *InMemoryWorkflowService) scheduleWorkflowTask(e *inMemoryExecution) {
+	if e.isClosed() || e.workflowTask != nil {
+		return
+	}
+	taskQueue := &taskqueuepb.TaskQueue{Name: e.taskQueue, Kind: enumspb.TASK_QUEUE_KIND_NORMAL}
+	if e.stickyTaskQueue != "" {
+		taskQueue = &taskqueuepb.TaskQueue{Name: e.stickyTaskQueue, Kind: enumspb.TASK_QUEUE_KIND_STICKY}
+	}
+	event := e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_TASK_SCHEDULED, Attributes: &historypb.HistoryEvent_WorkflowTaskScheduledEventAttributes{WorkflowTaskScheduledEventAttributes: &historypb.WorkflowTaskScheduledEventAttributes{
+		TaskQueue:                  taskQueue,
+		StartToCloseTimeoutSeconds: int32(e.taskTimeout / time.Second),
+		Attempt:                    e.workflowTaskAttempt,
+	}}})
+	task := &inMemoryWorkflowTask{
+		scheduledEventID: event.GetEventId(),
+		attempt:          e.workflowTaskAttempt,
+		taskQueue:        taskQueue.GetName(),
+		scheduledTime:    time.Now(),
+	}
+	e.workflowTask = task
+	s.enqueueWorkflowTask(e.namespace, task.taskQueue, inMemoryQueuedWorkflowTask{execution: e, workflowTask: task})
+
+	if taskQueue.GetKind() == enumspb.TASK_QUEUE_KIND_STICKY {
+		task.timer = time.AfterFunc(e.stickyTimeout, func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			if e.workflowTask == task && task.startedEventID == 0 {
+				s.timeoutWorkflowTask(e, task, enumspb.TIMEOUT_TYPE_SCHEDULE_TO_START)
+			}
+		})
+	}
+}
+
+// startWorkflowTask returns nil if the queued task is no longer valid. Tasks polled from the sticky task queue only
+// contain the events after the previous workflow task, the worker has the rest cached.
+func (s *InMemoryWorkflowService) startWorkflowTask(e *inMemoryExecution, task *inMemoryWorkflowTask, taskQueue, identity string) *workflowservice.PollWorkflowTaskQueueResponse {
+	if e.workflowTask != task || task.startedEventID != 0 || task.taskQueue != taskQueue {
+		return nil
+	}
+	event := e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_TASK_STARTED, Attributes: &historypb.HistoryEvent_WorkflowTaskStartedEventAttributes{WorkflowTaskStartedEventAttributes: &historypb.WorkflowTaskStartedEventAttributes{
+		ScheduledEventId: task.scheduledEventID,
+		Identity:         identity,
+		RequestId:        uuid.New(),
+	}}})
+	task.startedEventID = event.GetEventId()
+	if task.timer != nil {
+		task.timer.Stop()
+	}
+	task.timer = time.AfterFunc(e.taskTimeout, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if e.workflowTask == task {
+			s.timeoutWorkflowTask(e, task, enumspb.TIMEOUT_TYPE_START_TO_CLOSE)
+		}
+	})
+
+	events := e.history
+	if e.stickyTaskQueue != "" && taskQueue == e.stickyTaskQueue {
+		events = e.history[e.previousStartedEventID:]
+	}
+	token := inMemoryTaskToken{
+		Namespace:        e.namespace,
+		WorkflowID:       e.execution.GetWorkflowId(),
+		RunID:            e.execution.GetRunId(),
+		ScheduledEventID: task.scheduledEventID,
+		Attempt:          task.attempt,
+	}
+	return &workflowservice.PollWorkflowTaskQueueResponse{
+		TaskToken:                  token.encode(),
+		WorkflowExecution:          e.execution,
+		WorkflowType:               e.workflowType,
+		PreviousStartedEventId:     e.previousStartedEventID,
+		StartedEventId:             task.startedEventID,
+		Attempt:                    task.attempt,
+		History:                    &historypb.History{Events: append([]*historypb.HistoryEvent(nil), events...)},
+		WorkflowExecutionTaskQueue: &taskqueuepb.TaskQueue{Name: e.taskQueue, Kind: enumspb.TASK_QUEUE_KIND_NORMAL},
+		ScheduledTimestamp:         task.scheduledTime.UnixNano(),
+		StartedTimestamp:           event.GetTimestamp(),
+	}
+}
+
+// startQueryTask returns nil if the query was already dispatched or abandoned. Query tasks polled from the sticky
+// task queue have no history, the worker answers them from its cached workflow state.
+func (s *InMemoryWorkflowService) startQueryTask(query *inMemoryQuery, taskQueue string) *workflowservice.PollWorkflowTaskQueueResponse {
+	if query.dispatched {
+		return nil
+	}
+	query.dispatched = true
+	e := query.execution
+	var events []*historypb.HistoryEvent
+	if e.stickyTaskQueue == "" || taskQueue != e.stickyTaskQueue {
+		events = append(events, e.history...)
+	}
+	token := inMemoryTaskToken{
+		Namespace:  e.namespace,
+		WorkflowID: e.execution.GetWorkflowId(),
+		RunID:      e.execution.GetRunId(),
+		QueryID:    query.id,
+	}
+	return &workflowservice.PollWorkflowTaskQueueResponse{
+		TaskToken:                  token.encode(),
+		WorkflowExecution:          e.execution,
+		WorkflowType:               e.workflowType,
+		PreviousStartedEventId:     e.previousStartedEventID,
+		History:                    &historypb.History{Events: events},
+		Query:                      query.query,
+		WorkflowExecutionTaskQueue: &taskqueuepb.TaskQueue{Name: e.taskQueue, Kind: enumspb.TASK_QUEUE_KIND_NORMAL},
+	}
+}
+
+func (s *InMemoryWorkflowService) timeoutWorkflowTask(e *inMemoryExecution, task *inMemoryWorkflowTask, timeoutType enumspb.TimeoutType) {
+	e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_TASK_TIMED_OUT, Attributes: &historypb.HistoryEvent_WorkflowTaskTimedOutEventAttributes{WorkflowTaskTimedOutEventAttributes: &historypb.WorkflowTaskTimedOutEventAttributes{
+		ScheduledEventId: task.scheduledEventID,
+		StartedEventId:   task.startedEventID,
+		TimeoutType:      timeoutType,
+	}}})
+	e.workflowTask = nil
+	if timeoutType == enumspb.TIMEOUT_TYPE_START_TO_CLOSE {
+		e.workflowTaskAttempt = task.attempt + 1
+	}
+	e.stickyTaskQueue = ""
+	e.flushBufferedEvents()
+	s.scheduleWorkflowTask(e)
+}
+
+func (s *InMemoryWorkflowService) failWorkflowTask(e *inMemoryExecution, task *inMemoryWorkflowTask, cause enumspb.WorkflowTaskFailedCause, failure *failurepb.Failure, identity string) {
+	e.addEvent(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_TASK_FAILED, Attributes: &historypb.HistoryEvent_WorkflowTaskFailedEventAttributes{WorkflowTaskFailedEventAttributes: &historypb.WorkflowTaskFailedEventAttributes{
+		ScheduledEventId: task.scheduledEventID,
+		StartedEventId:   task.startedEventID,
+		Cause:            cause,
+		Failure:          failure,
+		Identity:         identity,
+	}}})
+	e.workflowTask = nil
+	if cause != enumspb.WORKFLOW_TASK_FAILED_CAUSE_UNHANDLED_COMMAND {
+		e.workflowTaskAttempt = task.attempt + 1
+	}
+	// the cached workflow state of the worker is no longer valid
+	e.stickyTaskQueue = ""
+	e.flushBufferedEvents()
+	s.scheduleWorkflowTask(e)
+}
+
+func (s *InMemoryWorkflowService) clearStickyTaskQueue(e *inMemoryExecution) {
+	e.stickyTaskQueue = ""
+	if task := e.workflowTask; task != nil && task.startedEventID == 0 && task.taskQueue != e.taskQueue {
+		if task.timer != nil {
+			task.timer.Stop()
+		}
+		task.taskQueue = e.taskQueue
+		s.enqueueWorkflowTask(e.namespace, e.taskQueue, inMemoryQueuedWorkflowTask{execution: e, workflowTask: task})
+	}
+}
+
+// startActivityTask returns nil if the queued activity task is no longer valid.
+func (s *InMemoryWorkflowService) startActivityTask(task inMemoryQueuedActivityTask, identity string) *workflowservice.PollActivityTaskQueueResponse {
+	e, activity := task.execution, task.activity
+	if e.activities[activity.scheduledEventID] != activity || activity.attempt != task.attempt || activity.started {
+		return nil
+	}
+	activity.stopAttemptTimers()
+	activity.started = true
+	activity.startedTime = time.Now()
+	activity.identity = identity
+
+	attempt := activity.attempt
+	if timeout := activity.attributes.GetStartToCloseTimeoutSeconds(); timeout > 0 {
+		activity.attemptTimers = append(activity.attemptTimers, time.AfterFunc(time.Duration(timeout)*time.Second, func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			if e.activities[activity.scheduledEventID] == activity && activity.attempt == attempt && activity.started {
+				s.timeoutActivity(e, activity, enumspb.TIMEOUT_TYPE_START_TO_CLOSE)
+			}
+		}))
+	}
+	s.startHeartbeatTimer(e, activity)
+
+	token := inMemoryTaskToken{
+		Namespace:        e.namespace,
+		WorkflowID:       e.execution.GetWorkflowId(),
+		RunID:            e.execution.GetRunId(),
+		ScheduledEventID: activity.scheduledEventID,
+		Attempt:          int64(attempt),
+	}
+	attributes := activity.attributes
+	return &workflowservice.PollActivityTaskQueueResponse{
+		TaskToken:                     token.encode(),
+		WorkflowNamespace:             e.namespace,
+		WorkflowType:                  e.workflowType,
+		WorkflowExecution:             e.execution,
+		ActivityType:                  attributes.GetActivityType(),
+		ActivityId:                    attributes.GetActivityId(),
+		Header:                        attributes.GetHeader(),
+		Input:                         attributes.GetInput(),
+		HeartbeatDetails:              activity.heartbeatDetails,
+		ScheduledTimestamp:            activity.scheduledTime.UnixNano(),
+		ScheduledTimestampThisAttempt: activity.attemptScheduledTime.UnixNano(),
+		StartedTimestamp:              activity.startedTime.UnixNano(),
+		Attempt:                       attempt,
+		ScheduleToCloseTimeoutSeconds: attributes.GetScheduleToCloseTimeoutSeconds(),
+		StartToCloseTimeoutSeconds:    attributes.GetStartToCloseTimeoutSeconds(),
+		HeartbeatTimeoutSeconds:       attributes.GetHeartbeatTimeoutSeconds(),
+		RetryPolicy:                   attributes.GetRetryPolicy(),
+	}
+}
+
+func (t inMemoryTaskToken) encode() []byte {
+	token, _ := json.Marshal(t)
+	return token
+}
+
+func decodeInMemoryTaskToken(taskToken []byte) (*inMemoryTaskToken, error) {
+	token := &inMemoryTaskToken{}
+	if err := json.Unmarshal(taskToken, token); err != nil {
+		return nil, serviceerror.NewInvalidArgument("invalid task token")
+	}
+	return token, nil
+}
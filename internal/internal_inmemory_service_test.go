@@ -0,0 +1,241 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/workflowservice/v1"
+
+	ilog "go.temporal.io/sdk/internal/log"
+)
+
+const inMemoryTestTaskQueue = "in-memory-test-task-queue"
+
+type InMemoryWorkflowServiceTestSuite struct {
+	suite.Suite
+	service *InMemoryWorkflowService
+	client  *WorkflowClient
+	worker  *AggregatedWorker
+}
+
+func TestInMemoryWorkflowServiceSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryWorkflowServiceTestSuite))
+}
+
+func inMemoryGreetingWorkflow(ctx Context, name string) (string, error) {
+	ctx = WithActivityOptions(ctx, ActivityOptions{
+		StartToCloseTimeout: 10 * time.Second,
+		RetryPolicy: &RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 1,
+			MaximumAttempts:    3,
+		},
+	})
+	status := "started"
+	if err := SetQueryHandler(ctx, "status", func() (string, error) {
+		return status, nil
+	}); err != nil {
+		return "", err
+	}
+
+	var greeting string
+	if err := ExecuteActivity(ctx, "flakyGreeting", name).Get(ctx, &greeting); err != nil {
+		return "", err
+	}
+	if err := NewTimer(ctx, time.Second).Get(ctx, nil); err != nil {
+		return "", err
+	}
+
+	status = "waiting for signal"
+	var suffix string
+	GetSignalChannel(ctx, "suffix").Receive(ctx, &suffix)
+	return greeting + suffix, nil
+}
+
+func inMemoryFlakyGreetingActivity(ctx context.Context, name string) (string, error) {
+	if GetActivityInfo(ctx).Attempt == 1 {
+		return "", errors.New("first attempt fails")
+	}
+	return "Hello " + name, nil
+}
+
+func inMemoryTimeoutWorkflow(ctx Context) error {
+	ctx = WithActivityOptions(ctx, ActivityOptions{
+		StartToCloseTimeout: time.Second,
+		RetryPolicy:         &RetryPolicy{MaximumAttempts: 1},
+	})
+	return ExecuteActivity(ctx, "blockingActivity").Get(ctx, nil)
+}
+
+func inMemoryBlockingActivity(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func inMemoryChildWorkflow(ctx Context) error {
+	ctx = WithChildWorkflowOptions(ctx, ChildWorkflowOptions{})
+	return ExecuteChildWorkflow(ctx, "greetingWorkflow", "child").Get(ctx, nil)
+}
+
+func (s *InMemoryWorkflowServiceTestSuite) SetupTest() {
+	s.service = NewInMemoryWorkflowService()
+	client, err := NewClient(ClientOptions{WorkflowService: s.service, Logger: ilog.NewNopLogger()})
+	s.NoError(err)
+	s.client = client.(*WorkflowClient)
+
+	s.worker = NewAggregatedWorker(s.client, inMemoryTestTaskQueue, WorkerOptions{})
+	s.worker.RegisterWorkflowWithOptions(inMemoryGreetingWorkflow, RegisterWorkflowOptions{Name: "greetingWorkflow"})
+	s.worker.RegisterWorkflowWithOptions(inMemoryTimeoutWorkflow, RegisterWorkflowOptions{Name: "timeoutWorkflow"})
+	s.worker.RegisterWorkflowWithOptions(inMemoryChildWorkflow, RegisterWorkflowOptions{Name: "childWorkflow"})
+	s.worker.RegisterActivityWithOptions(inMemoryFlakyGreetingActivity, RegisterActivityOptions{Name: "flakyGreeting"})
+	s.worker.RegisterActivityWithOptions(inMemoryBlockingActivity, RegisterActivityOptions{Name: "blockingActivity"})
+	s.NoError(s.worker.Start())
+}
+
+func (s *InMemoryWorkflowServiceTestSuite) TearDownTest() {
+	s.worker.Stop()
+	s.client.Close()
+}
+
+func (s *InMemoryWorkflowServiceTestSuite) TestWorkflowWithRetriesTimersSignalsAndQueries() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	run, err := s.client.ExecuteWorkflow(ctx, StartWorkflowOptions{
+		ID:                       "greeting",
+		TaskQueue:                inMemoryTestTaskQueue,
+		WorkflowExecutionTimeout: time.Minute,
+	}, "greetingWorkflow", "Temporal")
+	s.NoError(err)
+
+	s.Eventually(func() bool {
+		value, err := s.client.QueryWorkflow(ctx, run.GetID(), run.GetRunID(), "status")
+		if err != nil {
+			return false
+		}
+		var status string
+		return value.Get(&status) == nil && status == "waiting for signal"
+	}, 20*time.Second, 100*time.Millisecond)
+
+	s.NoError(s.client.SignalWorkflow(ctx, run.GetID(), run.GetRunID(), "suffix", "!"))
+	var result string
+	s.NoError(run.Get(ctx, &result))
+	s.Equal("Hello Temporal!", result)
+
+	var attempts []int32
+	iter := s.client.GetWorkflowHistory(ctx, run.GetID(), run.GetRunID(), false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		s.NoError(err)
+		if event.GetEventType() == enumspb.EVENT_TYPE_ACTIVITY_TASK_STARTED {
+			attempts = append(attempts, event.GetActivityTaskStartedEventAttributes().GetAttempt())
+		}
+	}
+	s.Equal([]int32{2}, attempts)
+
+	description, err := s.client.DescribeWorkflowExecution(ctx, run.GetID(), run.GetRunID())
+	s.NoError(err)
+	s.Equal(enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED, description.GetWorkflowExecutionInfo().GetStatus())
+}
+
+func (s *InMemoryWorkflowServiceTestSuite) TestActivityStartToCloseTimeout() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	run, err := s.client.ExecuteWorkflow(ctx, StartWorkflowOptions{
+		ID:        "timeout",
+		TaskQueue: inMemoryTestTaskQueue,
+	}, "timeoutWorkflow")
+	s.NoError(err)
+
+	err = run.Get(ctx, nil)
+	var timeoutErr *TimeoutError
+	s.True(errors.As(err, &timeoutErr), "unexpected error: %v", err)
+	s.Equal(enumspb.TIMEOUT_TYPE_START_TO_CLOSE, timeoutErr.TimeoutType())
+}
+
+func (s *InMemoryWorkflowServiceTestSuite) TestStartWorkflowRejectsDuplicates() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	options := StartWorkflowOptions{ID: "duplicate", TaskQueue: inMemoryTestTaskQueue}
+	run, err := s.client.ExecuteWorkflow(ctx, options, "greetingWorkflow", "Temporal")
+	s.NoError(err)
+
+	_, err = s.client.StartWorkflow(ctx, options, "greetingWorkflow", "Temporal")
+	var alreadyStartedErr *serviceerror.WorkflowExecutionAlreadyStarted
+	s.True(errors.As(err, &alreadyStartedErr), "unexpected error: %v", err)
+	s.Equal(run.GetRunID(), alreadyStartedErr.RunId)
+
+	s.NoError(s.client.TerminateWorkflow(ctx, run.GetID(), run.GetRunID(), "done"))
+	err = run.Get(ctx, nil)
+	s.Error(err)
+	var terminatedErr *TerminatedError
+	s.True(errors.As(err, &terminatedErr), "unexpected error: %v", err)
+}
+
+func (s *InMemoryWorkflowServiceTestSuite) TestUnsupportedCommandFailsWorkflowTask() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	run, err := s.client.ExecuteWorkflow(ctx, StartWorkflowOptions{ID: "child", TaskQueue: inMemoryTestTaskQueue}, "childWorkflow")
+	s.NoError(err)
+	defer func() { s.NoError(s.client.TerminateWorkflow(ctx, run.GetID(), run.GetRunID(), "done")) }()
+
+	s.Eventually(func() bool {
+		iter := s.client.GetWorkflowHistory(ctx, run.GetID(), run.GetRunID(), false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+		for iter.HasNext() {
+			event, err := iter.Next()
+			s.NoError(err)
+			if event.GetEventType() == enumspb.EVENT_TYPE_WORKFLOW_TASK_FAILED {
+				attributes := event.GetWorkflowTaskFailedEventAttributes()
+				s.Equal(enumspb.WORKFLOW_TASK_FAILED_CAUSE_BAD_START_CHILD_EXECUTION_ATTRIBUTES, attributes.GetCause())
+				s.Equal("command StartChildWorkflowExecution is not supported by InMemoryWorkflowService", attributes.GetFailure().GetMessage())
+				return true
+			}
+		}
+		return false
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func (s *InMemoryWorkflowServiceTestSuite) TestUnsupportedRequests() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var unimplementedErr *serviceerror.Unimplemented
+	_, err := s.service.ResetWorkflowExecution(ctx, &workflowservice.ResetWorkflowExecutionRequest{})
+	s.True(errors.As(err, &unimplementedErr), "unexpected error: %v", err)
+	_, err = s.service.ListArchivedWorkflowExecutions(ctx, &workflowservice.ListArchivedWorkflowExecutionsRequest{})
+	s.True(errors.As(err, &unimplementedErr), "unexpected error: %v", err)
+	_, err = s.service.ListWorkflowExecutions(ctx, &workflowservice.ListWorkflowExecutionsRequest{Query: "WorkflowType = 'childWorkflow'"})
+	s.True(errors.As(err, &unimplementedErr), "unexpected error: %v", err)
+}
@@ -0,0 +1,121 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"sort"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"google.golang.org/grpc"
+)
+
+// ListOpenWorkflowExecutions implementation. Only execution and type filters are supported.
+func (s *InMemoryWorkflowService) ListOpenWorkflowExecutions(_ context.Context, request *workflowservice.ListOpenWorkflowExecutionsRequest, _ ...grpc.CallOption) (*workflowservice.ListOpenWorkflowExecutionsResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	executions := s.listExecutions(request.GetNamespace(), func(e *inMemoryExecution) bool {
+		return !e.isClosed() &&
+			matchInMemoryFilters(e, request.GetExecutionFilter().GetWorkflowId(), request.GetTypeFilter().GetName())
+	})
+	return &workflowservice.ListOpenWorkflowExecutionsResponse{Executions: executions}, nil
+}
+
+// ListClosedWorkflowExecutions implementation. Only execution, type and status filters are supported.
+func (s *InMemoryWorkflowService) ListClosedWorkflowExecutions(_ context.Context, request *workflowservice.ListClosedWorkflowExecutionsRequest, _ ...grpc.CallOption) (*workflowservice.ListClosedWorkflowExecutionsResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	executions := s.listExecutions(request.GetNamespace(), func(e *inMemoryExecution) bool {
+		return e.isClosed() &&
+			matchInMemoryFilters(e, request.GetExecutionFilter().GetWorkflowId(), request.GetTypeFilter().GetName()) &&
+			(request.GetStatusFilter() == nil || request.GetStatusFilter().GetStatus() == e.status)
+	})
+	return &workflowservice.ListClosedWorkflowExecutionsResponse{Executions: executions}, nil
+}
+
+// ListWorkflowExecutions implementation. Visibility queries are not supported, an empty query lists all executions.
+func (s *InMemoryWorkflowService) ListWorkflowExecutions(_ context.Context, request *workflowservice.ListWorkflowExecutionsRequest, _ ...grpc.CallOption) (*workflowservice.ListWorkflowExecutionsResponse, error) {
+	if request.GetQuery() != "" {
+		return nil, serviceerror.NewUnimplemented("visibility queries are not supported by InMemoryWorkflowService")
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return &workflowservice.ListWorkflowExecutionsResponse{Executions: s.listExecutions(request.GetNamespace(), nil)}, nil
+}
+
+// ListArchivedWorkflowExecutions is not supported by InMemoryWorkflowService.
+func (s *InMemoryWorkflowService) ListArchivedWorkflowExecutions(_ context.Context, _ *workflowservice.ListArchivedWorkflowExecutionsRequest, _ ...grpc.CallOption) (*workflowservice.ListArchivedWorkflowExecutionsResponse, error) {
+	return nil, serviceerror.NewUnimplemented("archival is not supported by InMemoryWorkflowService")
+}
+
+// ScanWorkflowExecutions implementation. Visibility queries are not supported, an empty query scans all executions.
+func (s *InMemoryWorkflowService) ScanWorkflowExecutions(_ context.Context, request *workflowservice.ScanWorkflowExecutionsRequest, _ ...grpc.CallOption) (*workflowservice.ScanWorkflowExecutionsResponse, error) {
+	if request.GetQuery() != "" {
+		return nil, serviceerror.NewUnimplemented("visibility queries are not supported by InMemoryWorkflowService")
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return &workflowservice.ScanWorkflowExecutionsResponse{Executions: s.listExecutions(request.GetNamespace(), nil)}, nil
+}
+
+// CountWorkflowExecutions implementation. Visibility queries are not supported, an empty query counts all executions.
+func (s *InMemoryWorkflowService) CountWorkflowExecutions(_ context.Context, request *workflowservice.CountWorkflowExecutionsRequest, _ ...grpc.CallOption) (*workflowservice.CountWorkflowExecutionsResponse, error) {
+	if request.GetQuery() != "" {
+		return nil, serviceerror.NewUnimplemented("visibility queries are not supported by InMemoryWorkflowService")
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return &workflowservice.CountWorkflowExecutionsResponse{Count: int64(len(s.listExecutions(request.GetNamespace(), nil)))}, nil
+}
+
+// GetSearchAttributes implementation
+func (s *InMemoryWorkflowService) GetSearchAttributes(_ context.Context, _ *workflowservice.GetSearchAttributesRequest, _ ...grpc.CallOption) (*workflowservice.GetSearchAttributesResponse, error) {
+	return &workflowservice.GetSearchAttributesResponse{Keys: map[string]enumspb.IndexedValueType{}}, nil
+}
+
+func (s *InMemoryWorkflowService) listExecutions(namespace string, filter func(e *inMemoryExecution) bool) []*workflowpb.WorkflowExecutionInfo {
+	var executions []*inMemoryExecution
+	for key, e := range s.executions {
+		if key.namespace == namespace && (filter == nil || filter(e)) {
+			executions = append(executions, e)
+		}
+	}
+	sort.Slice(executions, func(i, j int) bool {
+		return executions[i].startTime.After(executions[j].startTime)
+	})
+	result := make([]*workflowpb.WorkflowExecutionInfo, 0, len(executions))
+	for _, e := range executions {
+		result = append(result, e.info())
+	}
+	return result
+}
+
+func matchInMemoryFilters(e *inMemoryExecution, workflowID, workflowType string) bool {
+	return (workflowID == "" || e.execution.GetWorkflowId() == workflowID) &&
+		(workflowType == "" || e.workflowType.GetName() == workflowType)
+}
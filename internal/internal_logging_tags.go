@@ -43,4 +43,10 @@ const (
 	tagQueryType         = "QueryType"
 	tagResult            = "Result"
 	tagError             = "Error"
+	tagBlobReference     = "BlobReference"
+	tagPayloadOperation  = "PayloadOperation"
+	tagPayloadName       = "PayloadName"
+	tagPayloadIndex      = "PayloadIndex"
+	tagPayloadSize       = "PayloadSize"
+	tagPayloadSizeLimit  = "PayloadSizeLimit"
 )
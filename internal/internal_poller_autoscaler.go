@@ -0,0 +1,186 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"sync"
+
+	"github.com/uber-go/tally"
+
+	"go.temporal.io/sdk/internal/common/metrics"
+)
+
+// pollerAutoscaler decides how many of the poller goroutines of a worker poll at the same time.
+// The number of active pollers is grown when polls return tasks and the task queue reports a backlog, and shrunk
+// when long polls return without a task or fail. Pollers are never grown beyond the execution slots available
+// as a poller without a slot can't take a task anyway.
+type pollerAutoscaler struct {
+	minPollers int
+	maxPollers int
+	// freeSlots returns the number of execution slots not used by running tasks or active polls.
+	// It and metricsScope are bound by the base worker that runs the pollers.
+	freeSlots    func() int
+	metricsScope tally.Scope
+
+	lock   sync.Mutex
+	target int
+	// changed is closed and replaced every time target changes.
+	changed chan struct{}
+}
+
+func newPollerAutoscaler(minPollers, maxPollers int) *pollerAutoscaler {
+	if maxPollers < 1 {
+		maxPollers = 1
+	}
+	if minPollers < 1 {
+		minPollers = 1
+	}
+	if minPollers > maxPollers {
+		minPollers = maxPollers
+	}
+	return &pollerAutoscaler{
+		minPollers: minPollers,
+		maxPollers: maxPollers,
+		target:     minPollers,
+		changed:    make(chan struct{}),
+	}
+}
+
+// waitUntilActive blocks the poller with the given index until it is allowed to poll.
+// It returns false if stopCh is closed first or if the index is not below maxPollers anymore.
+func (pa *pollerAutoscaler) waitUntilActive(index int, stopCh <-chan struct{}) bool {
+	for {
+		pa.lock.Lock()
+		if index < pa.target {
+			pa.lock.Unlock()
+			return true
+		}
+		if index >= pa.maxPollers {
+			pa.lock.Unlock()
+			return false
+		}
+		changed := pa.changed
+		pa.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-stopCh:
+			return false
+		}
+	}
+}
+
+// recordTask is called when a poll returns a task. backlogCountHint is the backlog of the task queue reported by
+// the server, 0 if unknown.
+func (pa *pollerAutoscaler) recordTask(backlogCountHint int64) {
+	if pa == nil {
+		return
+	}
+	delta := 1
+	if backlogCountHint > 0 {
+		delta = 2
+	}
+	pa.adjust(delta)
+}
+
+// recordNoTask is called when a long poll returns without a task.
+func (pa *pollerAutoscaler) recordNoTask() {
+	if pa == nil {
+		return
+	}
+	pa.adjust(-1)
+}
+
+// recordError is called when a poll fails.
+func (pa *pollerAutoscaler) recordError() {
+	if pa == nil {
+		return
+	}
+	pa.adjust(-1)
+}
+
+// setMaxPollers changes the maximum number of active pollers, minPollers is lowered with it if needed.
+func (pa *pollerAutoscaler) setMaxPollers(maxPollers int) {
+	if maxPollers < 1 {
+		maxPollers = 1
+	}
+	pa.lock.Lock()
+	pa.maxPollers = maxPollers
+	if pa.minPollers > maxPollers {
+		pa.minPollers = maxPollers
+	}
+	// Wake up waiting pollers to let the ones above maxPollers exit.
+	close(pa.changed)
+	pa.changed = make(chan struct{})
+	pa.lock.Unlock()
+	pa.adjust(0)
+}
+
+func (pa *pollerAutoscaler) activePollers() int {
+	pa.lock.Lock()
+	defer pa.lock.Unlock()
+	return pa.target
+}
+
+func (pa *pollerAutoscaler) adjust(delta int) {
+	if delta > 0 && pa.freeSlots != nil && pa.freeSlots() == 0 {
+		return
+	}
+
+	pa.lock.Lock()
+	target := pa.target + delta
+	if target < pa.minPollers {
+		target = pa.minPollers
+	}
+	if target > pa.maxPollers {
+		target = pa.maxPollers
+	}
+	if target == pa.target {
+		pa.lock.Unlock()
+		return
+	}
+	pa.target = target
+	close(pa.changed)
+	pa.changed = make(chan struct{})
+	pa.lock.Unlock()
+
+	if pa.metricsScope != nil {
+		pa.metricsScope.Gauge(metrics.ActivePollersGauge).Update(float64(target))
+	}
+}
+
+func newWorkflowPollerAutoscaler(params workerExecutionParameters) *pollerAutoscaler {
+	if !params.EnablePollerAutoscaling {
+		return nil
+	}
+	return newPollerAutoscaler(params.MinConcurrentWorkflowTaskQueuePollers, params.MaxConcurrentWorkflowTaskQueuePollers)
+}
+
+func newActivityPollerAutoscaler(params workerExecutionParameters) *pollerAutoscaler {
+	if !params.EnablePollerAutoscaling {
+		return nil
+	}
+	return newPollerAutoscaler(params.MinConcurrentActivityTaskQueuePollers, params.MaxConcurrentActivityTaskQueuePollers)
+}
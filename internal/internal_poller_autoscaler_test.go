@@ -0,0 +1,132 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+
+	"go.temporal.io/sdk/internal/common/metrics"
+)
+
+func TestPollerAutoscaler(t *testing.T) {
+	var nilAutoscaler *pollerAutoscaler
+	nilAutoscaler.recordTask(10)
+	nilAutoscaler.recordNoTask()
+	nilAutoscaler.recordError()
+
+	scope := tally.NewTestScope("", nil)
+	freeSlots := 10
+	pa := newPollerAutoscaler(2, 5)
+	pa.freeSlots = func() int { return freeSlots }
+	pa.metricsScope = scope
+	require.Equal(t, 2, pa.activePollers())
+
+	pa.recordTask(0)
+	require.Equal(t, 3, pa.activePollers())
+	pa.recordTask(100)
+	require.Equal(t, 5, pa.activePollers())
+	pa.recordTask(100)
+	require.Equal(t, 5, pa.activePollers())
+	require.Equal(t, float64(5), scope.Snapshot().Gauges()[metrics.ActivePollersGauge+"+"].Value())
+
+	pa.recordNoTask()
+	require.Equal(t, 4, pa.activePollers())
+	pa.recordError()
+	require.Equal(t, 3, pa.activePollers())
+
+	// No free slots, a poller can't be used anyway.
+	freeSlots = 0
+	pa.recordTask(100)
+	require.Equal(t, 3, pa.activePollers())
+
+	pa.recordNoTask()
+	pa.recordNoTask()
+	pa.recordNoTask()
+	require.Equal(t, 2, pa.activePollers())
+	require.Equal(t, float64(2), scope.Snapshot().Gauges()[metrics.ActivePollersGauge+"+"].Value())
+}
+
+func TestPollerAutoscalerBounds(t *testing.T) {
+	pa := newPollerAutoscaler(0, 0)
+	require.Equal(t, 1, pa.minPollers)
+	require.Equal(t, 1, pa.maxPollers)
+
+	pa = newPollerAutoscaler(4, 2)
+	require.Equal(t, 2, pa.minPollers)
+	require.Equal(t, 2, pa.maxPollers)
+
+	require.Nil(t, newWorkflowPollerAutoscaler(workerExecutionParameters{MaxConcurrentWorkflowTaskQueuePollers: 2}))
+	pa = newActivityPollerAutoscaler(workerExecutionParameters{
+		EnablePollerAutoscaling:               true,
+		MinConcurrentActivityTaskQueuePollers: 1,
+		MaxConcurrentActivityTaskQueuePollers: 3,
+	})
+	require.Equal(t, 1, pa.minPollers)
+	require.Equal(t, 3, pa.maxPollers)
+}
+
+func TestPollerAutoscalerWaitUntilActive(t *testing.T) {
+	pa := newPollerAutoscaler(1, 3)
+	stopCh := make(chan struct{})
+	require.True(t, pa.waitUntilActive(0, stopCh))
+
+	activeC := make(chan bool)
+	go func() { activeC <- pa.waitUntilActive(2, stopCh) }()
+	select {
+	case <-activeC:
+		require.Fail(t, "poller must wait until it is active")
+	case <-time.After(50 * time.Millisecond):
+	}
+	pa.recordTask(100)
+	require.True(t, <-activeC)
+
+	pa.recordNoTask()
+	pa.recordNoTask()
+	go func() { activeC <- pa.waitUntilActive(1, stopCh) }()
+	close(stopCh)
+	require.False(t, <-activeC)
+}
+
+func TestBaseWorkerPollerAutoscaling(t *testing.T) {
+	poller := &testTaskPoller{pollDuration: time.Millisecond, autoscaler: newPollerAutoscaler(1, 5)}
+	worker := newTestBaseWorker(poller, baseWorkerOptions{
+		pollerCount:       5,
+		maxConcurrentTask: 10,
+		pollerAutoscaler:  poller.autoscaler},
+		tally.NoopScope,
+	)
+	require.NotNil(t, poller.autoscaler.freeSlots)
+
+	worker.Start()
+	time.Sleep(50 * time.Millisecond)
+	worker.Stop()
+
+	maxPolling, _ := poller.maxConcurrency()
+	require.Equal(t, 1, maxPolling)
+}
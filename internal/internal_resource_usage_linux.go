@@ -0,0 +1,292 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cgroupV1MemoryNoLimit is the lower bound of what cgroup v1 reports as memory limit when memory isn't limited.
+const cgroupV1MemoryNoLimit = 1 << 62
+
+// linuxResourceUsageReader reads resource usage of the cgroup of the process, and of the whole host from /proc
+// when the cgroup doesn't limit memory. The cgroup of the process is read from /proc/self/cgroup and looked up
+// under the cgroup mount root.
+type linuxResourceUsageReader struct {
+	cgroupRoot string
+	procRoot   string
+	now        func() time.Time
+
+	lastCPUBusy  float64
+	lastCPUTotal float64
+}
+
+func newResourceUsageReader() (resourceUsageReader, error) {
+	return &linuxResourceUsageReader{
+		cgroupRoot: "/sys/fs/cgroup",
+		procRoot:   "/proc",
+		now:        time.Now,
+	}, nil
+}
+
+func (r *linuxResourceUsageReader) read() (float64, float64, error) {
+	memoryUsage, err := r.readMemoryUsage()
+	if err != nil {
+		return 0, 0, err
+	}
+	busy, total, err := r.readCPUTime()
+	if err != nil {
+		return 0, 0, err
+	}
+	var cpuUsage float64
+	if r.lastCPUTotal > 0 && total > r.lastCPUTotal {
+		cpuUsage = (busy - r.lastCPUBusy) / (total - r.lastCPUTotal)
+	}
+	r.lastCPUBusy, r.lastCPUTotal = busy, total
+	return memoryUsage, cpuUsage, nil
+}
+
+// readMemoryUsage returns the working set of the cgroup relative to its limit. Inactive file cache is not counted
+// as used, the kernel reclaims it before the limit is hit.
+func (r *linuxResourceUsageReader) readMemoryUsage() (float64, error) {
+	// cgroup v2
+	dir := r.cgroupDir("")
+	used, err := readFloatFile(filepath.Join(dir, "memory.current"))
+	if err == nil {
+		// memory.max is "max" when memory isn't limited.
+		if limit, err := readFloatFile(filepath.Join(dir, "memory.max")); err == nil && limit > 0 {
+			return workingSet(used, readStatValue(filepath.Join(dir, "memory.stat"), "inactive_file")) / limit, nil
+		}
+	}
+	// cgroup v1
+	dir = r.cgroupDir("memory")
+	used, err = readFloatFile(filepath.Join(dir, "memory.usage_in_bytes"))
+	if err == nil {
+		limit, err := readFloatFile(filepath.Join(dir, "memory.limit_in_bytes"))
+		if err == nil && limit > 0 && limit < cgroupV1MemoryNoLimit {
+			return workingSet(used, readStatValue(filepath.Join(dir, "memory.stat"), "total_inactive_file")) / limit, nil
+		}
+	}
+	return r.readProcMemoryUsage()
+}
+
+// cgroupDir returns the directory of the cgroup of the process in the hierarchy of the cgroup v1 controller, or
+// in the cgroup v2 hierarchy when controller is empty. The mount root of the hierarchy is returned when the cgroup
+// isn't found there, which is the case in containers that mount their own cgroup as the root.
+func (r *linuxResourceUsageReader) cgroupDir(controller string) string {
+	root := filepath.Join(r.cgroupRoot, controller)
+	content, err := ioutil.ReadFile(filepath.Join(r.procRoot, "self", "cgroup"))
+	if err != nil {
+		return root
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path, the controller list is empty for cgroup v2.
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 || !hasCgroupController(fields[1], controller) {
+			continue
+		}
+		dir := filepath.Join(root, fields[2])
+		if _, err := os.Stat(dir); err != nil {
+			return root
+		}
+		return dir
+	}
+	return root
+}
+
+func hasCgroupController(controllers string, controller string) bool {
+	if controller == "" {
+		return controllers == ""
+	}
+	for _, c := range strings.Split(controllers, ",") {
+		if c == controller {
+			return true
+		}
+	}
+	return false
+}
+
+// readStatValue returns the value of the key in a file of "key value" lines like memory.stat, or 0 when it
+// can't be read.
+func readStatValue(path string, key string) float64 {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == key {
+			if value, err := strconv.ParseFloat(fields[1], 64); err == nil {
+				return value
+			}
+		}
+	}
+	return 0
+}
+
+func workingSet(used float64, inactiveFile float64) float64 {
+	if inactiveFile > used {
+		return 0
+	}
+	return used - inactiveFile
+}
+
+func (r *linuxResourceUsageReader) readProcMemoryUsage() (float64, error) {
+	content, err := ioutil.ReadFile(filepath.Join(r.procRoot, "meminfo"))
+	if err != nil {
+		return 0, err
+	}
+	var total, available float64
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total, err = strconv.ParseFloat(fields[1], 64)
+		case "MemAvailable:":
+			available, err = strconv.ParseFloat(fields[1], 64)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse meminfo: %w", err)
+		}
+	}
+	if total <= 0 {
+		return 0, fmt.Errorf("no MemTotal in meminfo")
+	}
+	return 1 - available/total, nil
+}
+
+// readCPUTime returns CPU time used by the process and CPU time available to it, in the same unit.
+// Only differences between two reads are meaningful.
+func (r *linuxResourceUsageReader) readCPUTime() (busy float64, total float64, err error) {
+	if usage, cpus, ok := r.readCgroupCPU(); ok {
+		return usage, float64(r.now().UnixNano()) * cpus, nil
+	}
+	return r.readProcCPUTime()
+}
+
+// readCgroupCPU returns CPU time used by the cgroup in nanoseconds and the number of CPUs available to it.
+func (r *linuxResourceUsageReader) readCgroupCPU() (usage float64, cpus float64, ok bool) {
+	cpus = float64(runtime.NumCPU())
+
+	// cgroup v2
+	dir := r.cgroupDir("")
+	if stat, err := ioutil.ReadFile(filepath.Join(dir, "cpu.stat")); err == nil {
+		for _, line := range strings.Split(string(stat), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && fields[0] == "usage_usec" {
+				if usec, err := strconv.ParseFloat(fields[1], 64); err == nil {
+					usage, ok = usec*1000, true
+				}
+			}
+		}
+		if !ok {
+			return 0, 0, false
+		}
+		// cpu.max is "$QUOTA $PERIOD" where quota is "max" when CPU isn't limited.
+		if cpuMax, err := readStringFile(filepath.Join(dir, "cpu.max")); err == nil {
+			fields := strings.Fields(cpuMax)
+			if len(fields) == 2 {
+				quota, err1 := strconv.ParseFloat(fields[0], 64)
+				period, err2 := strconv.ParseFloat(fields[1], 64)
+				if err1 == nil && err2 == nil && quota > 0 && period > 0 {
+					cpus = quota / period
+				}
+			}
+		}
+		return usage, cpus, true
+	}
+
+	// cgroup v1
+	usage, err := readFloatFile(filepath.Join(r.cgroupDir("cpuacct"), "cpuacct.usage"))
+	if err != nil {
+		return 0, 0, false
+	}
+	// cfs_quota_us is -1 when CPU isn't limited.
+	dir = r.cgroupDir("cpu")
+	quota, err1 := readFloatFile(filepath.Join(dir, "cpu.cfs_quota_us"))
+	period, err2 := readFloatFile(filepath.Join(dir, "cpu.cfs_period_us"))
+	if err1 == nil && err2 == nil && quota > 0 && period > 0 {
+		cpus = quota / period
+	}
+	return usage, cpus, true
+}
+
+// readProcCPUTime returns busy and total CPU time of the host in jiffies.
+func (r *linuxResourceUsageReader) readProcCPUTime() (busy float64, total float64, err error) {
+	content, err := ioutil.ReadFile(filepath.Join(r.procRoot, "stat"))
+	if err != nil {
+		return 0, 0, err
+	}
+	line := strings.SplitN(string(content), "\n", 2)[0]
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, 0, fmt.Errorf("unexpected stat format: %q", line)
+	}
+	// user nice system idle iowait irq softirq steal, guest time is already included in user and nice.
+	var idle float64
+	for i, field := range fields[1:] {
+		if i >= 8 {
+			break
+		}
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unable to parse stat: %w", err)
+		}
+		total += value
+		if i == 3 || i == 4 {
+			idle += value
+		}
+	}
+	return total - idle, total, nil
+}
+
+func readStringFile(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func readFloatFile(path string) (float64, error) {
+	content, err := readStringFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(content, 64)
+}
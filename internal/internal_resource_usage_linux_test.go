@@ -0,0 +1,128 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestLinuxResourceUsageReaderCgroupV2(t *testing.T) {
+	root, err := ioutil.TempDir("", "cgroup")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+	writeTestFiles(t, filepath.Join(root, "proc"), map[string]string{
+		"self/cgroup": "0::/system.slice/worker.service\n",
+	})
+	// The cgroup of the process, the files of the root cgroup are not read.
+	writeTestFiles(t, filepath.Join(root, "cgroup"), map[string]string{
+		"memory.current": "900\n",
+		"memory.max":     "max\n",
+		"system.slice/worker.service/memory.current": "500\n",
+		"system.slice/worker.service/memory.max":     "1000\n",
+		"system.slice/worker.service/memory.stat":    "anon 300\nfile 200\nactive_file 0\ninactive_file 200\n",
+		"system.slice/worker.service/cpu.stat":       "usage_usec 1000000\nuser_usec 800000\n",
+		"system.slice/worker.service/cpu.max":        "200000 100000\n",
+	})
+	now := time.Unix(100, 0)
+	r := &linuxResourceUsageReader{cgroupRoot: filepath.Join(root, "cgroup"), procRoot: filepath.Join(root, "proc"), now: func() time.Time { return now }}
+
+	memoryUsage, cpuUsage, err := r.read()
+	require.NoError(t, err)
+	require.Equal(t, 0.3, memoryUsage)
+	require.Equal(t, float64(0), cpuUsage)
+
+	// 1s of CPU time in 1s with 2 CPUs.
+	now = now.Add(time.Second)
+	writeTestFiles(t, filepath.Join(root, "cgroup"), map[string]string{"system.slice/worker.service/cpu.stat": "usage_usec 2000000\n"})
+	_, cpuUsage, err = r.read()
+	require.NoError(t, err)
+	require.InDelta(t, 0.5, cpuUsage, 0.0001)
+}
+
+func TestLinuxResourceUsageReaderCgroupV1(t *testing.T) {
+	root, err := ioutil.TempDir("", "cgroup")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+	writeTestFiles(t, filepath.Join(root, "proc"), map[string]string{
+		"self/cgroup": "5:cpu,cpuacct:/docker/worker\n4:memory:/docker/worker\n1:name=systemd:/docker/worker\n",
+	})
+	// The memory cgroup of the process is mounted as the root, like in a container without a cgroup namespace.
+	writeTestFiles(t, filepath.Join(root, "cgroup"), map[string]string{
+		"memory/memory.usage_in_bytes":        "600\n",
+		"memory/memory.limit_in_bytes":        "1000\n",
+		"memory/memory.stat":                  "cache 300\ninactive_file 100\ntotal_inactive_file 200\n",
+		"cpuacct/docker/worker/cpuacct.usage": "1000000000\n",
+		"cpu/docker/worker/cpu.cfs_quota_us":  "-1\n",
+		"cpu/docker/worker/cpu.cfs_period_us": "100000\n",
+	})
+	r := &linuxResourceUsageReader{cgroupRoot: filepath.Join(root, "cgroup"), procRoot: filepath.Join(root, "proc"), now: time.Now}
+
+	memoryUsage, err := r.readMemoryUsage()
+	require.NoError(t, err)
+	require.Equal(t, 0.4, memoryUsage)
+	usage, _, ok := r.readCgroupCPU()
+	require.True(t, ok)
+	require.Equal(t, float64(1000000000), usage)
+}
+
+func TestLinuxResourceUsageReaderProc(t *testing.T) {
+	root, err := ioutil.TempDir("", "proc")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+	writeTestFiles(t, filepath.Join(root, "cgroup"), map[string]string{
+		"memory.current": "300\n",
+		"memory.max":     "max\n",
+	})
+	writeTestFiles(t, filepath.Join(root, "proc"), map[string]string{
+		"meminfo": "MemTotal:       1000 kB\nMemFree:         100 kB\nMemAvailable:    250 kB\n",
+		"stat":    "cpu  100 0 100 700 100 0 0 0 0 0\ncpu0 100 0 100 700 100 0 0 0 0 0\n",
+	})
+	r := &linuxResourceUsageReader{cgroupRoot: filepath.Join(root, "cgroup"), procRoot: filepath.Join(root, "proc"), now: time.Now}
+
+	memoryUsage, _, err := r.read()
+	require.NoError(t, err)
+	require.Equal(t, 0.75, memoryUsage)
+
+	writeTestFiles(t, filepath.Join(root, "proc"), map[string]string{
+		"stat": "cpu  250 0 250 750 150 0 0 0 0 0\n",
+	})
+	_, cpuUsage, err := r.read()
+	require.NoError(t, err)
+	require.InDelta(t, 0.75, cpuUsage, 0.0001)
+}
@@ -121,19 +121,22 @@ type (
 
 	// workflowTaskHandlerImpl is the implementation of WorkflowTaskHandler
 	workflowTaskHandlerImpl struct {
-		namespace              string
-		metricsScope           *metrics.TaggedScope
-		ppMgr                  pressurePointMgr
-		logger                 log.Logger
-		identity               string
-		enableLoggingInReplay  bool
-		disableStickyExecution bool
-		registry               *registry
-		laTunnel               *localActivityTunnel
-		workflowPanicPolicy    WorkflowPanicPolicy
-		dataConverter          converter.DataConverter
-		contextPropagators     []ContextPropagator
-		tracer                 opentracing.Tracer
+		namespace                string
+		metricsScope             *metrics.TaggedScope
+		ppMgr                    pressurePointMgr
+		logger                   log.Logger
+		identity                 string
+		enableLoggingInReplay    bool
+		disableStickyExecution   bool
+		registry                 *registry
+		laTunnel                 *localActivityTunnel
+		workflowPanicPolicy      WorkflowPanicPolicy
+		dataConverter            converter.DataConverter
+		failureConverter         FailureConverter
+		payloadSizeChecker       *payloadSizeChecker
+		contextPropagators       []ContextPropagator
+		tracer                   opentracing.Tracer
+		deadlockDetectionTimeout time.Duration
 	}
 
 	activityProvider func(name string) activity
@@ -149,9 +152,12 @@ type (
 		registry           *registry
 		activityProvider   activityProvider
 		dataConverter      converter.DataConverter
+		failureConverter   FailureConverter
+		payloadSizeChecker *payloadSizeChecker
 		workerStopCh       <-chan struct{}
 		contextPropagators []ContextPropagator
 		tracer             opentracing.Tracer
+		interceptors       []ActivityInterceptor
 	}
 
 	// history wrapper method to help information about events.
@@ -377,18 +383,21 @@ func isPreloadMarkerEvent(event *historypb.HistoryEvent) bool {
 func newWorkflowTaskHandler(params workerExecutionParameters, ppMgr pressurePointMgr, registry *registry) WorkflowTaskHandler {
 	ensureRequiredParams(&params)
 	return &workflowTaskHandlerImpl{
-		namespace:              params.Namespace,
-		logger:                 params.Logger,
-		ppMgr:                  ppMgr,
-		metricsScope:           metrics.NewTaggedScope(params.MetricsScope),
-		identity:               params.Identity,
-		enableLoggingInReplay:  params.EnableLoggingInReplay,
-		disableStickyExecution: params.DisableStickyExecution,
-		registry:               registry,
-		workflowPanicPolicy:    params.WorkflowPanicPolicy,
-		dataConverter:          params.DataConverter,
-		contextPropagators:     params.ContextPropagators,
-		tracer:                 params.Tracer,
+		namespace:                params.Namespace,
+		logger:                   params.Logger,
+		ppMgr:                    ppMgr,
+		metricsScope:             metrics.NewTaggedScope(params.MetricsScope),
+		identity:                 params.Identity,
+		enableLoggingInReplay:    params.EnableLoggingInReplay,
+		disableStickyExecution:   params.DisableStickyExecution,
+		registry:                 registry,
+		workflowPanicPolicy:      params.WorkflowPanicPolicy,
+		dataConverter:            params.DataConverter,
+		failureConverter:         params.FailureConverter,
+		payloadSizeChecker:       newPayloadSizeChecker(params.PayloadLimits, params.Logger, params.MetricsScope),
+		contextPropagators:       params.ContextPropagators,
+		tracer:                   params.Tracer,
+		deadlockDetectionTimeout: params.DeadlockDetectionTimeout,
 	}
 }
 
@@ -398,6 +407,13 @@ var stickyCacheSize = defaultStickyCacheSize
 var initCacheOnce sync.Once
 var stickyCacheLock sync.Mutex
 
+// stickyCacheHits and stickyCacheMisses count what StickyCacheHit and StickyCacheMiss metrics count.
+var stickyCacheHits, stickyCacheMisses int64
+
+func getStickyCacheHitsAndMisses() (int64, int64) {
+	return atomic.LoadInt64(&stickyCacheHits), atomic.LoadInt64(&stickyCacheMisses)
+}
+
 // SetStickyWorkflowCacheSize sets the cache size for sticky workflow cache. Sticky workflow execution is the affinity
 // between workflow tasks of a specific workflow execution to a specific worker. The affinity is set if sticky execution
 // is enabled via Worker.Options (It is enabled by default unless disabled explicitly). The benefit of sticky execution
@@ -573,8 +589,11 @@ func (w *workflowExecutionContextImpl) createEventHandler() {
 		w.wth.metricsScope,
 		w.wth.registry,
 		w.wth.dataConverter,
+		w.wth.failureConverter,
+		w.wth.payloadSizeChecker,
 		w.wth.contextPropagators,
 		w.wth.tracer,
+		w.wth.deadlockDetectionTimeout,
 	)
 	w.eventHandler.Store(eventHandler)
 }
@@ -663,9 +682,11 @@ func (wth *workflowTaskHandlerImpl) getOrCreateWorkflowContext(
 		if task.Query != nil && !isFullHistory {
 			// query task and we have a valid cached state
 			metricsScope.Counter(metrics.StickyCacheHit).Inc(1)
+			atomic.AddInt64(&stickyCacheHits, 1)
 		} else if history.Events[0].GetEventId() == workflowContext.previousStartedEventID+1 {
 			// non query task and we have a valid cached state
 			metricsScope.Counter(metrics.StickyCacheHit).Inc(1)
+			atomic.AddInt64(&stickyCacheHits, 1)
 		} else {
 			// non query task and cached state is missing events, we need to discard the cached state and rebuild one.
 			_ = workflowContext.ResetIfStale(task, historyIterator)
@@ -675,6 +696,7 @@ func (wth *workflowTaskHandlerImpl) getOrCreateWorkflowContext(
 			// we are getting partial history task, but cached state was already evicted.
 			// we need to reset history so we get events from beginning to replay/rebuild the state
 			metricsScope.Counter(metrics.StickyCacheMiss).Inc(1)
+			atomic.AddInt64(&stickyCacheMisses, 1)
 			if _, err = resetHistory(task, historyIterator); err != nil {
 				return
 			}
@@ -1451,7 +1473,7 @@ func (wth *workflowTaskHandlerImpl) completeWorkflow(
 			tagRunID, task.WorkflowExecution.GetRunId(),
 			"PanicError", workflowPanicErr.Error(),
 			"PanicStack", workflowPanicErr.StackTrace())
-		return errorToFailWorkflowTask(task.TaskToken, workflowContext.err, wth.identity, wth.dataConverter)
+		return errorToFailWorkflowTask(task.TaskToken, workflowContext.err, wth.identity, wth.failureConverter)
 	}
 
 	// complete workflow task
@@ -1484,7 +1506,7 @@ func (wth *workflowTaskHandlerImpl) completeWorkflow(
 		// Workflow failures
 		metricsScope.Counter(metrics.WorkflowFailedCounter).Inc(1)
 		closeCommand = createNewCommand(enumspb.COMMAND_TYPE_FAIL_WORKFLOW_EXECUTION)
-		failure := convertErrorToFailure(workflowContext.err, wth.dataConverter)
+		failure := wth.failureConverter.ErrorToFailure(workflowContext.err)
 		closeCommand.Attributes = &commandpb.Command_FailWorkflowExecutionCommandAttributes{FailWorkflowExecutionCommandAttributes: &commandpb.FailWorkflowExecutionCommandAttributes{
 			Failure: failure,
 		}}
@@ -1534,11 +1556,11 @@ func (wth *workflowTaskHandlerImpl) completeWorkflow(
 	}
 }
 
-func errorToFailWorkflowTask(taskToken []byte, err error, identity string, dataConverter converter.DataConverter) *workflowservice.RespondWorkflowTaskFailedRequest {
+func errorToFailWorkflowTask(taskToken []byte, err error, identity string, failureConverter FailureConverter) *workflowservice.RespondWorkflowTaskFailedRequest {
 	return &workflowservice.RespondWorkflowTaskFailedRequest{
 		TaskToken:      taskToken,
 		Cause:          enumspb.WORKFLOW_TASK_FAILED_CAUSE_WORKFLOW_WORKER_UNHANDLED_FAILURE,
-		Failure:        convertErrorToFailure(err, dataConverter),
+		Failure:        failureConverter.ErrorToFailure(err),
 		Identity:       identity,
 		BinaryChecksum: getBinaryChecksum(),
 	}
@@ -1584,9 +1606,12 @@ func newActivityTaskHandlerWithCustomProvider(
 		registry:           registry,
 		activityProvider:   activityProvider,
 		dataConverter:      params.DataConverter,
+		failureConverter:   params.FailureConverter,
+		payloadSizeChecker: newPayloadSizeChecker(params.PayloadLimits, params.Logger, params.MetricsScope),
 		workerStopCh:       params.WorkerStopChannel,
 		contextPropagators: params.ContextPropagators,
 		tracer:             params.Tracer,
+		interceptors:       params.ActivityInterceptors,
 	}
 }
 
@@ -1777,7 +1802,8 @@ func (ath *activityTaskHandlerImpl) Execute(taskQueue string, t *workflowservice
 				"PanicStack", st)
 			metricsScope.Counter(metrics.ActivityTaskPanicCounter).Inc(1)
 			panicErr := newPanicError(p, st)
-			result, err = convertActivityResultToRespondRequest(ath.identity, t.TaskToken, nil, panicErr, ath.dataConverter), nil
+			result, err = convertActivityResultToRespondRequest(ath.identity, t.TaskToken, nil, panicErr,
+				dataConverterWithContext(ctx, ath.dataConverter), failureConverterWithContext(ctx, ath.failureConverter)), nil
 		}
 	}()
 
@@ -1795,6 +1821,11 @@ func (ath *activityTaskHandlerImpl) Execute(taskQueue string, t *workflowservice
 
 	ctx, span := createOpenTracingActivitySpan(ctx, ath.tracer, time.Now(), activityType, t.WorkflowExecution.GetWorkflowId(), t.WorkflowExecution.GetRunId())
 	defer span.Finish()
+
+	ctx, err = newActivityInterceptors(ctx, ath.interceptors)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize activity interceptors %v", err)
+	}
 	output, err := activityImplementation.Execute(ctx, t.Input)
 
 	dlCancelFunc()
@@ -1808,6 +1839,11 @@ func (ath *activityTaskHandlerImpl) Execute(taskQueue string, t *workflowservice
 		)
 		return nil, ctx.Err()
 	}
+	if err == nil {
+		if err = ath.payloadSizeChecker.check(output, payloadOperationActivityResult, activityType); err != nil {
+			output = nil
+		}
+	}
 	if err != nil && err != ErrActivityResultPending {
 		ath.logger.Error("Activity error.",
 			tagWorkflowID, t.WorkflowExecution.GetWorkflowId(),
@@ -1816,7 +1852,8 @@ func (ath *activityTaskHandlerImpl) Execute(taskQueue string, t *workflowservice
 			tagError, err,
 		)
 	}
-	return convertActivityResultToRespondRequest(ath.identity, t.TaskToken, output, err, ath.dataConverter), nil
+	return convertActivityResultToRespondRequest(ath.identity, t.TaskToken, output, err,
+		dataConverterWithContext(ctx, ath.dataConverter), failureConverterWithContext(ctx, ath.failureConverter)), nil
 }
 
 func (ath *activityTaskHandlerImpl) getActivity(name string) activity {
@@ -71,19 +71,25 @@ type (
 	// basePoller is the base class for all poller implementations
 	basePoller struct {
 		stopC <-chan struct{}
+		// autoscaler is nil unless poller autoscaling is enabled.
+		autoscaler *pollerAutoscaler
+		pause      *workerPauseController
 	}
 
 	// workflowTaskPoller implements polling/processing a workflow task
 	workflowTaskPoller struct {
 		basePoller
-		namespace     string
-		taskQueueName string
-		identity      string
-		service       workflowservice.WorkflowServiceClient
-		taskHandler   WorkflowTaskHandler
-		metricsScope  tally.Scope
-		logger        log.Logger
-		dataConverter converter.DataConverter
+		namespace        string
+		taskQueueName    string
+		identity         string
+		service          workflowservice.WorkflowServiceClient
+		taskHandler      WorkflowTaskHandler
+		metricsScope     tally.Scope
+		logger           log.Logger
+		dataConverter    converter.DataConverter
+		failureConverter FailureConverter
+		// blobCollector is nil unless BlobStore is set in worker options.
+		blobCollector *blobCollector
 
 		stickyUUID                   string
 		disableStickyExecution       bool
@@ -106,6 +112,10 @@ type (
 		metricsScope        *metrics.TaggedScope
 		logger              log.Logger
 		activitiesPerSecond float64
+		requestLock         sync.Mutex
+
+		runningLock       sync.Mutex
+		runningActivities map[*activityTask]RunningActivityStatus
 	}
 
 	historyIteratorImpl struct {
@@ -133,6 +143,7 @@ type (
 		dataConverter      converter.DataConverter
 		contextPropagators []ContextPropagator
 		tracer             opentracing.Tracer
+		interceptors       []ActivityInterceptor
 	}
 
 	localActivityResult struct {
@@ -192,10 +203,14 @@ func (bp *basePoller) stopping() bool {
 
 // doPoll runs the given pollFunc in a separate go routine. Returns when either of the conditions are met:
 // - poll succeeds, poll fails or worker is stopping
+// A paused worker doesn't start new polls, polls in flight complete so the tasks they return aren't lost.
 func (bp *basePoller) doPoll(pollFunc func(ctx context.Context) (interface{}, error)) (interface{}, error) {
 	if bp.stopping() {
 		return nil, errStop
 	}
+	if bp.pause.isPaused() {
+		return nil, errPaused
+	}
 
 	var err error
 	var result interface{}
@@ -220,8 +235,12 @@ func (bp *basePoller) doPoll(pollFunc func(ctx context.Context) (interface{}, er
 
 // newWorkflowTaskPoller creates a new workflow task poller which must have a one to one relationship to workflow worker
 func newWorkflowTaskPoller(taskHandler WorkflowTaskHandler, service workflowservice.WorkflowServiceClient, params workerExecutionParameters) *workflowTaskPoller {
+	var collector *blobCollector
+	if params.BlobStore != nil {
+		collector = newBlobCollector(service, params)
+	}
 	return &workflowTaskPoller{
-		basePoller:                   basePoller{stopC: params.WorkerStopChannel},
+		basePoller:                   basePoller{stopC: params.WorkerStopChannel, autoscaler: newWorkflowPollerAutoscaler(params), pause: params.PauseController},
 		service:                      service,
 		namespace:                    params.Namespace,
 		taskQueueName:                params.TaskQueue,
@@ -230,6 +249,8 @@ func newWorkflowTaskPoller(taskHandler WorkflowTaskHandler, service workflowserv
 		metricsScope:                 params.MetricsScope,
 		logger:                       params.Logger,
 		dataConverter:                params.DataConverter,
+		failureConverter:             params.FailureConverter,
+		blobCollector:                collector,
 		stickyUUID:                   uuid.New(),
 		disableStickyExecution:       params.DisableStickyExecution,
 		StickyScheduleToStartTimeout: params.StickyScheduleToStartTimeout,
@@ -310,6 +331,9 @@ func (wtp *workflowTaskPoller) processWorkflowTask(task *workflowTask) error {
 		if err != nil {
 			return err
 		}
+		if wtp.blobCollector != nil && isWorkflowClosedByRequest(completedRequest) {
+			wtp.blobCollector.collect(task.task.WorkflowExecution)
+		}
 
 		if response == nil || response.WorkflowTask == nil {
 			return nil
@@ -345,7 +369,7 @@ func (wtp *workflowTaskPoller) RespondTaskCompletedWithMetrics(completedRequest
 			tagRunID, task.WorkflowExecution.GetRunId(),
 			tagError, taskErr)
 		// convert err to WorkflowTaskFailed
-		completedRequest = errorToFailWorkflowTask(task.TaskToken, taskErr, wtp.identity, wtp.dataConverter)
+		completedRequest = errorToFailWorkflowTask(task.TaskToken, taskErr, wtp.identity, wtp.failureConverter)
 	} else {
 		wtp.metricsScope.Counter(metrics.WorkflowTaskCompletedCounter).Inc(1)
 	}
@@ -426,6 +450,7 @@ func newLocalActivityPoller(params workerExecutionParameters, laTunnel *localAct
 		dataConverter:      params.DataConverter,
 		contextPropagators: params.ContextPropagators,
 		tracer:             params.Tracer,
+		interceptors:       params.ActivityInterceptors,
 	}
 	return &localActivityTaskPoller{
 		basePoller:   basePoller{stopC: params.WorkerStopChannel},
@@ -502,6 +527,15 @@ func (lath *localActivityTaskHandler) executeLocalActivityTask(task *localActivi
 		}
 	}
 
+	ctx, err := newActivityInterceptors(ctx, lath.interceptors)
+	if err != nil {
+		return &localActivityResult{
+			task:   task,
+			result: nil,
+			err:    fmt.Errorf("unable to initialize activity interceptors %v", err),
+		}
+	}
+
 	// panic handler
 	defer func() {
 		if p := recover(); p != nil {
@@ -549,7 +583,6 @@ func (lath *localActivityTaskHandler) executeLocalActivityTask(task *localActivi
 	task.Unlock()
 
 	var laResult *commonpb.Payloads
-	var err error
 	doneCh := make(chan struct{})
 	go func(ch chan struct{}) {
 		laStartTime := time.Now()
@@ -676,16 +709,19 @@ func (wtp *workflowTaskPoller) poll(ctx context.Context) (interface{}, error) {
 			wtp.metricsScope.Counter(metrics.PollWorkflowQueueFailedCounter).Inc(1)
 		}
 		wtp.updateBacklog(request.TaskQueue.GetKind(), 0)
+		wtp.autoscaler.recordError()
 		return nil, err
 	}
 
 	if response == nil || len(response.TaskToken) == 0 {
 		wtp.metricsScope.Counter(metrics.PollWorkflowQueueNoTaskCounter).Inc(1)
 		wtp.updateBacklog(request.TaskQueue.GetKind(), 0)
+		wtp.autoscaler.recordNoTask()
 		return &workflowTask{}, nil
 	}
 
 	wtp.updateBacklog(request.TaskQueue.GetKind(), response.GetBacklogCountHint())
+	wtp.autoscaler.recordTask(response.GetBacklogCountHint())
 
 	task := wtp.toWorkflowTask(response)
 	traceLog(func() {
@@ -813,7 +849,7 @@ func newGetHistoryPageFunc(
 
 func newActivityTaskPoller(taskHandler ActivityTaskHandler, service workflowservice.WorkflowServiceClient, params workerExecutionParameters) *activityTaskPoller {
 	return &activityTaskPoller{
-		basePoller:          basePoller{stopC: params.WorkerStopChannel},
+		basePoller:          basePoller{stopC: params.WorkerStopChannel, autoscaler: newActivityPollerAutoscaler(params), pause: params.PauseController},
 		taskHandler:         taskHandler,
 		service:             service,
 		namespace:           params.Namespace,
@@ -822,6 +858,7 @@ func newActivityTaskPoller(taskHandler ActivityTaskHandler, service workflowserv
 		logger:              params.Logger,
 		metricsScope:        metrics.NewTaggedScope(params.MetricsScope),
 		activitiesPerSecond: params.TaskQueueActivitiesPerSecond,
+		runningActivities:   make(map[*activityTask]RunningActivityStatus),
 	}
 }
 
@@ -838,7 +875,7 @@ func (atp *activityTaskPoller) poll(ctx context.Context) (interface{}, error) {
 		Namespace:         atp.namespace,
 		TaskQueue:         &taskqueuepb.TaskQueue{Name: atp.taskQueueName, Kind: enumspb.TASK_QUEUE_KIND_NORMAL},
 		Identity:          atp.identity,
-		TaskQueueMetadata: &taskqueuepb.TaskQueueMetadata{MaxTasksPerSecond: &types.DoubleValue{Value: atp.activitiesPerSecond}},
+		TaskQueueMetadata: &taskqueuepb.TaskQueueMetadata{MaxTasksPerSecond: &types.DoubleValue{Value: atp.getActivitiesPerSecond()}},
 	}
 
 	response, err := atp.service.PollActivityTaskQueue(ctx, request)
@@ -848,12 +885,16 @@ func (atp *activityTaskPoller) poll(ctx context.Context) (interface{}, error) {
 		} else {
 			atp.metricsScope.Counter(metrics.ActivityPollFailedCounter).Inc(1)
 		}
+		atp.autoscaler.recordError()
 		return nil, err
 	}
 	if response == nil || len(response.TaskToken) == 0 {
 		atp.metricsScope.Counter(metrics.ActivityPollNoTaskCounter).Inc(1)
+		atp.autoscaler.recordNoTask()
 		return &activityTask{}, nil
 	}
+	// Activity poll responses don't carry a backlog hint.
+	atp.autoscaler.recordTask(0)
 
 	atp.metricsScope.Counter(metrics.ActivityPollSucceedCounter).Inc(1)
 	atp.metricsScope.Timer(metrics.ActivityPollLatency).Record(time.Since(startTime))
@@ -864,6 +905,51 @@ func (atp *activityTaskPoller) poll(ctx context.Context) (interface{}, error) {
 	return &activityTask{task: response, pollStartTime: startTime}, nil
 }
 
+func (atp *activityTaskPoller) getActivitiesPerSecond() float64 {
+	atp.requestLock.Lock()
+	defer atp.requestLock.Unlock()
+	return atp.activitiesPerSecond
+}
+
+// setActivitiesPerSecond changes the task queue rate limit sent to the server with the following polls.
+func (atp *activityTaskPoller) setActivitiesPerSecond(activitiesPerSecond float64) {
+	atp.requestLock.Lock()
+	defer atp.requestLock.Unlock()
+	atp.activitiesPerSecond = activitiesPerSecond
+}
+
+func (atp *activityTaskPoller) startRunning(activityTask *activityTask, startTime time.Time) {
+	task := activityTask.task
+	atp.runningLock.Lock()
+	defer atp.runningLock.Unlock()
+	atp.runningActivities[activityTask] = RunningActivityStatus{
+		ActivityID:   task.GetActivityId(),
+		ActivityType: task.ActivityType.GetName(),
+		TaskQueue:    atp.taskQueueName,
+		WorkflowID:   task.WorkflowExecution.GetWorkflowId(),
+		RunID:        task.WorkflowExecution.GetRunId(),
+		WorkflowType: task.WorkflowType.GetName(),
+		Attempt:      task.GetAttempt(),
+		StartTime:    startTime,
+	}
+}
+
+func (atp *activityTaskPoller) stopRunning(activityTask *activityTask) {
+	atp.runningLock.Lock()
+	defer atp.runningLock.Unlock()
+	delete(atp.runningActivities, activityTask)
+}
+
+func (atp *activityTaskPoller) getRunningActivities() []RunningActivityStatus {
+	atp.runningLock.Lock()
+	defer atp.runningLock.Unlock()
+	result := make([]RunningActivityStatus, 0, len(atp.runningActivities))
+	for _, a := range atp.runningActivities {
+		result = append(result, a)
+	}
+	return result
+}
+
 // PollTask polls a new task
 func (atp *activityTaskPoller) PollTask() (interface{}, error) {
 	// Get the task.
@@ -894,6 +980,8 @@ func (atp *activityTaskPoller) ProcessTask(task interface{}) error {
 	metricsScope := getMetricsScopeForActivity(atp.metricsScope, workflowType, activityType)
 
 	executionStartTime := time.Now()
+	atp.startRunning(activityTask, executionStartTime)
+	defer atp.stopRunning(activityTask)
 	// Process the activity task.
 	request, err := atp.taskHandler.Execute(atp.taskQueueName, activityTask.task)
 	if err != nil {
@@ -1027,7 +1115,7 @@ func reportActivityCompleteByID(ctx context.Context, service workflowservice.Wor
 }
 
 func convertActivityResultToRespondRequest(identity string, taskToken []byte, result *commonpb.Payloads, err error,
-	dataConverter converter.DataConverter) interface{} {
+	dataConverter converter.DataConverter, failureConverter FailureConverter) interface{} {
 	if err == ErrActivityResultPending {
 		// activity result is pending and will be completed asynchronously.
 		// nothing to report at this point
@@ -1056,12 +1144,12 @@ func convertActivityResultToRespondRequest(identity string, taskToken []byte, re
 
 	return &workflowservice.RespondActivityTaskFailedRequest{
 		TaskToken: taskToken,
-		Failure:   convertErrorToFailure(err, dataConverter),
+		Failure:   failureConverter.ErrorToFailure(err),
 		Identity:  identity}
 }
 
 func convertActivityResultToRespondRequestByID(identity, namespace, workflowID, runID, activityID string,
-	result *commonpb.Payloads, err error, dataConverter converter.DataConverter) interface{} {
+	result *commonpb.Payloads, err error, dataConverter converter.DataConverter, failureConverter FailureConverter) interface{} {
 	if err == ErrActivityResultPending {
 		// activity result is pending and will be completed asynchronously.
 		// nothing to report at this point
@@ -1103,6 +1191,6 @@ func convertActivityResultToRespondRequestByID(identity, namespace, workflowID,
 		WorkflowId: workflowID,
 		RunId:      runID,
 		ActivityId: activityID,
-		Failure:    convertErrorToFailure(err, dataConverter),
+		Failure:    failureConverter.ErrorToFailure(err),
 		Identity:   identity}
 }
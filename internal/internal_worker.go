@@ -68,6 +68,8 @@ const (
 	// Set to 2 pollers for now, can adjust later if needed. The typical RTT (round-trip time) is below 1ms within data
 	// center. And the poll API latency is about 5ms. With 2 poller, we could achieve around 300~400 RPS.
 	defaultConcurrentPollRoutineSize = 2
+	// Pollers autoscaling starts from and shrinks down to a single poller by default.
+	defaultMinConcurrentPollRoutineSize = 1
 
 	defaultMaxConcurrentActivityExecutionSize = 1000   // Large concurrent activity execution size (1k)
 	defaultWorkerActivitiesPerSecond          = 100000 // Large activity executions/sec (unlimited)
@@ -143,6 +145,10 @@ type (
 		// MaxConcurrentActivityTaskQueuePollers is the max number of pollers for activity task queue.
 		MaxConcurrentActivityTaskQueuePollers int
 
+		// MinConcurrentActivityTaskQueuePollers is the min number of pollers for activity task queue when
+		// EnablePollerAutoscaling is set.
+		MinConcurrentActivityTaskQueuePollers int
+
 		// Defines how many concurrent workflow task executions by this worker.
 		ConcurrentWorkflowTaskExecutionSize int
 
@@ -152,6 +158,16 @@ type (
 		// MaxConcurrentWorkflowTaskQueuePollers is the max number of pollers for workflow task queue.
 		MaxConcurrentWorkflowTaskQueuePollers int
 
+		// MinConcurrentWorkflowTaskQueuePollers is the min number of pollers for workflow task queue when
+		// EnablePollerAutoscaling is set.
+		MinConcurrentWorkflowTaskQueuePollers int
+
+		// EnablePollerAutoscaling scales the number of pollers between min and max, see WorkerOptions.
+		EnablePollerAutoscaling bool
+
+		// SlotSupplier decides when one more task can be executed, see WorkerOptions.
+		SlotSupplier SlotSupplier
+
 		// Defines how many concurrent local activity executions by this worker.
 		ConcurrentLocalActivityExecutionSize int
 
@@ -191,18 +207,34 @@ type (
 
 		DataConverter converter.DataConverter
 
+		FailureConverter FailureConverter
+
+		// PayloadLimits are limits of payload sizes checked before payloads are sent to the server.
+		PayloadLimits PayloadLimitOptions
+
 		// WorkerStopTimeout is the time delay before hard terminate worker
 		WorkerStopTimeout time.Duration
 
+		// DeadlockDetectionTimeout is the time a workflow coroutine can run without yielding.
+		DeadlockDetectionTimeout time.Duration
+
+		// BlobStore to delete blobs of closed workflow executions from.
+		BlobStore converter.BlobStore
+
 		// WorkerStopChannel is a read only channel listen on worker close. The worker will close the channel before exit.
 		WorkerStopChannel <-chan struct{}
 
+		// PauseController pauses and resumes all the pollers of the worker.
+		PauseController *workerPauseController
+
 		// SessionResourceID is a unique identifier of the resource the session will consume
 		SessionResourceID string
 
 		ContextPropagators []ContextPropagator
 
 		Tracer opentracing.Tracer
+
+		ActivityInterceptors []ActivityInterceptor
 	}
 )
 
@@ -228,6 +260,9 @@ func ensureRequiredParams(params *workerExecutionParameters) {
 		params.DataConverter = converter.GetDefaultDataConverter()
 		params.Logger.Info("No DataConverter configured for temporal worker. Use default one.")
 	}
+	if params.FailureConverter == nil {
+		params.FailureConverter = NewDefaultFailureConverter(DefaultFailureConverterOptions{DataConverter: params.DataConverter})
+	}
 }
 
 // verifyNamespaceExist does a DescribeNamespace operation on the specified namespace with backoff/retry
@@ -287,7 +322,10 @@ func newWorkflowTaskWorkerInternal(taskHandler WorkflowTaskHandler, service work
 		taskWorker:        poller,
 		identity:          params.Identity,
 		workerType:        "WorkflowWorker",
-		stopTimeout:       params.WorkerStopTimeout},
+		stopTimeout:       params.WorkerStopTimeout,
+		pollerAutoscaler:  poller.autoscaler,
+		slotSupplier:      params.SlotSupplier,
+		pauseController:   params.PauseController},
 		params.Logger,
 		params.MetricsScope,
 		nil,
@@ -310,7 +348,8 @@ func newWorkflowTaskWorkerInternal(taskHandler WorkflowTaskHandler, service work
 		taskWorker:        localActivityTaskPoller,
 		identity:          params.Identity,
 		workerType:        "LocalActivityWorker",
-		stopTimeout:       params.WorkerStopTimeout},
+		stopTimeout:       params.WorkerStopTimeout,
+		slotSupplier:      params.SlotSupplier},
 		params.Logger,
 		params.MetricsScope,
 		nil,
@@ -349,6 +388,24 @@ func (ww *workflowWorker) Stop() {
 	ww.worker.Stop()
 }
 
+func (ww *workflowWorker) updateOptions(options WorkerUpdateOptions) {
+	if options.MaxConcurrentWorkflowTaskExecutionSize > 0 {
+		ww.worker.updateMaxConcurrentTask(options.MaxConcurrentWorkflowTaskExecutionSize)
+	}
+	if options.WorkerWorkflowTasksPerSecond > 0 {
+		ww.worker.updateMaxTaskPerSecond(options.WorkerWorkflowTasksPerSecond)
+	}
+	if options.MaxConcurrentWorkflowTaskPollers > 0 {
+		ww.worker.updatePollerCount(options.MaxConcurrentWorkflowTaskPollers)
+	}
+	if options.MaxConcurrentLocalActivityExecutionSize > 0 {
+		ww.localActivityWorker.updateMaxConcurrentTask(options.MaxConcurrentLocalActivityExecutionSize)
+	}
+	if options.WorkerLocalActivitiesPerSecond > 0 {
+		ww.localActivityWorker.updateMaxTaskPerSecond(options.WorkerLocalActivitiesPerSecond)
+	}
+}
+
 func newSessionWorker(service workflowservice.WorkflowServiceClient, params workerExecutionParameters, overrides *workerOverrides, env *registry, maxConcurrentSessionExecutionSize int) *sessionWorker {
 	if params.Identity == "" {
 		params.Identity = getWorkerIdentity(params.TaskQueue)
@@ -393,6 +450,13 @@ func (sw *sessionWorker) Stop() {
 	sw.activityWorker.Stop()
 }
 
+func (sw *sessionWorker) updateOptions(options WorkerUpdateOptions) {
+	sw.activityWorker.updateOptions(options)
+	// Session creation always uses a single poller.
+	options.MaxConcurrentActivityTaskPollers = 0
+	sw.creationWorker.updateOptions(options)
+}
+
 func newActivityWorker(service workflowservice.WorkflowServiceClient, params workerExecutionParameters, overrides *workerOverrides, env *registry, sessionTokenBucket *sessionTokenBucket) *activityWorker {
 	workerStopChannel := make(chan struct{}, 1)
 	params.WorkerStopChannel = getReadOnlyChannel(workerStopChannel)
@@ -423,7 +487,10 @@ func newActivityTaskWorker(taskHandler ActivityTaskHandler, service workflowserv
 			identity:          workerParams.Identity,
 			workerType:        "ActivityWorker",
 			stopTimeout:       workerParams.WorkerStopTimeout,
-			userContextCancel: workerParams.UserContextCancel},
+			userContextCancel: workerParams.UserContextCancel,
+			pollerAutoscaler:  poller.autoscaler,
+			slotSupplier:      workerParams.SlotSupplier,
+			pauseController:   workerParams.PauseController},
 		workerParams.Logger,
 		workerParams.MetricsScope,
 		sessionTokenBucket,
@@ -455,6 +522,23 @@ func (aw *activityWorker) Stop() {
 	aw.worker.Stop()
 }
 
+func (aw *activityWorker) updateOptions(options WorkerUpdateOptions) {
+	if options.MaxConcurrentActivityExecutionSize > 0 {
+		aw.worker.updateMaxConcurrentTask(options.MaxConcurrentActivityExecutionSize)
+	}
+	if options.WorkerActivitiesPerSecond > 0 {
+		aw.worker.updateMaxTaskPerSecond(options.WorkerActivitiesPerSecond)
+	}
+	if options.MaxConcurrentActivityTaskPollers > 0 {
+		aw.worker.updatePollerCount(options.MaxConcurrentActivityTaskPollers)
+	}
+	if options.TaskQueueActivitiesPerSecond > 0 {
+		if poller, ok := aw.poller.(*activityTaskPoller); ok {
+			poller.setActivitiesPerSecond(options.TaskQueueActivitiesPerSecond)
+		}
+	}
+}
+
 type registry struct {
 	sync.Mutex
 	workflowFuncMap      map[string]interface{}
@@ -743,7 +827,7 @@ type workflowExecutor struct {
 
 func (we *workflowExecutor) Execute(ctx Context, input *commonpb.Payloads) (*commonpb.Payloads, error) {
 	var args []interface{}
-	dataConverter := getWorkflowEnvOptions(ctx).DataConverter
+	dataConverter := getDataConverterFromWorkflowContext(ctx)
 	fnType := reflect.TypeOf(we.fn)
 
 	decoded, err := decodeArgsToValues(dataConverter, fnType, input)
@@ -776,32 +860,28 @@ func (ae *activityExecutor) GetFunction() interface{} {
 
 func (ae *activityExecutor) Execute(ctx context.Context, input *commonpb.Payloads) (*commonpb.Payloads, error) {
 	fnType := reflect.TypeOf(ae.fn)
-	var args []reflect.Value
 	dataConverter := getDataConverterFromActivityCtx(ctx)
 
-	// activities optionally might not take context.
-	if fnType.NumIn() > 0 && isActivityContext(fnType.In(0)) {
-		args = append(args, reflect.ValueOf(ctx))
-	}
-
 	decoded, err := decodeArgs(dataConverter, fnType, input)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"unable to decode the activity function input payload with error: %w for function name: %v",
 			err, ae.name)
 	}
-	args = append(args, decoded...)
-
-	fnValue := reflect.ValueOf(ae.fn)
-	retValues := fnValue.Call(args)
-	return validateFunctionAndGetResults(ae.fn, retValues, dataConverter)
+	args := make([]interface{}, 0, len(decoded))
+	for _, arg := range decoded {
+		args = append(args, arg.Interface())
+	}
+	return ae.ExecuteWithActualArgs(ctx, args)
 }
 
 func (ae *activityExecutor) ExecuteWithActualArgs(ctx context.Context, actualArgs []interface{}) (*commonpb.Payloads, error) {
-	retValues := ae.executeWithActualArgsWithoutParseResult(ctx, actualArgs)
 	dataConverter := getDataConverterFromActivityCtx(ctx)
 
-	return validateFunctionAndGetResults(ae.fn, retValues, dataConverter)
+	envInterceptor := getActivityEnvironmentInterceptor(ctx)
+	envInterceptor.fn = ae.fn
+	results := envInterceptor.inboundInterceptor.ExecuteActivity(ctx, ae.name, actualArgs...)
+	return validateFunctionAndGetResults(ae.fn, results, dataConverter)
 }
 
 func (ae *activityExecutor) executeWithActualArgsWithoutParseResult(ctx context.Context, actualArgs []interface{}) []reflect.Value {
@@ -834,9 +914,9 @@ func getDataConverterFromActivityCtx(ctx context.Context) converter.DataConverte
 	}
 	info := ctx.Value(activityEnvContextKey).(*activityEnvironment)
 	if info.dataConverter == nil {
-		return converter.GetDefaultDataConverter()
+		return dataConverterWithContext(ctx, converter.GetDefaultDataConverter())
 	}
-	return info.dataConverter
+	return dataConverterWithContext(ctx, info.dataConverter)
 }
 
 // AggregatedWorker combines management of both workflowWorker and activityWorker worker lifecycle.
@@ -847,6 +927,7 @@ type AggregatedWorker struct {
 	logger         log.Logger
 	registry       *registry
 	stopC          chan struct{}
+	pause          *workerPauseController
 }
 
 // RegisterWorkflow registers workflow implementation with the AggregatedWorker
@@ -1017,6 +1098,64 @@ func (aw *AggregatedWorker) Stop() {
 	aw.logger.Info("Stopped Worker")
 }
 
+// Pause stops polling for new workflow tasks and activities while polls in flight complete and tasks already
+// polled are executed. Local activities scheduled by running workflow tasks keep executing. The worker stays
+// registered and keeps its sticky workflow cache. A worker paused before Start starts paused.
+func (aw *AggregatedWorker) Pause() {
+	if aw.pause.pause() {
+		aw.logger.Info("Paused Worker")
+	}
+}
+
+// Resume resumes polling of a paused worker.
+func (aw *AggregatedWorker) Resume() {
+	if aw.pause.resume() {
+		aw.logger.Info("Resumed Worker")
+	}
+}
+
+// UpdateOptions changes rate limits, concurrency limits and poller counts of the worker while it runs. Zero values
+// keep the current values. Tasks in flight are not affected.
+func (aw *AggregatedWorker) UpdateOptions(options WorkerUpdateOptions) error {
+	if err := validateWorkerUpdateOptions(options); err != nil {
+		return err
+	}
+	if !util.IsInterfaceNil(aw.workflowWorker) {
+		aw.workflowWorker.updateOptions(options)
+	}
+	if !util.IsInterfaceNil(aw.activityWorker) {
+		aw.activityWorker.updateOptions(options)
+	}
+	if !util.IsInterfaceNil(aw.sessionWorker) {
+		aw.sessionWorker.updateOptions(options)
+	}
+	aw.logger.Info("Updated Worker options", "Options", fmt.Sprintf("%+v", options))
+	return nil
+}
+
+func validateWorkerUpdateOptions(options WorkerUpdateOptions) error {
+	limits := []struct {
+		name  string
+		value float64
+	}{
+		{"MaxConcurrentActivityExecutionSize", float64(options.MaxConcurrentActivityExecutionSize)},
+		{"WorkerActivitiesPerSecond", options.WorkerActivitiesPerSecond},
+		{"MaxConcurrentLocalActivityExecutionSize", float64(options.MaxConcurrentLocalActivityExecutionSize)},
+		{"WorkerLocalActivitiesPerSecond", options.WorkerLocalActivitiesPerSecond},
+		{"TaskQueueActivitiesPerSecond", options.TaskQueueActivitiesPerSecond},
+		{"MaxConcurrentActivityTaskPollers", float64(options.MaxConcurrentActivityTaskPollers)},
+		{"MaxConcurrentWorkflowTaskExecutionSize", float64(options.MaxConcurrentWorkflowTaskExecutionSize)},
+		{"WorkerWorkflowTasksPerSecond", options.WorkerWorkflowTasksPerSecond},
+		{"MaxConcurrentWorkflowTaskPollers", float64(options.MaxConcurrentWorkflowTaskPollers)},
+	}
+	for _, limit := range limits {
+		if limit.value < 0 {
+			return fmt.Errorf("%s must not be negative: %v", limit.name, limit.value)
+		}
+	}
+	return nil
+}
+
 // WorkflowReplayer is used to replay workflow code from an event history
 type WorkflowReplayer struct {
 	registry *registry
@@ -1239,6 +1378,9 @@ func extractHistoryFromFile(jsonfileName string, lastEventID int64) (*historypb.
 func NewAggregatedWorker(client *WorkflowClient, taskQueue string, options WorkerOptions) *AggregatedWorker {
 	setClientDefaults(client)
 	setWorkerOptionsDefaults(&options)
+	if options.FailureConverter == nil {
+		options.FailureConverter = client.failureConverter
+	}
 	ctx := options.BackgroundActivityContext
 	if ctx == nil {
 		ctx = context.Background()
@@ -1251,11 +1393,15 @@ func NewAggregatedWorker(client *WorkflowClient, taskQueue string, options Worke
 		ConcurrentActivityExecutionSize:       options.MaxConcurrentActivityExecutionSize,
 		WorkerActivitiesPerSecond:             options.WorkerActivitiesPerSecond,
 		MaxConcurrentActivityTaskQueuePollers: options.MaxConcurrentActivityTaskPollers,
+		MinConcurrentActivityTaskQueuePollers: options.MinConcurrentActivityTaskPollers,
 		ConcurrentLocalActivityExecutionSize:  options.MaxConcurrentLocalActivityExecutionSize,
 		WorkerLocalActivitiesPerSecond:        options.WorkerLocalActivitiesPerSecond,
 		ConcurrentWorkflowTaskExecutionSize:   options.MaxConcurrentWorkflowTaskExecutionSize,
 		WorkerWorkflowTasksPerSecond:          options.WorkerWorkflowTasksPerSecond,
 		MaxConcurrentWorkflowTaskQueuePollers: options.MaxConcurrentWorkflowTaskPollers,
+		MinConcurrentWorkflowTaskQueuePollers: options.MinConcurrentWorkflowTaskPollers,
+		EnablePollerAutoscaling:               options.EnablePollerAutoscaling,
+		SlotSupplier:                          options.SlotSupplier,
 		Identity:                              client.identity,
 		MetricsScope:                          client.metricsScope,
 		Logger:                                client.logger,
@@ -1267,9 +1413,14 @@ func NewAggregatedWorker(client *WorkflowClient, taskQueue string, options Worke
 		TaskQueueActivitiesPerSecond:          options.TaskQueueActivitiesPerSecond,
 		WorkflowPanicPolicy:                   options.NonDeterministicWorkflowPolicy,
 		DataConverter:                         client.dataConverter,
+		FailureConverter:                      options.FailureConverter,
+		PayloadLimits:                         client.payloadLimits,
 		WorkerStopTimeout:                     options.WorkerStopTimeout,
+		DeadlockDetectionTimeout:              options.DeadlockDetectionTimeout,
+		BlobStore:                             options.BlobStore,
 		ContextPropagators:                    client.contextPropagators,
 		Tracer:                                client.tracer,
+		ActivityInterceptors:                  options.ActivityInterceptorChainFactories,
 	}
 
 	ensureRequiredParams(&workerParams)
@@ -1278,6 +1429,7 @@ func NewAggregatedWorker(client *WorkflowClient, taskQueue string, options Worke
 		tagTaskQueue, taskQueue,
 		tagWorkerID, workerParams.Identity,
 	)
+	workerParams.PauseController = newWorkerPauseController(tagScope(workerParams.MetricsScope, tagTaskQueue, taskQueue))
 
 	processTestTags(&options, &workerParams)
 
@@ -1315,6 +1467,7 @@ func NewAggregatedWorker(client *WorkflowClient, taskQueue string, options Worke
 		logger:         workerParams.Logger,
 		registry:       registry,
 		stopC:          make(chan struct{}),
+		pause:          workerParams.PauseController,
 	}
 }
 
@@ -1428,6 +1581,12 @@ func setWorkerOptionsDefaults(options *WorkerOptions) {
 	if options.MaxConcurrentWorkflowTaskPollers <= 0 {
 		options.MaxConcurrentWorkflowTaskPollers = defaultConcurrentPollRoutineSize
 	}
+	if options.MinConcurrentActivityTaskPollers <= 0 {
+		options.MinConcurrentActivityTaskPollers = defaultMinConcurrentPollRoutineSize
+	}
+	if options.MinConcurrentWorkflowTaskPollers <= 0 {
+		options.MinConcurrentWorkflowTaskPollers = defaultMinConcurrentPollRoutineSize
+	}
 	if options.MaxConcurrentLocalActivityExecutionSize == 0 {
 		options.MaxConcurrentLocalActivityExecutionSize = defaultMaxConcurrentLocalActivityExecutionSize
 	}
@@ -1443,6 +1602,9 @@ func setWorkerOptionsDefaults(options *WorkerOptions) {
 	if options.MaxConcurrentSessionExecutionSize == 0 {
 		options.MaxConcurrentSessionExecutionSize = defaultMaxConcurrentSessionExecutionSize
 	}
+	if options.DeadlockDetectionTimeout == 0 {
+		options.DeadlockDetectionTimeout = defaultDeadlockDetectionTimeout
+	}
 }
 
 // setClientDefaults should be needed only in unit tests.
@@ -1450,6 +1612,9 @@ func setClientDefaults(client *WorkflowClient) {
 	if client.dataConverter == nil {
 		client.dataConverter = converter.GetDefaultDataConverter()
 	}
+	if client.failureConverter == nil {
+		client.failureConverter = NewDefaultFailureConverter(DefaultFailureConverterOptions{DataConverter: client.dataConverter})
+	}
 	if client.namespace == "" {
 		client.namespace = DefaultNamespace
 	}
@@ -32,6 +32,7 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/uber-go/tally"
@@ -49,6 +50,9 @@ import (
 const (
 	retryPollOperationInitialInterval = 20 * time.Millisecond
 	retryPollOperationMaxInterval     = 10 * time.Second
+
+	// slotReservationRetryInterval is how long a poller waits before asking the slot supplier again.
+	slotReservationRetryInterval = 100 * time.Millisecond
 )
 
 var (
@@ -99,6 +103,8 @@ type (
 		GetContextPropagators() []ContextPropagator
 		UpsertSearchAttributes(attributes map[string]interface{}) error
 		GetRegistry() *registry
+		GetPayloadSizeChecker() *payloadSizeChecker
+		GetDeadlockDetectionTimeout() time.Duration
 	}
 
 	// WorkflowDefinitionFactory factory for creating WorkflowDefinition instances.
@@ -133,6 +139,12 @@ type (
 		workerType        string
 		stopTimeout       time.Duration
 		userContextCancel context.CancelFunc
+		// pollerAutoscaler limits how many of the pollerCount pollers poll at the same time, nil to run all of them.
+		pollerAutoscaler *pollerAutoscaler
+		// slotSupplier decides if one more of the maxConcurrentTask slots can be used, nil to use all of them.
+		slotSupplier SlotSupplier
+		// pauseController is shared by all the base workers of a worker, nil if the worker can't be paused.
+		pauseController *workerPauseController
 	}
 
 	// baseWorker that wraps worker activities.
@@ -152,6 +164,26 @@ type (
 		pollerRequestCh    chan struct{}
 		taskQueueCh        chan interface{}
 		sessionTokenBucket *sessionTokenBucket
+		// usedSlots is the number of slots reserved from slotSupplier.
+		usedSlots int32
+
+		// limitsLock guards pollerCount and maxConcurrentTask of options, pollers, tokensIssued and slotDebt as
+		// the limits can be updated while the worker runs.
+		limitsLock sync.Mutex
+		// pollers are the indexes of running pollers, nil until the worker is started.
+		pollers map[int]struct{}
+		// tokensIssued is set once the task dispatcher issued maxConcurrentTask poller request tokens.
+		tokensIssued bool
+		// slotDebt is the number of poller request tokens to drop after maxConcurrentTask was lowered.
+		slotDebt int
+
+		// runningTasks is the number of polled tasks being processed.
+		runningTasks int32
+		// statusLock guards the poll outcomes reported by status.
+		statusLock        sync.Mutex
+		lastPollTime      time.Time
+		lastPollError     error
+		lastPollErrorTime time.Time
 	}
 
 	polledTask struct {
@@ -190,6 +222,10 @@ func newBaseWorker(options baseWorkerOptions, logger log.Logger, metricsScope ta
 	if options.pollerRate > 0 {
 		bw.pollLimiter = rate.NewLimiter(rate.Limit(options.pollerRate), 1)
 	}
+	if options.pollerAutoscaler != nil {
+		options.pollerAutoscaler.freeSlots = func() int { return len(bw.pollerRequestCh) }
+		options.pollerAutoscaler.metricsScope = bw.metricsScope
+	}
 
 	return bw
 }
@@ -202,10 +238,12 @@ func (bw *baseWorker) Start() {
 
 	bw.metricsScope.Counter(metrics.WorkerStartCounter).Inc(1)
 
+	bw.limitsLock.Lock()
+	bw.pollers = make(map[int]struct{})
 	for i := 0; i < bw.options.pollerCount; i++ {
-		bw.stopWG.Add(1)
-		go bw.runPoller()
+		bw.startPollerLocked(i)
 	}
+	bw.limitsLock.Unlock()
 
 	bw.stopWG.Add(1)
 	go bw.runTaskDispatcher()
@@ -229,15 +267,57 @@ func (bw *baseWorker) isStop() bool {
 	}
 }
 
-func (bw *baseWorker) runPoller() {
+// callers MUST hold limitsLock
+func (bw *baseWorker) startPollerLocked(index int) {
+	if _, ok := bw.pollers[index]; ok {
+		return
+	}
+	bw.pollers[index] = struct{}{}
+	bw.stopWG.Add(1)
+	go bw.runPoller(index)
+}
+
+// retirePoller returns true if the poller with the given index must exit after pollerCount was lowered.
+func (bw *baseWorker) retirePoller(index int) bool {
+	bw.limitsLock.Lock()
+	defer bw.limitsLock.Unlock()
+	if index < bw.options.pollerCount {
+		return false
+	}
+	delete(bw.pollers, index)
+	return true
+}
+
+func (bw *baseWorker) runPoller(index int) {
 	defer bw.stopWG.Done()
 	bw.metricsScope.Counter(metrics.PollerStartCounter).Inc(1)
 
 	for {
+		if bw.retirePoller(index) {
+			return
+		}
+		if bw.options.pollerAutoscaler != nil && !bw.options.pollerAutoscaler.waitUntilActive(index, bw.stopCh) {
+			if bw.isStop() {
+				return
+			}
+			// pollerCount was lowered, the poller is retired at the top of the loop.
+			continue
+		}
 		select {
 		case <-bw.stopCh:
 			return
 		case <-bw.pollerRequestCh:
+			if bw.retirePoller(index) {
+				bw.returnSlotToken()
+				return
+			}
+			// A paused poller holds on to its token until the worker is resumed.
+			if !bw.options.pauseController.waitUntilResumed(bw.stopCh) {
+				return
+			}
+			if !bw.reserveSlot() {
+				return
+			}
 			if bw.sessionTokenBucket != nil {
 				bw.sessionTokenBucket.waitForAvailableToken()
 			}
@@ -249,8 +329,12 @@ func (bw *baseWorker) runPoller() {
 func (bw *baseWorker) runTaskDispatcher() {
 	defer bw.stopWG.Done()
 
-	for i := 0; i < bw.options.maxConcurrentTask; i++ {
-		bw.pollerRequestCh <- struct{}{}
+	bw.limitsLock.Lock()
+	bw.tokensIssued = true
+	maxConcurrentTask := bw.options.maxConcurrentTask
+	bw.limitsLock.Unlock()
+	for i := 0; i < maxConcurrentTask; i++ {
+		bw.returnSlotToken()
 	}
 
 	for {
@@ -278,6 +362,12 @@ func (bw *baseWorker) pollTask() {
 	bw.retrier.Throttle()
 	if bw.pollLimiter == nil || bw.pollLimiter.Wait(bw.limiterContext) == nil {
 		task, err = bw.options.taskWorker.PollTask()
+		bw.recordPoll(err)
+		if err == errPaused {
+			// Not a poll failure, the poller waits until the worker is resumed.
+			bw.releaseSlot()
+			return
+		}
 		if err != nil && enableVerboseLogging {
 			bw.logger.Debug("Failed to poll for task.", tagError, err)
 		}
@@ -303,10 +393,165 @@ func (bw *baseWorker) pollTask() {
 		case <-bw.stopCh:
 		}
 	} else {
-		bw.pollerRequestCh <- struct{}{} // poll failed, trigger a new poll
+		bw.releaseSlot() // poll failed, trigger a new poll
+	}
+}
+
+// reserveSlot blocks until the slot supplier gives a slot. It returns false if the worker is stopped first.
+func (bw *baseWorker) reserveSlot() bool {
+	if bw.options.slotSupplier == nil {
+		return true
+	}
+	for {
+		info := SlotInfo{WorkerType: bw.options.workerType, UsedSlots: int(atomic.LoadInt32(&bw.usedSlots))}
+		if bw.options.slotSupplier.TryReserveSlot(info) {
+			usedSlots := atomic.AddInt32(&bw.usedSlots, 1)
+			bw.metricsScope.Gauge(metrics.WorkerTaskSlotsUsedGauge).Update(float64(usedSlots))
+			return true
+		}
+		bw.metricsScope.Counter(metrics.WorkerTaskSlotsDeniedCounter).Inc(1)
+
+		select {
+		case <-bw.stopCh:
+			return false
+		case <-time.After(slotReservationRetryInterval):
+		}
 	}
 }
 
+// releaseSlot releases a slot to the slot supplier and lets a poller use it.
+func (bw *baseWorker) releaseSlot() {
+	if bw.options.slotSupplier != nil {
+		usedSlots := atomic.AddInt32(&bw.usedSlots, -1)
+		bw.metricsScope.Gauge(metrics.WorkerTaskSlotsUsedGauge).Update(float64(usedSlots))
+		bw.options.slotSupplier.ReleaseSlot(SlotInfo{WorkerType: bw.options.workerType, UsedSlots: int(usedSlots)})
+	}
+	bw.returnSlotToken()
+}
+
+// returnSlotToken gives a poller request token back to pollers unless it is dropped after maxConcurrentTask was
+// lowered.
+func (bw *baseWorker) returnSlotToken() {
+	bw.limitsLock.Lock()
+	if bw.slotDebt > 0 {
+		bw.slotDebt--
+		bw.limitsLock.Unlock()
+		return
+	}
+	bw.limitsLock.Unlock()
+
+	select {
+	case bw.pollerRequestCh <- struct{}{}:
+	default:
+		// The buffer only fits the initial maxConcurrentTask tokens, the rest wait for a poller to take one.
+		go func() {
+			select {
+			case bw.pollerRequestCh <- struct{}{}:
+			case <-bw.stopCh:
+			}
+		}()
+	}
+}
+
+// updatePollerCount starts or retires pollers. Retired pollers exit before their next poll.
+func (bw *baseWorker) updatePollerCount(pollerCount int) {
+	bw.limitsLock.Lock()
+	defer bw.limitsLock.Unlock()
+	bw.options.pollerCount = pollerCount
+	if bw.options.pollerAutoscaler != nil {
+		bw.options.pollerAutoscaler.setMaxPollers(pollerCount)
+	}
+	if bw.pollers == nil || bw.isStop() {
+		return
+	}
+	for i := 0; i < pollerCount; i++ {
+		bw.startPollerLocked(i)
+	}
+}
+
+// updateMaxConcurrentTask changes the number of tasks processed at the same time. Tasks in flight are not affected
+// when it is lowered, their slots are removed when they complete.
+func (bw *baseWorker) updateMaxConcurrentTask(maxConcurrentTask int) {
+	bw.limitsLock.Lock()
+	delta := maxConcurrentTask - bw.options.maxConcurrentTask
+	bw.options.maxConcurrentTask = maxConcurrentTask
+	if !bw.tokensIssued {
+		bw.limitsLock.Unlock()
+		return
+	}
+	if delta < 0 {
+		bw.slotDebt -= delta
+	drainIdleTokens:
+		for bw.slotDebt > 0 {
+			select {
+			case <-bw.pollerRequestCh:
+				bw.slotDebt--
+			default:
+				break drainIdleTokens
+			}
+		}
+		bw.limitsLock.Unlock()
+		return
+	}
+	for delta > 0 && bw.slotDebt > 0 {
+		bw.slotDebt--
+		delta--
+	}
+	bw.limitsLock.Unlock()
+
+	for i := 0; i < delta; i++ {
+		bw.returnSlotToken()
+	}
+}
+
+func (bw *baseWorker) recordPoll(err error) {
+	if err == errStop || err == errPaused {
+		return
+	}
+	bw.statusLock.Lock()
+	defer bw.statusLock.Unlock()
+	bw.lastPollTime = time.Now()
+	if err != nil {
+		bw.lastPollError = err
+		bw.lastPollErrorTime = bw.lastPollTime
+	}
+}
+
+func (bw *baseWorker) status(taskQueue string) TaskWorkerStatus {
+	status := TaskWorkerStatus{
+		WorkerType:       bw.options.workerType,
+		TaskQueue:        taskQueue,
+		UsedSlots:        int(atomic.LoadInt32(&bw.runningTasks)),
+		MaxTaskPerSecond: float64(bw.taskLimiter.Limit()),
+	}
+
+	bw.limitsLock.Lock()
+	status.Started = bw.pollers != nil && !bw.isStop()
+	status.MaxPollers = bw.options.pollerCount
+	status.RunningPollers = len(bw.pollers)
+	status.AvailableSlots = bw.options.maxConcurrentTask - status.UsedSlots
+	bw.limitsLock.Unlock()
+	if status.AvailableSlots < 0 {
+		status.AvailableSlots = 0
+	}
+	status.ActivePollers = status.MaxPollers
+	if bw.options.pollerAutoscaler != nil {
+		status.ActivePollers = bw.options.pollerAutoscaler.activePollers()
+	}
+
+	bw.statusLock.Lock()
+	status.LastPollTime = bw.lastPollTime
+	status.LastPollError = bw.lastPollError
+	status.LastPollErrorTime = bw.lastPollErrorTime
+	bw.statusLock.Unlock()
+	return status
+}
+
+// updateMaxTaskPerSecond changes the rate limit of task processing.
+func (bw *baseWorker) updateMaxTaskPerSecond(maxTaskPerSecond float64) {
+	bw.taskLimiter.SetLimit(rate.Limit(maxTaskPerSecond))
+}
+
 func isNonRetriableError(err error) bool {
 	if err == nil {
 		return false
@@ -326,6 +571,8 @@ func (bw *baseWorker) processTask(task interface{}) {
 	polledTask, isPolledTask := task.(*polledTask)
 	if isPolledTask {
 		task = polledTask.task
+		atomic.AddInt32(&bw.runningTasks, 1)
+		defer atomic.AddInt32(&bw.runningTasks, -1)
 	}
 	defer func() {
 		if p := recover(); p != nil {
@@ -338,7 +585,7 @@ func (bw *baseWorker) processTask(task interface{}) {
 		}
 
 		if isPolledTask {
-			bw.pollerRequestCh <- struct{}{}
+			bw.releaseSlot()
 		}
 	}()
 	err := bw.options.taskWorker.ProcessTask(task)
@@ -0,0 +1,143 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uber-go/tally"
+
+	ilog "go.temporal.io/sdk/internal/log"
+)
+
+// testTaskPoller is a configurable taskPoller for base worker tests. It counts polls and processed tasks and keeps
+// the highest number of concurrent polls and task executions.
+type testTaskPoller struct {
+	// task is returned by every poll, polls return no task when nil.
+	task interface{}
+	// pollErrors are returned by the first polls, one per poll.
+	pollErrors []error
+	// pollDuration and processDuration are how long a poll and a task execution block.
+	pollDuration    time.Duration
+	processDuration time.Duration
+	// autoscaler records polls which returned no task when set, like the pollers of the worker do.
+	autoscaler *pollerAutoscaler
+
+	lock          sync.Mutex
+	polls         int
+	polling       int
+	maxPolling    int
+	processed     int
+	processing    int
+	maxProcessing int
+}
+
+func (p *testTaskPoller) PollTask() (interface{}, error) {
+	p.lock.Lock()
+	p.polls++
+	var err error
+	if p.polls <= len(p.pollErrors) {
+		err = p.pollErrors[p.polls-1]
+	}
+	p.polling++
+	if p.polling > p.maxPolling {
+		p.maxPolling = p.polling
+	}
+	p.lock.Unlock()
+
+	time.Sleep(p.pollDuration)
+
+	p.lock.Lock()
+	p.polling--
+	p.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if p.task == nil && p.autoscaler != nil {
+		p.autoscaler.recordNoTask()
+	}
+	return p.task, nil
+}
+
+func (p *testTaskPoller) ProcessTask(interface{}) error {
+	p.lock.Lock()
+	p.processing++
+	if p.processing > p.maxProcessing {
+		p.maxProcessing = p.processing
+	}
+	p.lock.Unlock()
+
+	time.Sleep(p.processDuration)
+
+	p.lock.Lock()
+	p.processing--
+	p.processed++
+	p.lock.Unlock()
+	return nil
+}
+
+// counts returns the number of polls and of processed tasks.
+func (p *testTaskPoller) counts() (int, int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.polls, p.processed
+}
+
+// maxConcurrency returns the highest number of concurrent polls and of concurrent task executions.
+func (p *testTaskPoller) maxConcurrency() (int, int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.maxPolling, p.maxProcessing
+}
+
+// resetMaxConcurrency restarts counting the highest concurrency from the current one.
+func (p *testTaskPoller) resetMaxConcurrency() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.maxPolling = p.polling
+	p.maxProcessing = p.processing
+}
+
+// newTestBaseWorker creates a base worker polling from the given poller. Options left unset get test defaults.
+func newTestBaseWorker(poller taskPoller, options baseWorkerOptions, metricsScope tally.Scope) *baseWorker {
+	options.taskWorker = poller
+	if options.pollerCount == 0 {
+		options.pollerCount = 1
+	}
+	if options.maxConcurrentTask == 0 {
+		options.maxConcurrentTask = 1
+	}
+	if options.maxTaskPerSecond == 0 {
+		options.maxTaskPerSecond = 1000
+	}
+	if options.workerType == "" {
+		options.workerType = "TestWorker"
+	}
+	if options.stopTimeout == 0 {
+		options.stopTimeout = time.Second
+	}
+	return newBaseWorker(options, ilog.NewNopLogger(), metricsScope, nil)
+}
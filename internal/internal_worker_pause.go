@@ -0,0 +1,108 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/uber-go/tally"
+
+	"go.temporal.io/sdk/internal/common/metrics"
+)
+
+var errPaused = errors.New("worker paused")
+
+// workerPauseController pauses and resumes all the pollers of a worker. Paused pollers don't start new polls, while
+// polls in flight complete and the tasks they return are processed. A nil controller is never paused.
+type workerPauseController struct {
+	metricsScope tally.Scope
+
+	lock   sync.Mutex
+	paused bool
+	// resumedC is closed while not paused.
+	resumedC chan struct{}
+}
+
+func newWorkerPauseController(metricsScope tally.Scope) *workerPauseController {
+	resumedC := make(chan struct{})
+	close(resumedC)
+	return &workerPauseController{
+		metricsScope: metricsScope,
+		resumedC:     resumedC,
+	}
+}
+
+// pause returns false if already paused.
+func (pc *workerPauseController) pause() bool {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+	if pc.paused {
+		return false
+	}
+	pc.paused = true
+	pc.resumedC = make(chan struct{})
+	pc.metricsScope.Gauge(metrics.WorkerPausedGauge).Update(1)
+	return true
+}
+
+// resume returns false if not paused.
+func (pc *workerPauseController) resume() bool {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+	if !pc.paused {
+		return false
+	}
+	pc.paused = false
+	close(pc.resumedC)
+	pc.metricsScope.Gauge(metrics.WorkerPausedGauge).Update(0)
+	return true
+}
+
+func (pc *workerPauseController) isPaused() bool {
+	if pc == nil {
+		return false
+	}
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+	return pc.paused
+}
+
+// waitUntilResumed blocks while the worker is paused. It returns false if stopCh is closed first.
+func (pc *workerPauseController) waitUntilResumed(stopCh <-chan struct{}) bool {
+	if pc == nil {
+		return true
+	}
+	pc.lock.Lock()
+	resumedC := pc.resumedC
+	pc.lock.Unlock()
+
+	select {
+	case <-resumedC:
+		return true
+	case <-stopCh:
+		return false
+	}
+}
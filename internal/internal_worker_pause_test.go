@@ -0,0 +1,181 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+
+	"go.temporal.io/sdk/internal/common/metrics"
+	ilog "go.temporal.io/sdk/internal/log"
+)
+
+func TestWorkerPauseController(t *testing.T) {
+	var nilController *workerPauseController
+	require.False(t, nilController.isPaused())
+	require.True(t, nilController.waitUntilResumed(nil))
+
+	scope := tally.NewTestScope("", nil)
+	pc := newWorkerPauseController(scope)
+	stopCh := make(chan struct{})
+	require.True(t, pc.waitUntilResumed(stopCh))
+	require.False(t, pc.resume())
+
+	require.True(t, pc.pause())
+	require.False(t, pc.pause())
+	require.True(t, pc.isPaused())
+	require.Equal(t, float64(1), scope.Snapshot().Gauges()[metrics.WorkerPausedGauge+"+"].Value())
+
+	resumedC := make(chan bool)
+	go func() { resumedC <- pc.waitUntilResumed(stopCh) }()
+	select {
+	case <-resumedC:
+		require.Fail(t, "paused worker must wait until resumed")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.True(t, pc.resume())
+	require.True(t, <-resumedC)
+	require.False(t, pc.isPaused())
+	require.Equal(t, float64(0), scope.Snapshot().Gauges()[metrics.WorkerPausedGauge+"+"].Value())
+
+	pc.pause()
+	go func() { resumedC <- pc.waitUntilResumed(stopCh) }()
+	close(stopCh)
+	require.False(t, <-resumedC)
+}
+
+func TestBasePollerPauseCompletesPoll(t *testing.T) {
+	pc := newWorkerPauseController(tally.NoopScope)
+	bp := &basePoller{stopC: make(chan struct{}), pause: pc}
+
+	// A poll in flight is not cancelled, the task it returns would be lost.
+	result, err := bp.doPoll(func(ctx context.Context) (interface{}, error) {
+		pc.pause()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+			return "task", nil
+		}
+	})
+	require.NoError(t, err)
+	require.Equal(t, "task", result)
+
+	_, err = bp.doPoll(func(ctx context.Context) (interface{}, error) {
+		require.Fail(t, "paused poller must not poll")
+		return nil, nil
+	})
+	require.Equal(t, errPaused, err)
+}
+
+func TestBaseWorkerPause(t *testing.T) {
+	pc := newWorkerPauseController(tally.NoopScope)
+	poller := &testTaskPoller{task: struct{}{}, processDuration: 100 * time.Millisecond}
+	worker := newTestBaseWorker(poller, baseWorkerOptions{pauseController: pc}, tally.NoopScope)
+	worker.Start()
+	defer worker.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+	pc.pause()
+	// The task polled before pause is processed.
+	time.Sleep(300 * time.Millisecond)
+	polls, processed := poller.counts()
+	require.Equal(t, 1, polls)
+	require.Equal(t, 1, processed)
+
+	pc.resume()
+	time.Sleep(150 * time.Millisecond)
+	polls, _ = poller.counts()
+	require.True(t, polls > 1)
+}
+
+func pauseTestWorkflow(Context) (string, error) {
+	return "done", nil
+}
+
+func TestAggregatedWorkerPause(t *testing.T) {
+	client, err := NewClient(ClientOptions{WorkflowService: NewInMemoryWorkflowService(), Logger: ilog.NewNopLogger()})
+	require.NoError(t, err)
+	defer client.Close()
+
+	worker := NewAggregatedWorker(client.(*WorkflowClient), "pause-task-queue", WorkerOptions{})
+	worker.RegisterWorkflow(pauseTestWorkflow)
+	worker.Pause()
+	require.NoError(t, worker.Start())
+	defer worker.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	run, err := client.ExecuteWorkflow(ctx, StartWorkflowOptions{ID: "pause", TaskQueue: "pause-task-queue"}, pauseTestWorkflow)
+	require.NoError(t, err)
+
+	getCtx, getCancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer getCancel()
+	require.Error(t, run.Get(getCtx, nil))
+
+	worker.Resume()
+	var result string
+	require.NoError(t, run.Get(ctx, &result))
+	require.Equal(t, "done", result)
+}
+
+func TestAggregatedWorkerPauseRunsLocalActivities(t *testing.T) {
+	client, err := NewClient(ClientOptions{WorkflowService: NewInMemoryWorkflowService(), Logger: ilog.NewNopLogger()})
+	require.NoError(t, err)
+	defer client.Close()
+
+	worker := NewAggregatedWorker(client.(*WorkflowClient), "pause-la-task-queue", WorkerOptions{})
+	pauseWorker := func() error {
+		worker.Pause()
+		return nil
+	}
+	worker.RegisterWorkflowWithOptions(func(ctx Context) (string, error) {
+		ctx = WithLocalActivityOptions(ctx, LocalActivityOptions{ScheduleToCloseTimeout: 10 * time.Second})
+		if err := ExecuteLocalActivity(ctx, pauseWorker).Get(ctx, nil); err != nil {
+			return "", err
+		}
+		var result string
+		err := ExecuteLocalActivity(ctx, func() (string, error) { return "done", nil }).Get(ctx, &result)
+		return result, err
+	}, RegisterWorkflowOptions{Name: "PauseLocalActivityWorkflow"})
+	require.NoError(t, worker.Start())
+	defer worker.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	run, err := client.ExecuteWorkflow(ctx, StartWorkflowOptions{ID: "pause-la", TaskQueue: "pause-la-task-queue"},
+		"PauseLocalActivityWorkflow")
+	require.NoError(t, err)
+
+	// The workflow task polled before pause completes its local activities.
+	var result string
+	require.NoError(t, run.Get(ctx, &result))
+	require.Equal(t, "done", result)
+	require.True(t, worker.pause.isPaused())
+}
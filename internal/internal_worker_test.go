@@ -1703,6 +1703,38 @@ func TestActivityExecutionVariousTypesWithDataConverter(t *testing.T) {
 	testActivityExecutionVariousTypesHelper(ctx, t, dc)
 }
 
+type testTypedNilError struct{}
+
+func (e *testTypedNilError) Error() string {
+	return "typed nil error"
+}
+
+func TestActivityTypedNilError(t *testing.T) {
+	ctx := context.WithValue(context.Background(), activityEnvContextKey, &activityEnvironment{})
+	dataConverter := converter.GetDefaultDataConverter()
+
+	// A typed nil pointer returned as error is a non nil error, as when the function is called directly.
+	a1 := activityExecutor{
+		fn: func(arg1 string) (string, error) {
+			var err *testTypedNilError
+			return "result", err
+		}}
+	_, err := a1.Execute(ctx, testEncodeFunctionArgs(dataConverter, "test"))
+	require.Error(t, err)
+	require.IsType(t, (*testTypedNilError)(nil), err)
+
+	// A nil pointer returned through a pointer error result is a success.
+	a2 := activityExecutor{
+		fn: func(arg1 string) (string, *testTypedNilError) {
+			return "result", nil
+		}}
+	encResult, err := a2.Execute(ctx, testEncodeFunctionArgs(dataConverter, "test"))
+	require.NoError(t, err)
+	var result string
+	require.NoError(t, dataConverter.FromPayloads(encResult, &result))
+	require.Equal(t, "result", result)
+}
+
 func TestActivityNilArgs(t *testing.T) {
 	nilErr := errors.New("nils")
 	activityFn := func(name string, idx int, strptr *string, wt *commonpb.WorkflowType) error {
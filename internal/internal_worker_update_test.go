@@ -0,0 +1,140 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+	"golang.org/x/time/rate"
+
+	ilog "go.temporal.io/sdk/internal/log"
+)
+
+func TestBaseWorkerUpdateMaxConcurrentTask(t *testing.T) {
+	poller := &testTaskPoller{task: struct{}{}, processDuration: 5 * time.Millisecond}
+	worker := newTestBaseWorker(poller, baseWorkerOptions{pollerCount: 2, maxConcurrentTask: 1}, tally.NoopScope)
+	worker.Start()
+	defer worker.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+	worker.updateMaxConcurrentTask(3)
+	time.Sleep(100 * time.Millisecond)
+	_, maxProcessing := poller.maxConcurrency()
+	require.Equal(t, 3, maxProcessing)
+
+	worker.updateMaxConcurrentTask(1)
+	// Let tasks in flight complete.
+	time.Sleep(50 * time.Millisecond)
+	poller.resetMaxConcurrency()
+	time.Sleep(100 * time.Millisecond)
+	_, maxProcessing = poller.maxConcurrency()
+	require.Equal(t, 1, maxProcessing)
+}
+
+func TestBaseWorkerUpdatePollerCount(t *testing.T) {
+	poller := &testTaskPoller{pollDuration: 10 * time.Millisecond}
+	worker := newTestBaseWorker(poller, baseWorkerOptions{pollerCount: 1, maxConcurrentTask: 10}, tally.NoopScope)
+	worker.Start()
+	defer worker.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+	maxPolling, _ := poller.maxConcurrency()
+	require.Equal(t, 1, maxPolling)
+
+	worker.updatePollerCount(3)
+	time.Sleep(50 * time.Millisecond)
+	maxPolling, _ = poller.maxConcurrency()
+	require.Equal(t, 3, maxPolling)
+
+	worker.updatePollerCount(1)
+	time.Sleep(50 * time.Millisecond)
+	poller.resetMaxConcurrency()
+	time.Sleep(50 * time.Millisecond)
+	maxPolling, _ = poller.maxConcurrency()
+	require.Equal(t, 1, maxPolling)
+	worker.limitsLock.Lock()
+	require.Len(t, worker.pollers, 1)
+	worker.limitsLock.Unlock()
+}
+
+func TestBaseWorkerUpdatePollerCountWithAutoscaler(t *testing.T) {
+	autoscaler := newPollerAutoscaler(2, 4)
+	worker := newTestBaseWorker(&testTaskPoller{pollDuration: 10 * time.Millisecond}, baseWorkerOptions{
+		pollerCount:       4,
+		maxConcurrentTask: 10,
+		pollerAutoscaler:  autoscaler},
+		tally.NoopScope,
+	)
+	worker.Start()
+	defer worker.Stop()
+
+	worker.updatePollerCount(1)
+	require.Equal(t, 1, autoscaler.activePollers())
+	time.Sleep(50 * time.Millisecond)
+	worker.limitsLock.Lock()
+	require.Len(t, worker.pollers, 1)
+	worker.limitsLock.Unlock()
+}
+
+func TestAggregatedWorkerUpdateOptions(t *testing.T) {
+	client, err := NewClient(ClientOptions{WorkflowService: NewInMemoryWorkflowService(), Logger: ilog.NewNopLogger()})
+	require.NoError(t, err)
+	defer client.Close()
+
+	worker := NewAggregatedWorker(client.(*WorkflowClient), "update-options-task-queue", WorkerOptions{EnableSessionWorker: true})
+	require.Error(t, worker.UpdateOptions(WorkerUpdateOptions{WorkerActivitiesPerSecond: -1}))
+
+	require.NoError(t, worker.UpdateOptions(WorkerUpdateOptions{
+		MaxConcurrentActivityExecutionSize:      5,
+		WorkerActivitiesPerSecond:               6,
+		TaskQueueActivitiesPerSecond:            7,
+		MaxConcurrentActivityTaskPollers:        8,
+		MaxConcurrentLocalActivityExecutionSize: 9,
+		WorkerLocalActivitiesPerSecond:          10,
+		MaxConcurrentWorkflowTaskExecutionSize:  11,
+	}))
+
+	activityWorker := worker.activityWorker.worker
+	require.Equal(t, 5, activityWorker.options.maxConcurrentTask)
+	require.Equal(t, rate.Limit(6), activityWorker.taskLimiter.Limit())
+	require.Equal(t, 7.0, worker.activityWorker.poller.(*activityTaskPoller).getActivitiesPerSecond())
+	require.Equal(t, 8, activityWorker.options.pollerCount)
+
+	localActivityWorker := worker.workflowWorker.localActivityWorker
+	require.Equal(t, 9, localActivityWorker.options.maxConcurrentTask)
+	require.Equal(t, rate.Limit(10), localActivityWorker.taskLimiter.Limit())
+
+	workflowWorker := worker.workflowWorker.worker
+	require.Equal(t, 11, workflowWorker.options.maxConcurrentTask)
+	require.Equal(t, defaultConcurrentPollRoutineSize, workflowWorker.options.pollerCount)
+	require.Equal(t, rate.Limit(defaultWorkerTaskExecutionRate), workflowWorker.taskLimiter.Limit())
+
+	require.Equal(t, 8, worker.sessionWorker.activityWorker.worker.options.pollerCount)
+	require.Equal(t, 1, worker.sessionWorker.creationWorker.worker.options.pollerCount)
+	require.Equal(t, 5, worker.sessionWorker.creationWorker.worker.options.maxConcurrentTask)
+}
@@ -29,6 +29,8 @@ package internal
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"reflect"
 	"runtime"
 	"strings"
@@ -47,6 +49,11 @@ import (
 const (
 	defaultSignalChannelSize = 100000 // really large buffering size(100K)
 
+	defaultDeadlockDetectionTimeout = time.Second
+
+	// debugModeEnvVar disables the deadlock detection when set, for debuggers the worker cannot detect.
+	debugModeEnvVar = "TEMPORAL_DEBUG"
+
 	panicIllegalAccessCoroutinueState = "getState: illegal access from outside of workflow context"
 )
 
@@ -158,6 +165,14 @@ type (
 		closed       bool             // indicates that owning coroutine has finished execution
 		blocked      atomic.Bool
 		panicError   *workflowPanicError // non nil if coroutine had unhandled panic
+		goroutineID  string              // id of the goroutine running the coroutine, used for its stack trace after a deadlock
+		deadlocked   bool                // true if coroutine didn't yield in time, it is abandoned by the dispatcher but keeps running until it yields
+	}
+
+	// deadlockDetectedError is the panic value of a coroutine that didn't yield in time.
+	deadlockDetectedError struct {
+		coroutine string
+		timeout   time.Duration
 	}
 
 	dispatcherImpl struct {
@@ -169,6 +184,9 @@ type (
 		mutex            sync.Mutex // used to synchronize executing
 		closed           bool
 		interceptor      WorkflowOutboundCallsInterceptor
+
+		deadlockDetectionTimeout time.Duration // time a coroutine can run without yielding
+		deadlockTimer            *time.Timer   // reused by every coroutine call to detect deadlocks
 	}
 
 	// WorkflowOptions options passed to the workflow function
@@ -512,26 +530,14 @@ func (d *syncWorkflowDefinition) Execute(env WorkflowEnvironment, header *common
 	})
 
 	getWorkflowEnvironment(d.rootCtx).RegisterSignalHandler(func(name string, result *commonpb.Payloads) {
-		eo := getWorkflowEnvOptions(d.rootCtx)
-		// We don't want this code to be blocked ever, using sendAsync().
-		ch := eo.getSignalChannel(d.rootCtx, name).(*channelImpl)
-		ok := ch.SendAsync(result)
-		if !ok {
-			panic(fmt.Sprintf("Exceeded channel buffer size for signal: %v", name))
+		err := envInterceptor.inboundInterceptor.HandleSignal(d.rootCtx, name, result)
+		if err != nil {
+			panic(err)
 		}
 	})
 
 	getWorkflowEnvironment(d.rootCtx).RegisterQueryHandler(func(queryType string, queryArgs *commonpb.Payloads) (*commonpb.Payloads, error) {
-		eo := getWorkflowEnvOptions(d.rootCtx)
-		handler, ok := eo.queryHandlers[queryType]
-		if !ok {
-			keys := []string{QueryTypeStackTrace, QueryTypeOpenSessions}
-			for k := range eo.queryHandlers {
-				keys = append(keys, k)
-			}
-			return nil, fmt.Errorf("unknown queryType %v. KnownQueryTypes=%v", queryType, keys)
-		}
-		return handler(queryArgs)
+		return envInterceptor.inboundInterceptor.HandleQuery(d.rootCtx, queryType, queryArgs)
 	})
 }
 
@@ -553,7 +559,10 @@ func (d *syncWorkflowDefinition) Close() {
 // Context passed to the root function is child of the passed rootCtx.
 // This way rootCtx can be used to pass values to the coroutine code.
 func newDispatcher(rootCtx Context, interceptor *workflowEnvironmentInterceptor, root func(ctx Context)) (*dispatcherImpl, Context) {
-	result := &dispatcherImpl{interceptor: interceptor.outboundInterceptor}
+	result := &dispatcherImpl{
+		interceptor:              interceptor.outboundInterceptor,
+		deadlockDetectionTimeout: getWorkflowEnvironment(rootCtx).GetDeadlockDetectionTimeout(),
+	}
 	interceptor.dispatcher = result
 	ctxWithState := result.interceptor.Go(rootCtx, "root", root)
 	return result, ctxWithState
@@ -565,6 +574,9 @@ func executeDispatcher(ctx Context, dispatcher dispatcher) {
 	env := getWorkflowEnvironment(ctx)
 	panicErr := dispatcher.ExecuteUntilAllBlocked()
 	if panicErr != nil {
+		if _, ok := panicErr.(*workflowPanicError).value.(*deadlockDetectedError); ok {
+			env.GetMetricsScope().Counter(metrics.WorkflowTaskDeadlockCounter).Inc(1)
+		}
 		env.Complete(nil, panicErr)
 		return
 	}
@@ -581,6 +593,20 @@ func executeDispatcher(ctx Context, dispatcher dispatcher) {
 		env.GetMetricsScope().Counter(metrics.UnhandledSignalsCounter).Inc(1)
 	}
 
+	if rp.error == nil {
+		if err := checkWorkflowPayloadSize(env, rp.workflowResult, payloadOperationWorkflowResult, env.WorkflowInfo().WorkflowType.Name); err != nil {
+			env.Complete(nil, err)
+			return
+		}
+	}
+	var continueAsNewErr *ContinueAsNewError
+	if errors.As(rp.error, &continueAsNewErr) {
+		params := continueAsNewErr.params
+		if err := checkWorkflowPayloadSize(env, params.Input, payloadOperationContinueAsNewInput, params.WorkflowType.Name); err != nil {
+			env.Complete(nil, err)
+			return
+		}
+	}
 	env.Complete(rp.workflowResult, rp.error)
 }
 
@@ -852,11 +878,38 @@ func (s *coroutineState) unblocked() {
 	s.keptBlocked = false
 }
 
-func (s *coroutineState) call() {
+// call runs the coroutine until it yields. It returns a workflowPanicError if the coroutine doesn't yield within
+// deadlockDetectionTimeout, the timeout is extended while a debugger is attached. The timer is shared by the
+// coroutines of the dispatcher and reset by every call.
+// A deadlocked coroutine can't be stopped, it keeps running and can modify the workflow state until it yields.
+func (s *coroutineState) call(timer *time.Timer, deadlockDetectionTimeout time.Duration) error {
 	s.unblock <- func(status string, stackDepth int) bool {
 		return false // unblock
 	}
-	<-s.aboutToBlock
+
+	if !timer.Stop() {
+		// The timer fired after the previous call returned.
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(deadlockDetectionTimeout)
+	for {
+		select {
+		case <-s.aboutToBlock:
+			return nil
+		case <-timer.C:
+			if isDebuggerAttached() {
+				// the coroutine is most likely stopped at a breakpoint
+				timer.Reset(deadlockDetectionTimeout)
+				continue
+			}
+			s.deadlocked = true
+			deadlockErr := &deadlockDetectedError{coroutine: s.name, timeout: deadlockDetectionTimeout}
+			return newWorkflowPanicError(deadlockErr, s.dispatcher.StackTrace())
+		}
+	}
 }
 
 func (s *coroutineState) close() {
@@ -865,6 +918,18 @@ func (s *coroutineState) close() {
 }
 
 func (s *coroutineState) exit() {
+	if s.deadlocked {
+		// the coroutine cannot be stopped until it yields, if it ever does
+		go func() {
+			<-s.aboutToBlock
+			s.goexit()
+		}()
+		return
+	}
+	s.goexit()
+}
+
+func (s *coroutineState) goexit() {
 	if !s.closed {
 		s.unblock <- func(status string, stackDepth int) bool {
 			runtime.Goexit()
@@ -874,6 +939,9 @@ func (s *coroutineState) exit() {
 }
 
 func (s *coroutineState) stackTrace() string {
+	if s.deadlocked {
+		return getGoroutineStackTrace(s.goroutineID, fmt.Sprintf("coroutine %s [deadlock detected]:", s.name))
+	}
 	if s.closed {
 		return ""
 	}
@@ -892,6 +960,7 @@ func (d *dispatcherImpl) NewCoroutine(ctx Context, name string, f func(ctx Conte
 	state := d.newState(name)
 	spawned := WithValue(ctx, coroutinesContextKey, state)
 	go func(crt *coroutineState) {
+		crt.goroutineID = getGoroutineID()
 		defer crt.close()
 		defer func() {
 			if r := recover(); r != nil {
@@ -928,6 +997,14 @@ func (d *dispatcherImpl) ExecuteUntilAllBlocked() (err error) {
 	d.executing = true
 	d.mutex.Unlock()
 	defer func() { d.executing = false }()
+	// Defaults are populated by setWorkerOptionsDefaults, tests can use the zero value.
+	deadlockDetectionTimeout := d.deadlockDetectionTimeout
+	if deadlockDetectionTimeout <= 0 {
+		deadlockDetectionTimeout = defaultDeadlockDetectionTimeout
+	}
+	if d.deadlockTimer == nil {
+		d.deadlockTimer = time.NewTimer(deadlockDetectionTimeout)
+	}
 	allBlocked := false
 	// Keep executing until at least one goroutine made some progress
 	for !allBlocked {
@@ -939,7 +1016,9 @@ func (d *dispatcherImpl) ExecuteUntilAllBlocked() (err error) {
 			if !c.closed {
 				// TODO: Support handling of panic in a coroutine by dispatcher.
 				// TODO: Dump all outstanding coroutines if one of them panics
-				c.call()
+				if err := c.call(d.deadlockTimer, deadlockDetectionTimeout); err != nil {
+					return err
+				}
 			}
 			// c.call() can close the context so check again
 			if c.closed {
@@ -965,6 +1044,60 @@ func (d *dispatcherImpl) ExecuteUntilAllBlocked() (err error) {
 	return nil
 }
 
+func (e *deadlockDetectedError) Error() string {
+	return fmt.Sprintf("potential deadlock detected: workflow coroutine %q didn't yield for over %v, "+
+		"workflow code must not busy loop or block on native Go primitives", e.coroutine, e.timeout)
+}
+
+// isDebuggerAttached returns true if a debugger traces the process, or if debug mode is enabled explicitly
+// through the TEMPORAL_DEBUG environment variable on platforms without /proc.
+func isDebuggerAttached() bool {
+	if os.Getenv(debugModeEnvVar) != "" {
+		return true
+	}
+	status, err := ioutil.ReadFile("/proc/self/status")
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		if strings.HasPrefix(line, "TracerPid:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "TracerPid:")) != "0"
+		}
+	}
+	return false
+}
+
+// getGoroutineID returns the id of the calling goroutine from the header of its stack trace.
+func getGoroutineID() string {
+	var buf [64]byte
+	header := strings.TrimPrefix(string(buf[:runtime.Stack(buf[:], false)]), "goroutine ")
+	if i := strings.IndexByte(header, ' '); i > 0 {
+		return header[:i]
+	}
+	return ""
+}
+
+// getGoroutineStackTrace returns the stack trace of another goroutine, with top replacing its header line.
+func getGoroutineStackTrace(goroutineID, top string) string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	prefix := "goroutine " + goroutineID + " ["
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		if strings.HasPrefix(stack, prefix) {
+			lines := strings.Split(strings.TrimRightFunc(stack, unicode.IsSpace), "\n")
+			return strings.Join(append([]string{top}, lines[1:]...), "\n")
+		}
+	}
+	return top
+}
+
 func (d *dispatcherImpl) IsDone() bool {
 	return len(d.coroutines) == 0
 }
@@ -981,9 +1114,12 @@ func (d *dispatcherImpl) Close() {
 	}
 	d.closed = true
 	d.mutex.Unlock()
+	if d.deadlockTimer != nil {
+		d.deadlockTimer.Stop()
+	}
 	for i := 0; i < len(d.coroutines); i++ {
 		c := d.coroutines[i]
-		if !c.closed {
+		if c.deadlocked || !c.closed {
 			c.exit()
 		}
 	}
@@ -993,7 +1129,7 @@ func (d *dispatcherImpl) StackTrace() string {
 	var result string
 	for i := 0; i < len(d.coroutines); i++ {
 		c := d.coroutines[i]
-		if !c.closed {
+		if c.deadlocked || !c.closed {
 			if len(result) > 0 {
 				result += "\n\n"
 			}
@@ -1154,6 +1290,24 @@ func newSyncWorkflowDefinition(workflow workflow) *syncWorkflowDefinition {
 }
 
 func getValidatedWorkflowFunction(workflowFunc interface{}, args []interface{}, dataConverter converter.DataConverter, r *registry) (*WorkflowType, *commonpb.Payloads, error) {
+	workflowType, err := getValidatedWorkflowType(workflowFunc, args, r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if dataConverter == nil {
+		dataConverter = converter.GetDefaultDataConverter()
+	}
+	input, err := encodeArgs(dataConverter, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return workflowType, input, nil
+}
+
+// getValidatedWorkflowType resolves the workflow type name and validates args against the workflow function signature
+// without encoding them.
+func getValidatedWorkflowType(workflowFunc interface{}, args []interface{}, r *registry) (*WorkflowType, error) {
 	fnName := ""
 	fType := reflect.TypeOf(workflowFunc)
 	switch getKind(fType) {
@@ -1162,24 +1316,16 @@ func getValidatedWorkflowFunction(workflowFunc interface{}, args []interface{},
 
 	case reflect.Func:
 		if err := validateFunctionArgs(workflowFunc, args, true); err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		fnName = getWorkflowFunctionName(r, workflowFunc)
 
 	default:
-		return nil, nil, fmt.Errorf(
+		return nil, fmt.Errorf(
 			"invalid type 'workflowFunc' parameter provided, it can be either worker function or name of the worker type: %v",
 			workflowFunc)
 	}
-
-	if dataConverter == nil {
-		dataConverter = converter.GetDefaultDataConverter()
-	}
-	input, err := encodeArgs(dataConverter, args)
-	if err != nil {
-		return nil, nil, err
-	}
-	return &WorkflowType{Name: fnName}, input, nil
+	return &WorkflowType{Name: fnName}, nil
 }
 
 func getWorkflowEnvOptions(ctx Context) *WorkflowOptions {
@@ -1208,9 +1354,9 @@ func setWorkflowEnvOptionsIfNotExist(ctx Context) Context {
 func getDataConverterFromWorkflowContext(ctx Context) converter.DataConverter {
 	options := getWorkflowEnvOptions(ctx)
 	if options == nil || options.DataConverter == nil {
-		return converter.GetDefaultDataConverter()
+		return dataConverterWithWorkflowContext(ctx, converter.GetDefaultDataConverter())
 	}
-	return options.DataConverter
+	return dataConverterWithWorkflowContext(ctx, options.DataConverter)
 }
 
 func getRegistryFromWorkflowContext(ctx Context) *registry {
@@ -74,8 +74,18 @@ type (
 		metricsScope       *metrics.TaggedScope
 		identity           string
 		dataConverter      converter.DataConverter
+		failureConverter   FailureConverter
 		contextPropagators []ContextPropagator
 		tracer             opentracing.Tracer
+		interceptor        ClientOutboundCallsInterceptor
+		payloadLimits      PayloadLimitOptions
+		payloadSizeChecker *payloadSizeChecker
+	}
+
+	// workflowClientInterceptor is the last link in the client interceptor chain. It encodes the arguments
+	// and calls the service.
+	workflowClientInterceptor struct {
+		client *WorkflowClient
 	}
 
 	// namespaceClient is the client for managing namespaces.
@@ -111,13 +121,14 @@ type (
 
 	// workflowRunImpl is an implementation of WorkflowRun
 	workflowRunImpl struct {
-		workflowFn    interface{}
-		workflowID    string
-		firstRunID    string
-		currentRunID  string
-		iterFn        func(ctx context.Context, runID string) HistoryEventIterator
-		dataConverter converter.DataConverter
-		registry      *registry
+		workflowFn       interface{}
+		workflowID       string
+		firstRunID       string
+		currentRunID     string
+		iterFn           func(ctx context.Context, runID string) HistoryEventIterator
+		dataConverter    converter.DataConverter
+		failureConverter FailureConverter
+		registry         *registry
 	}
 
 	// HistoryEventIterator represents the interface for
@@ -162,6 +173,27 @@ func (wc *WorkflowClient) StartWorkflow(
 	options StartWorkflowOptions,
 	workflowFunc interface{},
 	args ...interface{},
+) (*WorkflowExecution, error) {
+	if len(options.ID) == 0 {
+		options.ID = uuid.NewRandom().String()
+	}
+
+	// Validate type and its arguments.
+	workflowType, err := getValidatedWorkflowType(workflowFunc, args, wc.registry)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, header := wc.startWorkflowSpan(ctx, "StartWorkflow", workflowType.Name, options.ID)
+	return wc.interceptor.StartWorkflow(ctx, header, options, workflowType.Name, args...)
+}
+
+func (wc *WorkflowClient) startWorkflow(
+	ctx context.Context,
+	header *commonpb.Header,
+	options StartWorkflowOptions,
+	workflowType string,
+	args ...interface{},
 ) (*WorkflowExecution, error) {
 	workflowID := options.ID
 	if len(workflowID) == 0 {
@@ -172,11 +204,13 @@ func (wc *WorkflowClient) StartWorkflow(
 	runTimeout := common.Int32Ceil(options.WorkflowRunTimeout.Seconds())
 	workflowTaskTimeout := common.Int32Ceil(options.WorkflowTaskTimeout.Seconds())
 
-	// Validate type and its arguments.
-	workflowType, input, err := getValidatedWorkflowFunction(workflowFunc, args, wc.dataConverter, wc.registry)
+	input, err := encodeArgs(dataConverterWithContext(ctx, wc.dataConverter), args)
 	if err != nil {
 		return nil, err
 	}
+	if err := wc.payloadSizeChecker.check(input, payloadOperationWorkflowInput, workflowType); err != nil {
+		return nil, err
+	}
 
 	memo, err := getWorkflowMemo(options.Memo, wc.dataConverter)
 	if err != nil {
@@ -188,25 +222,12 @@ func (wc *WorkflowClient) StartWorkflow(
 		return nil, err
 	}
 
-	// create a workflow start span and attach it to the context object.
-	// N.B. we need to finish this immediately as jaeger does not give us a way
-	// to recreate a span given a span context - which means we will run into
-	// issues during replay. we work around this by creating and ending the
-	// workflow start span and passing in that context to the workflow. So
-	// everything beginning with the StartWorkflowExecutionRequest will be
-	// parented by the created start workflow span.
-	ctx, span := createOpenTracingWorkflowSpan(ctx, wc.tracer, time.Now(), fmt.Sprintf("StartWorkflow-%s", workflowType.Name), workflowID)
-	span.Finish()
-
-	// get workflow headers from the context
-	header := wc.getWorkflowHeader(ctx)
-
 	// run propagators to extract information about tracing and other stuff, store in headers field
 	startRequest := &workflowservice.StartWorkflowExecutionRequest{
 		Namespace:                       wc.namespace,
 		RequestId:                       uuid.New(),
 		WorkflowId:                      workflowID,
-		WorkflowType:                    &commonpb.WorkflowType{Name: workflowType.Name},
+		WorkflowType:                    &commonpb.WorkflowType{Name: workflowType},
 		TaskQueue:                       &taskqueuepb.TaskQueue{Name: options.TaskQueue, Kind: enumspb.TASK_QUEUE_KIND_NORMAL},
 		Input:                           input,
 		WorkflowExecutionTimeoutSeconds: executionTimeout,
@@ -239,7 +260,7 @@ func (wc *WorkflowClient) StartWorkflow(
 	}
 
 	if wc.metricsScope != nil {
-		scope := wc.metricsScope.GetTaggedScope(tagTaskQueue, options.TaskQueue, tagWorkflowType, workflowType.Name)
+		scope := wc.metricsScope.GetTaggedScope(tagTaskQueue, options.TaskQueue, tagWorkflowType, workflowType)
 		scope.Counter(metrics.WorkflowStartCounter).Inc(1)
 	}
 
@@ -260,35 +281,23 @@ func (wc *WorkflowClient) StartWorkflow(
 // subjected to change in the future.
 // NOTE: the context.Context should have a fairly large timeout, since workflow execution may take a while to be finished
 func (wc *WorkflowClient) ExecuteWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (WorkflowRun, error) {
-	// start the workflow execution
-	var runID string
-	var workflowID string
-	executionInfo, err := wc.StartWorkflow(ctx, options, workflow, args...)
-	if err != nil {
-		if e, ok := err.(*serviceerror.WorkflowExecutionAlreadyStarted); ok {
-			runID = e.RunId
-			workflowID = options.ID
-		} else {
-			return nil, err
-		}
-	} else {
-		runID = executionInfo.RunID
-		workflowID = executionInfo.ID
+	if len(options.ID) == 0 {
+		options.ID = uuid.NewRandom().String()
 	}
 
-	iterFn := func(fnCtx context.Context, fnRunID string) HistoryEventIterator {
-		return wc.GetWorkflowHistory(fnCtx, workflowID, fnRunID, true, enumspb.HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT)
+	// Validate type and its arguments.
+	workflowType, err := getValidatedWorkflowType(workflow, args, wc.registry)
+	if err != nil {
+		return nil, err
 	}
 
-	return &workflowRunImpl{
-		workflowFn:    workflow,
-		workflowID:    workflowID,
-		firstRunID:    runID,
-		currentRunID:  runID,
-		iterFn:        iterFn,
-		dataConverter: wc.dataConverter,
-		registry:      wc.registry,
-	}, nil
+	ctx, header := wc.startWorkflowSpan(ctx, "StartWorkflow", workflowType.Name, options.ID)
+	run, err := wc.interceptor.ExecuteWorkflow(ctx, header, options, workflowType.Name, args...)
+	if err != nil {
+		return nil, err
+	}
+	setWorkflowRunFunction(run, workflow)
+	return run, nil
 }
 
 // GetWorkflow gets a workflow execution and returns a WorkflowRun that will allow you to wait until this workflow
@@ -302,40 +311,19 @@ func (wc *WorkflowClient) GetWorkflow(_ context.Context, workflowID string, runI
 	}
 
 	return &workflowRunImpl{
-		workflowID:    workflowID,
-		firstRunID:    runID,
-		currentRunID:  runID,
-		iterFn:        iterFn,
-		dataConverter: wc.dataConverter,
-		registry:      wc.registry,
+		workflowID:       workflowID,
+		firstRunID:       runID,
+		currentRunID:     runID,
+		iterFn:           iterFn,
+		dataConverter:    wc.dataConverter,
+		failureConverter: wc.failureConverter,
+		registry:         wc.registry,
 	}
 }
 
 // SignalWorkflow signals a workflow in execution.
 func (wc *WorkflowClient) SignalWorkflow(ctx context.Context, workflowID string, runID string, signalName string, arg interface{}) error {
-	input, err := encodeArg(wc.dataConverter, arg)
-	if err != nil {
-		return err
-	}
-
-	request := &workflowservice.SignalWorkflowExecutionRequest{
-		Namespace: wc.namespace,
-		WorkflowExecution: &commonpb.WorkflowExecution{
-			WorkflowId: workflowID,
-			RunId:      runID,
-		},
-		SignalName: signalName,
-		Input:      input,
-		Identity:   wc.identity,
-	}
-
-	return backoff.Retry(ctx,
-		func() error {
-			tchCtx, cancel := newChannelContext(ctx)
-			defer cancel()
-			_, err := wc.workflowService.SignalWorkflowExecution(tchCtx, request)
-			return err
-		}, createDynamicServiceRetryPolicy(ctx), isServiceTransientError)
+	return wc.interceptor.SignalWorkflow(ctx, workflowID, runID, signalName, arg)
 }
 
 // SignalWithStartWorkflow sends a signal to a running workflow.
@@ -343,151 +331,45 @@ func (wc *WorkflowClient) SignalWorkflow(ctx context.Context, workflowID string,
 func (wc *WorkflowClient) SignalWithStartWorkflow(ctx context.Context, workflowID string, signalName string, signalArg interface{},
 	options StartWorkflowOptions, workflowFunc interface{}, workflowArgs ...interface{}) (WorkflowRun, error) {
 
-	signalInput, err := encodeArg(wc.dataConverter, signalArg)
-	if err != nil {
-		return nil, err
-	}
-
 	if workflowID == "" {
 		workflowID = uuid.NewRandom().String()
 	}
 
-	executionTimeout := common.Int32Ceil(options.WorkflowExecutionTimeout.Seconds())
-	runTimeout := common.Int32Ceil(options.WorkflowRunTimeout.Seconds())
-	taskTimeout := common.Int32Ceil(options.WorkflowTaskTimeout.Seconds())
-
 	// Validate type and its arguments.
-	workflowType, input, err := getValidatedWorkflowFunction(workflowFunc, workflowArgs, wc.dataConverter, wc.registry)
-	if err != nil {
-		return nil, err
-	}
-
-	memo, err := getWorkflowMemo(options.Memo, wc.dataConverter)
-	if err != nil {
-		return nil, err
-	}
-
-	searchAttr, err := serializeSearchAttributes(options.SearchAttributes)
+	workflowType, err := getValidatedWorkflowType(workflowFunc, workflowArgs, wc.registry)
 	if err != nil {
 		return nil, err
 	}
 
-	// create a workflow start span and attach it to the context object. finish it immediately
-	ctx, span := createOpenTracingWorkflowSpan(ctx, wc.tracer, time.Now(), fmt.Sprintf("SignalWithStartWorkflow-%s", workflowType.Name), workflowID)
-	span.Finish()
-
-	// get workflow headers from the context
-	header := wc.getWorkflowHeader(ctx)
-
-	signalWithStartRequest := &workflowservice.SignalWithStartWorkflowExecutionRequest{
-		Namespace:                       wc.namespace,
-		RequestId:                       uuid.New(),
-		WorkflowId:                      workflowID,
-		WorkflowType:                    &commonpb.WorkflowType{Name: workflowType.Name},
-		TaskQueue:                       &taskqueuepb.TaskQueue{Name: options.TaskQueue, Kind: enumspb.TASK_QUEUE_KIND_NORMAL},
-		Input:                           input,
-		WorkflowExecutionTimeoutSeconds: executionTimeout,
-		WorkflowRunTimeoutSeconds:       runTimeout,
-		WorkflowTaskTimeoutSeconds:      taskTimeout,
-		SignalName:                      signalName,
-		SignalInput:                     signalInput,
-		Identity:                        wc.identity,
-		RetryPolicy:                     convertRetryPolicy(options.RetryPolicy),
-		CronSchedule:                    options.CronSchedule,
-		Memo:                            memo,
-		SearchAttributes:                searchAttr,
-		WorkflowIdReusePolicy:           options.WorkflowIDReusePolicy,
-		Header:                          header,
-	}
-
-	var response *workflowservice.SignalWithStartWorkflowExecutionResponse
-
-	// Start creating workflow request.
-	err = backoff.Retry(ctx,
-		func() error {
-			tchCtx, cancel := newChannelContext(ctx)
-			defer cancel()
-
-			var err1 error
-			response, err1 = wc.workflowService.SignalWithStartWorkflowExecution(tchCtx, signalWithStartRequest)
-			return err1
-		}, createDynamicServiceRetryPolicy(ctx), isServiceTransientError)
-
+	ctx, header := wc.startWorkflowSpan(ctx, "SignalWithStartWorkflow", workflowType.Name, workflowID)
+	run, err := wc.interceptor.SignalWithStartWorkflow(ctx, header, workflowID, signalName, signalArg, options, workflowType.Name, workflowArgs...)
 	if err != nil {
 		return nil, err
 	}
+	setWorkflowRunFunction(run, workflowFunc)
+	return run, nil
+}
 
-	if wc.metricsScope != nil {
-		scope := wc.metricsScope.GetTaggedScope(tagTaskQueue, options.TaskQueue, tagWorkflowType, workflowType.Name)
-		scope.Counter(metrics.WorkflowSignalWithStartCounter).Inc(1)
-	}
-
-	iterFn := func(fnCtx context.Context, fnRunID string) HistoryEventIterator {
-		return wc.GetWorkflowHistory(fnCtx, workflowID, fnRunID, true, enumspb.HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT)
+// setWorkflowRunFunction records the workflow function the run was started with, the interceptors only get the
+// workflow type name. Runs created by interceptors are left as is.
+func setWorkflowRunFunction(run WorkflowRun, workflowFunc interface{}) {
+	if workflowRun, ok := run.(*workflowRunImpl); ok {
+		workflowRun.workflowFn = workflowFunc
 	}
-
-	return &workflowRunImpl{
-		workflowFn:    workflowFunc,
-		workflowID:    workflowID,
-		firstRunID:    response.GetRunId(),
-		currentRunID:  response.GetRunId(),
-		iterFn:        iterFn,
-		dataConverter: wc.dataConverter,
-		registry:      wc.registry,
-	}, nil
 }
 
 // CancelWorkflow cancels a workflow in execution.  It allows workflow to properly clean up and gracefully close.
 // workflowID is required, other parameters are optional.
 // If runID is omit, it will terminate currently running workflow (if there is one) based on the workflowID.
 func (wc *WorkflowClient) CancelWorkflow(ctx context.Context, workflowID string, runID string) error {
-	request := &workflowservice.RequestCancelWorkflowExecutionRequest{
-		Namespace: wc.namespace,
-		WorkflowExecution: &commonpb.WorkflowExecution{
-			WorkflowId: workflowID,
-			RunId:      runID,
-		},
-		Identity: wc.identity,
-	}
-
-	return backoff.Retry(ctx,
-		func() error {
-			tchCtx, cancel := newChannelContext(ctx)
-			defer cancel()
-			_, err := wc.workflowService.RequestCancelWorkflowExecution(tchCtx, request)
-			return err
-		}, createDynamicServiceRetryPolicy(ctx), isServiceTransientError)
+	return wc.interceptor.CancelWorkflow(ctx, workflowID, runID)
 }
 
 // TerminateWorkflow terminates a workflow execution.
 // workflowID is required, other parameters are optional.
 // If runID is omit, it will terminate currently running workflow (if there is one) based on the workflowID.
 func (wc *WorkflowClient) TerminateWorkflow(ctx context.Context, workflowID string, runID string, reason string, details ...interface{}) error {
-	datailsPayload, err := wc.dataConverter.ToPayloads(details...)
-	if err != nil {
-		return err
-	}
-
-	request := &workflowservice.TerminateWorkflowExecutionRequest{
-		Namespace: wc.namespace,
-		WorkflowExecution: &commonpb.WorkflowExecution{
-			WorkflowId: workflowID,
-			RunId:      runID,
-		},
-		Reason:   reason,
-		Identity: wc.identity,
-		Details:  datailsPayload,
-	}
-
-	err = backoff.Retry(ctx,
-		func() error {
-			tchCtx, cancel := newChannelContext(ctx)
-			defer cancel()
-			_, err := wc.workflowService.TerminateWorkflowExecution(tchCtx, request)
-			return err
-		}, createDynamicServiceRetryPolicy(ctx), isServiceTransientError)
-
-	return err
+	return wc.interceptor.TerminateWorkflow(ctx, workflowID, runID, reason, details...)
 }
 
 // GetWorkflowHistory return a channel which contains the history events of a given workflow
@@ -587,12 +469,16 @@ func (wc *WorkflowClient) CompleteActivity(ctx context.Context, taskToken []byte
 	var data *commonpb.Payloads
 	if result != nil {
 		var err0 error
-		data, err0 = encodeArg(wc.dataConverter, result)
+		data, err0 = encodeArg(dataConverterWithContext(ctx, wc.dataConverter), result)
 		if err0 != nil {
 			return err0
 		}
+		if err0 = wc.payloadSizeChecker.check(data, payloadOperationActivityResult, ""); err0 != nil {
+			return err0
+		}
 	}
-	request := convertActivityResultToRespondRequest(wc.identity, taskToken, data, err, wc.dataConverter)
+	request := convertActivityResultToRespondRequest(wc.identity, taskToken, data, err,
+		dataConverterWithContext(ctx, wc.dataConverter), failureConverterWithContext(ctx, wc.failureConverter))
 	return reportActivityComplete(ctx, wc.workflowService, request, wc.metricsScope)
 }
 
@@ -608,32 +494,42 @@ func (wc *WorkflowClient) CompleteActivityByID(ctx context.Context, namespace, w
 	var data *commonpb.Payloads
 	if result != nil {
 		var err0 error
-		data, err0 = encodeArg(wc.dataConverter, result)
+		data, err0 = encodeArg(dataConverterWithContext(ctx, wc.dataConverter), result)
 		if err0 != nil {
 			return err0
 		}
+		if err0 = wc.payloadSizeChecker.check(data, payloadOperationActivityResult, activityID); err0 != nil {
+			return err0
+		}
 	}
 
-	request := convertActivityResultToRespondRequestByID(wc.identity, namespace, workflowID, runID, activityID, data, err, wc.dataConverter)
+	request := convertActivityResultToRespondRequestByID(wc.identity, namespace, workflowID, runID, activityID, data, err,
+		dataConverterWithContext(ctx, wc.dataConverter), failureConverterWithContext(ctx, wc.failureConverter))
 	return reportActivityCompleteByID(ctx, wc.workflowService, request, wc.metricsScope)
 }
 
 // RecordActivityHeartbeat records heartbeat for an activity.
 func (wc *WorkflowClient) RecordActivityHeartbeat(ctx context.Context, taskToken []byte, details ...interface{}) error {
-	data, err := encodeArgs(wc.dataConverter, details)
+	data, err := encodeArgs(dataConverterWithContext(ctx, wc.dataConverter), details)
 	if err != nil {
 		return err
 	}
+	if err := wc.payloadSizeChecker.check(data, payloadOperationHeartbeatDetails, ""); err != nil {
+		return err
+	}
 	return recordActivityHeartbeat(ctx, wc.workflowService, wc.identity, taskToken, data)
 }
 
 // RecordActivityHeartbeatByID records heartbeat for an activity.
 func (wc *WorkflowClient) RecordActivityHeartbeatByID(ctx context.Context,
 	namespace, workflowID, runID, activityID string, details ...interface{}) error {
-	data, err := encodeArgs(wc.dataConverter, details)
+	data, err := encodeArgs(dataConverterWithContext(ctx, wc.dataConverter), details)
 	if err != nil {
 		return err
 	}
+	if err := wc.payloadSizeChecker.check(data, payloadOperationHeartbeatDetails, activityID); err != nil {
+		return err
+	}
 	return recordActivityHeartbeatByID(ctx, wc.workflowService, wc.identity, namespace, workflowID, runID, activityID, data)
 }
 
@@ -822,6 +718,121 @@ func (wc *WorkflowClient) DescribeWorkflowExecution(ctx context.Context, workflo
 	return response, nil
 }
 
+// ResetWorkflow resets a workflow execution to the reset point selected by the request and returns the
+// run ID of the new run. The history of the run is scanned to find the reset point when needed.
+// The server reapplies the signals received after the reset point to the new run.
+// The errors it can return:
+//  - BadRequestError
+//  - InternalServiceError
+//  - EntityNotExistError
+func (wc *WorkflowClient) ResetWorkflow(ctx context.Context, request *ResetWorkflowRequest) (*workflowservice.ResetWorkflowExecutionResponse, error) {
+	runID, eventID, err := wc.getResetPoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	resetRequest := &workflowservice.ResetWorkflowExecutionRequest{
+		Namespace: wc.namespace,
+		WorkflowExecution: &commonpb.WorkflowExecution{
+			WorkflowId: request.WorkflowID,
+			RunId:      runID,
+		},
+		Reason:                    request.Reason,
+		WorkflowTaskFinishEventId: eventID,
+		RequestId:                 uuid.New(),
+	}
+	var response *workflowservice.ResetWorkflowExecutionResponse
+	err = backoff.Retry(ctx,
+		func() error {
+			var err1 error
+			tchCtx, cancel := newChannelContext(ctx)
+			defer cancel()
+			response, err1 = wc.workflowService.ResetWorkflowExecution(tchCtx, resetRequest)
+			return err1
+		}, createDynamicServiceRetryPolicy(ctx), isServiceTransientError)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// getResetPoint returns the run and the workflow task finish event id the workflow should be reset to.
+func (wc *WorkflowClient) getResetPoint(ctx context.Context, request *ResetWorkflowRequest) (runID string, eventID int64, err error) {
+	if request.WorkflowID == "" {
+		return "", 0, errors.New("workflowID is required")
+	}
+	runID = request.RunID
+	switch request.ResetType {
+	case ResetTypeEventID:
+		if request.EventID <= 0 {
+			return "", 0, errors.New("eventID is required for ResetTypeEventID")
+		}
+		eventID = request.EventID
+	case ResetTypeLastWorkflowTask:
+		eventID, err = wc.findWorkflowTaskCompletedEventID(ctx, request.WorkflowID, runID, false, anyWorkflowTask)
+	case ResetTypeBadBinary:
+		if request.BinaryChecksum == "" {
+			return "", 0, errors.New("binaryChecksum is required for ResetTypeBadBinary")
+		}
+		eventID, err = wc.findWorkflowTaskCompletedEventID(ctx, request.WorkflowID, runID, true, func(attributes *historypb.WorkflowTaskCompletedEventAttributes) bool {
+			return attributes.GetBinaryChecksum() == request.BinaryChecksum
+		})
+	case ResetTypeLastContinuedAsNew:
+		iter := wc.GetWorkflowHistory(ctx, request.WorkflowID, runID, false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+		if !iter.HasNext() {
+			return "", 0, errors.New("workflow history is empty")
+		}
+		var event *historypb.HistoryEvent
+		if event, err = iter.Next(); err != nil {
+			return "", 0, err
+		}
+		runID = event.GetWorkflowExecutionStartedEventAttributes().GetContinuedExecutionRunId()
+		if runID == "" {
+			return "", 0, errors.New("workflow run was not continued as new")
+		}
+		eventID, err = wc.findWorkflowTaskCompletedEventID(ctx, request.WorkflowID, runID, false, anyWorkflowTask)
+	default:
+		return "", 0, fmt.Errorf("unknown reset type %v", request.ResetType)
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return runID, eventID, nil
+}
+
+func anyWorkflowTask(*historypb.WorkflowTaskCompletedEventAttributes) bool {
+	return true
+}
+
+// findWorkflowTaskCompletedEventID iterates over the history of the run and returns the id of the first or the last
+// WorkflowTaskCompleted event accepted by the match function.
+func (wc *WorkflowClient) findWorkflowTaskCompletedEventID(
+	ctx context.Context,
+	workflowID, runID string,
+	first bool,
+	match func(*historypb.WorkflowTaskCompletedEventAttributes) bool,
+) (int64, error) {
+	var eventID int64
+	iter := wc.GetWorkflowHistory(ctx, workflowID, runID, false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return 0, err
+		}
+		if event.GetEventType() != enumspb.EVENT_TYPE_WORKFLOW_TASK_COMPLETED ||
+			!match(event.GetWorkflowTaskCompletedEventAttributes()) {
+			continue
+		}
+		eventID = event.GetEventId()
+		if first {
+			break
+		}
+	}
+	if eventID == 0 {
+		return 0, errors.New("unable to find a completed workflow task to reset to")
+	}
+	return eventID, nil
+}
+
 // QueryWorkflow queries a given workflow execution
 // workflowID and queryType are required, other parameters are optional.
 // - workflow ID of the workflow.
@@ -873,6 +884,45 @@ type QueryWorkflowWithOptionsRequest struct {
 	QueryRejectCondition enumspb.QueryRejectCondition
 }
 
+// ResetType selects the workflow task a workflow execution is reset to by ResetWorkflow.
+type ResetType int
+
+const (
+	// ResetTypeEventID resets the workflow to the workflow task finish event given by ResetWorkflowRequest.EventID.
+	ResetTypeEventID ResetType = iota + 1
+	// ResetTypeLastWorkflowTask resets the workflow to its last completed workflow task.
+	ResetTypeLastWorkflowTask
+	// ResetTypeBadBinary resets the workflow to the first workflow task completed by the worker binary
+	// with ResetWorkflowRequest.BinaryChecksum, so that the task is redone by a fixed worker.
+	ResetTypeBadBinary
+	// ResetTypeLastContinuedAsNew resets the run that continued as new into the given run to its last
+	// completed workflow task.
+	ResetTypeLastContinuedAsNew
+)
+
+// ResetWorkflowRequest is the request to ResetWorkflow
+type ResetWorkflowRequest struct {
+	// WorkflowID is a required field indicating the workflow which should be reset.
+	WorkflowID string
+
+	// RunID is an optional field used to identify a specific run of the workflow.
+	// If RunID is not provided the latest run will be used.
+	RunID string
+
+	// Reason is recorded in the history of the new run.
+	Reason string
+
+	// ResetType is a required field which selects the reset point, see ResetType for details.
+	ResetType ResetType
+
+	// EventID is the id of a WorkflowTaskCompleted, WorkflowTaskFailed or WorkflowTaskTimedOut event.
+	// Required for ResetTypeEventID.
+	EventID int64
+
+	// BinaryChecksum of the worker binary that produced the bad workflow tasks. Required for ResetTypeBadBinary.
+	BinaryChecksum string
+}
+
 // QueryWorkflowWithOptionsResponse is the response to QueryWorkflowWithOptions
 type QueryWorkflowWithOptionsResponse struct {
 	// QueryResult contains the result of executing the query.
@@ -891,10 +941,17 @@ type QueryWorkflowWithOptionsResponse struct {
 //  - EntityNotExistError
 //  - QueryFailError
 func (wc *WorkflowClient) QueryWorkflowWithOptions(ctx context.Context, request *QueryWorkflowWithOptionsRequest) (*QueryWorkflowWithOptionsResponse, error) {
+	return wc.interceptor.QueryWorkflow(ctx, request)
+}
+
+func (wc *WorkflowClient) queryWorkflow(ctx context.Context, request *QueryWorkflowWithOptionsRequest) (*QueryWorkflowWithOptionsResponse, error) {
 	var input *commonpb.Payloads
 	if len(request.Args) > 0 {
 		var err error
-		if input, err = encodeArgs(wc.dataConverter, request.Args); err != nil {
+		if input, err = encodeArgs(dataConverterWithContext(ctx, wc.dataConverter), request.Args); err != nil {
+			return nil, err
+		}
+		if err = wc.payloadSizeChecker.check(input, payloadOperationQueryInput, request.QueryType); err != nil {
 			return nil, err
 		}
 	}
@@ -932,7 +989,7 @@ func (wc *WorkflowClient) QueryWorkflowWithOptions(ctx context.Context, request
 	}
 	return &QueryWorkflowWithOptionsResponse{
 		QueryRejected: nil,
-		QueryResult:   newEncodedValue(resp.QueryResult, wc.dataConverter),
+		QueryResult:   newEncodedValue(resp.QueryResult, dataConverterWithContext(ctx, wc.dataConverter)),
 	}, nil
 }
 
@@ -977,6 +1034,22 @@ func (wc *WorkflowClient) Close() {
 	}
 }
 
+// startWorkflowSpan creates a workflow start span and attaches it to the context object.
+// N.B. we need to finish this immediately as jaeger does not give us a way
+// to recreate a span given a span context - which means we will run into
+// issues during replay. we work around this by creating and ending the
+// workflow start span and passing in that context to the workflow. So
+// everything beginning with the StartWorkflowExecutionRequest will be
+// parented by the created start workflow span.
+// Returned header is populated by the context propagators from the resulting context.
+func (wc *WorkflowClient) startWorkflowSpan(ctx context.Context, operation, workflowType, workflowID string) (context.Context, *commonpb.Header) {
+	ctx, span := createOpenTracingWorkflowSpan(ctx, wc.tracer, time.Now(), fmt.Sprintf("%s-%s", operation, workflowType), workflowID)
+	span.Finish()
+
+	// get workflow headers from the context
+	return ctx, wc.getWorkflowHeader(ctx)
+}
+
 func (wc *WorkflowClient) getWorkflowHeader(ctx context.Context) *commonpb.Header {
 	header := &commonpb.Header{
 		Fields: make(map[string]*commonpb.Payload),
@@ -1133,13 +1206,13 @@ func (workflowRun *workflowRunImpl) Get(ctx context.Context, valuePtr interface{
 		if rf.Type().Kind() != reflect.Ptr {
 			return errors.New("value parameter is not a pointer")
 		}
-		return workflowRun.dataConverter.FromPayloads(attributes.Result, valuePtr)
+		return dataConverterWithContext(ctx, workflowRun.dataConverter).FromPayloads(attributes.Result, valuePtr)
 	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED:
 		attributes := closeEvent.GetWorkflowExecutionFailedEventAttributes()
-		err = convertFailureToError(attributes.GetFailure(), workflowRun.dataConverter)
+		err = failureConverterWithContext(ctx, workflowRun.failureConverter).FailureToError(attributes.GetFailure())
 	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CANCELED:
 		attributes := closeEvent.GetWorkflowExecutionCanceledEventAttributes()
-		details := newEncodedValues(attributes.Details, workflowRun.dataConverter)
+		details := newEncodedValues(attributes.Details, dataConverterWithContext(ctx, workflowRun.dataConverter))
 		err = NewCanceledError(details)
 	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED:
 		err = newTerminatedError()
@@ -1194,3 +1267,238 @@ func serializeSearchAttributes(input map[string]interface{}) (*commonpb.SearchAt
 	}
 	return &commonpb.SearchAttributes{IndexedFields: attr}, nil
 }
+
+func newClientInterceptors(client *WorkflowClient, interceptors []ClientInterceptor) ClientOutboundCallsInterceptor {
+	var interceptor ClientOutboundCallsInterceptor = &workflowClientInterceptor{client: client}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor = interceptors[i].InterceptClient(interceptor)
+	}
+	return interceptor
+}
+
+func (w *workflowClientInterceptor) ExecuteWorkflow(
+	ctx context.Context,
+	header *commonpb.Header,
+	options StartWorkflowOptions,
+	workflowType string,
+	args ...interface{},
+) (WorkflowRun, error) {
+	wc := w.client
+
+	// start the workflow execution
+	var runID string
+	var workflowID string
+	executionInfo, err := wc.startWorkflow(ctx, header, options, workflowType, args...)
+	if err != nil {
+		if e, ok := err.(*serviceerror.WorkflowExecutionAlreadyStarted); ok {
+			runID = e.RunId
+			workflowID = options.ID
+		} else {
+			return nil, err
+		}
+	} else {
+		runID = executionInfo.RunID
+		workflowID = executionInfo.ID
+	}
+
+	iterFn := func(fnCtx context.Context, fnRunID string) HistoryEventIterator {
+		return wc.GetWorkflowHistory(fnCtx, workflowID, fnRunID, true, enumspb.HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT)
+	}
+
+	return &workflowRunImpl{
+		workflowFn:       workflowType,
+		workflowID:       workflowID,
+		firstRunID:       runID,
+		currentRunID:     runID,
+		iterFn:           iterFn,
+		dataConverter:    wc.dataConverter,
+		failureConverter: wc.failureConverter,
+		registry:         wc.registry,
+	}, nil
+}
+
+func (w *workflowClientInterceptor) StartWorkflow(
+	ctx context.Context,
+	header *commonpb.Header,
+	options StartWorkflowOptions,
+	workflowType string,
+	args ...interface{},
+) (*WorkflowExecution, error) {
+	return w.client.startWorkflow(ctx, header, options, workflowType, args...)
+}
+
+func (w *workflowClientInterceptor) SignalWorkflow(ctx context.Context, workflowID, runID, signalName string, arg interface{}) error {
+	wc := w.client
+	input, err := encodeArg(dataConverterWithContext(ctx, wc.dataConverter), arg)
+	if err != nil {
+		return err
+	}
+	if err := wc.payloadSizeChecker.check(input, payloadOperationSignalInput, signalName); err != nil {
+		return err
+	}
+
+	request := &workflowservice.SignalWorkflowExecutionRequest{
+		Namespace: wc.namespace,
+		WorkflowExecution: &commonpb.WorkflowExecution{
+			WorkflowId: workflowID,
+			RunId:      runID,
+		},
+		SignalName: signalName,
+		Input:      input,
+		Identity:   wc.identity,
+	}
+
+	return backoff.Retry(ctx,
+		func() error {
+			tchCtx, cancel := newChannelContext(ctx)
+			defer cancel()
+			_, err := wc.workflowService.SignalWorkflowExecution(tchCtx, request)
+			return err
+		}, createDynamicServiceRetryPolicy(ctx), isServiceTransientError)
+}
+
+func (w *workflowClientInterceptor) SignalWithStartWorkflow(ctx context.Context, header *commonpb.Header, workflowID, signalName string, signalArg interface{},
+	options StartWorkflowOptions, workflowType string, workflowArgs ...interface{}) (WorkflowRun, error) {
+	wc := w.client
+
+	signalInput, err := encodeArg(dataConverterWithContext(ctx, wc.dataConverter), signalArg)
+	if err != nil {
+		return nil, err
+	}
+	if err := wc.payloadSizeChecker.check(signalInput, payloadOperationSignalInput, signalName); err != nil {
+		return nil, err
+	}
+
+	executionTimeout := common.Int32Ceil(options.WorkflowExecutionTimeout.Seconds())
+	runTimeout := common.Int32Ceil(options.WorkflowRunTimeout.Seconds())
+	taskTimeout := common.Int32Ceil(options.WorkflowTaskTimeout.Seconds())
+
+	input, err := encodeArgs(dataConverterWithContext(ctx, wc.dataConverter), workflowArgs)
+	if err != nil {
+		return nil, err
+	}
+	if err := wc.payloadSizeChecker.check(input, payloadOperationWorkflowInput, workflowType); err != nil {
+		return nil, err
+	}
+
+	memo, err := getWorkflowMemo(options.Memo, wc.dataConverter)
+	if err != nil {
+		return nil, err
+	}
+
+	searchAttr, err := serializeSearchAttributes(options.SearchAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	signalWithStartRequest := &workflowservice.SignalWithStartWorkflowExecutionRequest{
+		Namespace:                       wc.namespace,
+		RequestId:                       uuid.New(),
+		WorkflowId:                      workflowID,
+		WorkflowType:                    &commonpb.WorkflowType{Name: workflowType},
+		TaskQueue:                       &taskqueuepb.TaskQueue{Name: options.TaskQueue, Kind: enumspb.TASK_QUEUE_KIND_NORMAL},
+		Input:                           input,
+		WorkflowExecutionTimeoutSeconds: executionTimeout,
+		WorkflowRunTimeoutSeconds:       runTimeout,
+		WorkflowTaskTimeoutSeconds:      taskTimeout,
+		SignalName:                      signalName,
+		SignalInput:                     signalInput,
+		Identity:                        wc.identity,
+		RetryPolicy:                     convertRetryPolicy(options.RetryPolicy),
+		CronSchedule:                    options.CronSchedule,
+		Memo:                            memo,
+		SearchAttributes:                searchAttr,
+		WorkflowIdReusePolicy:           options.WorkflowIDReusePolicy,
+		Header:                          header,
+	}
+
+	var response *workflowservice.SignalWithStartWorkflowExecutionResponse
+
+	// Start creating workflow request.
+	err = backoff.Retry(ctx,
+		func() error {
+			tchCtx, cancel := newChannelContext(ctx)
+			defer cancel()
+
+			var err1 error
+			response, err1 = wc.workflowService.SignalWithStartWorkflowExecution(tchCtx, signalWithStartRequest)
+			return err1
+		}, createDynamicServiceRetryPolicy(ctx), isServiceTransientError)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if wc.metricsScope != nil {
+		scope := wc.metricsScope.GetTaggedScope(tagTaskQueue, options.TaskQueue, tagWorkflowType, workflowType)
+		scope.Counter(metrics.WorkflowSignalWithStartCounter).Inc(1)
+	}
+
+	iterFn := func(fnCtx context.Context, fnRunID string) HistoryEventIterator {
+		return wc.GetWorkflowHistory(fnCtx, workflowID, fnRunID, true, enumspb.HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT)
+	}
+
+	return &workflowRunImpl{
+		workflowFn:       workflowType,
+		workflowID:       workflowID,
+		firstRunID:       response.GetRunId(),
+		currentRunID:     response.GetRunId(),
+		iterFn:           iterFn,
+		dataConverter:    wc.dataConverter,
+		failureConverter: wc.failureConverter,
+		registry:         wc.registry,
+	}, nil
+}
+
+func (w *workflowClientInterceptor) CancelWorkflow(ctx context.Context, workflowID, runID string) error {
+	wc := w.client
+	request := &workflowservice.RequestCancelWorkflowExecutionRequest{
+		Namespace: wc.namespace,
+		WorkflowExecution: &commonpb.WorkflowExecution{
+			WorkflowId: workflowID,
+			RunId:      runID,
+		},
+		Identity: wc.identity,
+	}
+
+	return backoff.Retry(ctx,
+		func() error {
+			tchCtx, cancel := newChannelContext(ctx)
+			defer cancel()
+			_, err := wc.workflowService.RequestCancelWorkflowExecution(tchCtx, request)
+			return err
+		}, createDynamicServiceRetryPolicy(ctx), isServiceTransientError)
+}
+
+func (w *workflowClientInterceptor) TerminateWorkflow(ctx context.Context, workflowID, runID, reason string, details ...interface{}) error {
+	wc := w.client
+	datailsPayload, err := dataConverterWithContext(ctx, wc.dataConverter).ToPayloads(details...)
+	if err != nil {
+		return err
+	}
+
+	request := &workflowservice.TerminateWorkflowExecutionRequest{
+		Namespace: wc.namespace,
+		WorkflowExecution: &commonpb.WorkflowExecution{
+			WorkflowId: workflowID,
+			RunId:      runID,
+		},
+		Reason:   reason,
+		Identity: wc.identity,
+		Details:  datailsPayload,
+	}
+
+	err = backoff.Retry(ctx,
+		func() error {
+			tchCtx, cancel := newChannelContext(ctx)
+			defer cancel()
+			_, err := wc.workflowService.TerminateWorkflowExecution(tchCtx, request)
+			return err
+		}, createDynamicServiceRetryPolicy(ctx), isServiceTransientError)
+
+	return err
+}
+
+func (w *workflowClientInterceptor) QueryWorkflow(ctx context.Context, request *QueryWorkflowWithOptionsRequest) (*QueryWorkflowWithOptionsResponse, error) {
+	return w.client.queryWorkflow(ctx, request)
+}
@@ -28,6 +28,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -37,6 +38,7 @@ import (
 	enumspb "go.temporal.io/api/enums/v1"
 	historypb "go.temporal.io/api/history/v1"
 	"go.temporal.io/api/serviceerror"
+	workflowpb "go.temporal.io/api/workflow/v1"
 	"go.temporal.io/api/workflowservice/v1"
 	"go.temporal.io/api/workflowservicemock/v1"
 
@@ -1312,6 +1314,358 @@ func (s *workflowClientTestSuite) TestGetSearchAttributes() {
 	s.IsType(&serviceerror.InvalidArgument{}, err)
 }
 
+type testClientInterceptor struct {
+	calls []string
+}
+
+type testClientOutboundCallsInterceptor struct {
+	ClientOutboundCallsInterceptorBase
+	parent *testClientInterceptor
+}
+
+func (t *testClientInterceptor) InterceptClient(next ClientOutboundCallsInterceptor) ClientOutboundCallsInterceptor {
+	return &testClientOutboundCallsInterceptor{ClientOutboundCallsInterceptorBase{Next: next}, t}
+}
+
+func (t *testClientOutboundCallsInterceptor) ExecuteWorkflow(ctx context.Context, header *commonpb.Header, options StartWorkflowOptions, workflowType string, args ...interface{}) (WorkflowRun, error) {
+	t.parent.calls = append(t.parent.calls, "ExecuteWorkflow:"+workflowType)
+	payload, _ := converter.GetDefaultDataConverter().ToPayload("intercepted")
+	header.Fields["interceptor"] = payload
+	return t.Next.ExecuteWorkflow(ctx, header, options, workflowType, "redacted")
+}
+
+func (t *testClientOutboundCallsInterceptor) StartWorkflow(ctx context.Context, header *commonpb.Header, options StartWorkflowOptions, workflowType string, args ...interface{}) (*WorkflowExecution, error) {
+	t.parent.calls = append(t.parent.calls, "StartWorkflow:"+workflowType)
+	return t.Next.StartWorkflow(ctx, header, options, workflowType, "redacted")
+}
+
+func (t *testClientOutboundCallsInterceptor) SignalWorkflow(ctx context.Context, workflowID, runID, signalName string, arg interface{}) error {
+	t.parent.calls = append(t.parent.calls, "SignalWorkflow:"+signalName)
+	return t.Next.SignalWorkflow(ctx, workflowID, runID, signalName, arg)
+}
+
+func (t *testClientOutboundCallsInterceptor) CancelWorkflow(ctx context.Context, workflowID, runID string) error {
+	t.parent.calls = append(t.parent.calls, "CancelWorkflow:"+workflowID)
+	return t.Next.CancelWorkflow(ctx, workflowID, runID)
+}
+
+func (s *workflowClientTestSuite) TestClientInterceptors() {
+	interceptor := &testClientInterceptor{}
+	s.client = NewServiceClient(s.service, nil, ClientOptions{Interceptors: []ClientInterceptor{interceptor}})
+	options := StartWorkflowOptions{
+		ID:                       workflowID,
+		TaskQueue:                taskqueue,
+		WorkflowExecutionTimeout: timeoutInSeconds,
+		WorkflowTaskTimeout:      timeoutInSeconds,
+	}
+	wf := func(ctx Context, secret string) string {
+		return "result"
+	}
+
+	s.service.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(&workflowservice.StartWorkflowExecutionResponse{RunId: runID}, nil).
+		Do(func(_ interface{}, req *workflowservice.StartWorkflowExecutionRequest, _ ...interface{}) {
+			var input, header string
+			s.NoError(s.dataConverter.FromPayloads(req.Input, &input))
+			s.Equal("redacted", input)
+			s.NoError(s.dataConverter.FromPayload(req.Header.Fields["interceptor"], &header))
+			s.Equal("intercepted", header)
+		})
+	run, err := s.client.ExecuteWorkflow(context.Background(), options, wf, "secret")
+	s.NoError(err)
+	s.Equal(runID, run.GetRunID())
+	// The run keeps the workflow function, the interceptors only get the workflow type name.
+	s.Equal(reflect.ValueOf(wf).Pointer(), reflect.ValueOf(run.(*workflowRunImpl).workflowFn).Pointer())
+
+	s.service.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(&workflowservice.StartWorkflowExecutionResponse{RunId: runID}, nil).
+		Do(func(_ interface{}, req *workflowservice.StartWorkflowExecutionRequest, _ ...interface{}) {
+			var input string
+			s.NoError(s.dataConverter.FromPayloads(req.Input, &input))
+			s.Equal("redacted", input)
+		})
+	execution, err := s.client.(*WorkflowClient).StartWorkflow(context.Background(), options, wf, "secret")
+	s.NoError(err)
+	s.Equal(runID, execution.RunID)
+
+	s.service.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, serviceerror.NewWorkflowExecutionAlreadyStarted("Already Started", "", runID))
+	_, err = s.client.(*WorkflowClient).StartWorkflow(context.Background(), options, wf, "secret")
+	s.IsType(&serviceerror.WorkflowExecutionAlreadyStarted{}, err)
+
+	s.service.EXPECT().SignalWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(&workflowservice.SignalWorkflowExecutionResponse{}, nil)
+	s.NoError(s.client.SignalWorkflow(context.Background(), workflowID, runID, "my signal", "arg"))
+
+	s.service.EXPECT().RequestCancelWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(&workflowservice.RequestCancelWorkflowExecutionResponse{}, nil)
+	s.NoError(s.client.CancelWorkflow(context.Background(), workflowID, runID))
+
+	s.Equal([]string{
+		"ExecuteWorkflow:" + getFunctionName(wf),
+		"StartWorkflow:" + getFunctionName(wf),
+		"StartWorkflow:" + getFunctionName(wf),
+		"SignalWorkflow:my signal",
+		"CancelWorkflow:" + workflowID,
+	}, interceptor.calls)
+}
+
+func (s *workflowClientTestSuite) TestResetWorkflow() {
+	previousRunID := "previous run"
+	histories := map[string][]*historypb.HistoryEvent{
+		previousRunID: {
+			createTestEventWorkflowExecutionStarted(1, &historypb.WorkflowExecutionStartedEventAttributes{}),
+			createTestEventWorkflowTaskCompleted(4, &historypb.WorkflowTaskCompletedEventAttributes{BinaryChecksum: "good"}),
+			createTestEventWorkflowTaskCompleted(8, &historypb.WorkflowTaskCompletedEventAttributes{BinaryChecksum: "good"}),
+		},
+		runID: {
+			createTestEventWorkflowExecutionStarted(1, &historypb.WorkflowExecutionStartedEventAttributes{ContinuedExecutionRunId: previousRunID}),
+			createTestEventWorkflowTaskCompleted(4, &historypb.WorkflowTaskCompletedEventAttributes{BinaryChecksum: "good"}),
+			createTestEventWorkflowTaskCompleted(8, &historypb.WorkflowTaskCompletedEventAttributes{BinaryChecksum: "bad"}),
+			createTestEventWorkflowTaskCompleted(12, &historypb.WorkflowTaskCompletedEventAttributes{BinaryChecksum: "bad"}),
+		},
+	}
+	s.service.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, request *workflowservice.GetWorkflowExecutionHistoryRequest, _ ...interface{}) (*workflowservice.GetWorkflowExecutionHistoryResponse, error) {
+			return &workflowservice.GetWorkflowExecutionHistoryResponse{
+				History: &historypb.History{Events: histories[request.GetExecution().GetRunId()]},
+			}, nil
+		}).AnyTimes()
+
+	var resetRequest *workflowservice.ResetWorkflowExecutionRequest
+	s.service.EXPECT().ResetWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, request *workflowservice.ResetWorkflowExecutionRequest, _ ...interface{}) (*workflowservice.ResetWorkflowExecutionResponse, error) {
+			resetRequest = request
+			return &workflowservice.ResetWorkflowExecutionResponse{RunId: "new run"}, nil
+		}).Times(4)
+
+	tests := []struct {
+		request         ResetWorkflowRequest
+		expectedRunID   string
+		expectedEventID int64
+	}{
+		{ResetWorkflowRequest{ResetType: ResetTypeEventID, EventID: 4}, runID, 4},
+		{ResetWorkflowRequest{ResetType: ResetTypeLastWorkflowTask}, runID, 12},
+		{ResetWorkflowRequest{ResetType: ResetTypeBadBinary, BinaryChecksum: "bad"}, runID, 8},
+		{ResetWorkflowRequest{ResetType: ResetTypeLastContinuedAsNew}, previousRunID, 8},
+	}
+	for _, test := range tests {
+		test.request.WorkflowID = workflowID
+		test.request.RunID = runID
+		test.request.Reason = "bad deploy"
+		response, err := s.client.ResetWorkflow(context.Background(), &test.request)
+		s.NoError(err)
+		s.Equal("new run", response.GetRunId())
+		s.Equal(test.expectedRunID, resetRequest.GetWorkflowExecution().GetRunId())
+		s.Equal(test.expectedEventID, resetRequest.GetWorkflowTaskFinishEventId())
+		s.Equal("bad deploy", resetRequest.GetReason())
+	}
+
+	_, err := s.client.ResetWorkflow(context.Background(), &ResetWorkflowRequest{
+		WorkflowID: workflowID, RunID: runID, ResetType: ResetTypeBadBinary, BinaryChecksum: "unknown"})
+	s.Error(err)
+	_, err = s.client.ResetWorkflow(context.Background(), &ResetWorkflowRequest{
+		WorkflowID: workflowID, RunID: previousRunID, ResetType: ResetTypeLastContinuedAsNew})
+	s.Error(err)
+}
+
+func (s *workflowClientTestSuite) TestBatchOperation() {
+	executionInfo := func(id string) *workflowpb.WorkflowExecutionInfo {
+		return &workflowpb.WorkflowExecutionInfo{Execution: &commonpb.WorkflowExecution{WorkflowId: id, RunId: runID}}
+	}
+	pages := map[string]*workflowservice.ListWorkflowExecutionsResponse{
+		"": {
+			Executions:    []*workflowpb.WorkflowExecutionInfo{executionInfo("wid1"), executionInfo("wid2")},
+			NextPageToken: []byte("page2"),
+		},
+		"page2": {
+			Executions: []*workflowpb.WorkflowExecutionInfo{executionInfo("wid3")},
+		},
+	}
+	s.service.EXPECT().ListWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, request *workflowservice.ListWorkflowExecutionsRequest, _ ...interface{}) (*workflowservice.ListWorkflowExecutionsResponse, error) {
+			s.Equal("ExecutionStatus='Running'", request.GetQuery())
+			return pages[string(request.GetNextPageToken())], nil
+		}).Times(3)
+	s.service.EXPECT().TerminateWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, request *workflowservice.TerminateWorkflowExecutionRequest, _ ...interface{}) (*workflowservice.TerminateWorkflowExecutionResponse, error) {
+			s.Equal("cleanup", request.GetReason())
+			if request.GetWorkflowExecution().GetWorkflowId() == "wid2" {
+				return nil, serviceerror.NewInvalidArgument("terminate failed")
+			}
+			return &workflowservice.TerminateWorkflowExecutionResponse{}, nil
+		}).Times(4)
+
+	var progress []int
+	options := BatchOperationOptions{
+		Query:       "ExecutionStatus='Running'",
+		Type:        BatchOperationTerminate,
+		Reason:      "cleanup",
+		Concurrency: 2,
+		RPS:         100,
+		OnProgress: func(result BatchOperationResult) {
+			progress = append(progress, result.Processed)
+		},
+	}
+	result, err := s.client.BatchOperation(context.Background(), options)
+	s.NoError(err)
+	s.Equal(3, result.Processed)
+	s.Empty(result.NextPageToken)
+	s.Equal([]int{2, 3}, progress)
+	s.Equal(1, len(result.Failures))
+	s.Equal(WorkflowExecution{ID: "wid2", RunID: runID}, result.Failures[0].Execution)
+	s.Error(result.Failures[0].Err)
+
+	// Resume from the second page.
+	options.OnProgress = nil
+	options.NextPageToken = []byte("page2")
+	result, err = s.client.BatchOperation(context.Background(), options)
+	s.NoError(err)
+	s.Equal(1, result.Processed)
+	s.Empty(result.Failures)
+}
+
+func (s *workflowClientTestSuite) TestBatchOperation_Resume() {
+	executions := []*workflowpb.WorkflowExecutionInfo{
+		{Execution: &commonpb.WorkflowExecution{WorkflowId: "wid1", RunId: runID}},
+		{Execution: &commonpb.WorkflowExecution{WorkflowId: "wid2", RunId: runID}},
+		{Execution: &commonpb.WorkflowExecution{WorkflowId: "wid3", RunId: runID}},
+	}
+	s.service.EXPECT().ListWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&workflowservice.ListWorkflowExecutionsResponse{Executions: executions}, nil).Times(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	var terminated []string
+	s.service.EXPECT().TerminateWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, request *workflowservice.TerminateWorkflowExecutionRequest, _ ...interface{}) (*workflowservice.TerminateWorkflowExecutionResponse, error) {
+			terminated = append(terminated, request.GetWorkflowExecution().GetWorkflowId())
+			if len(terminated) == 1 {
+				// Interrupt the batch while the only concurrency token is held.
+				cancel()
+				time.Sleep(50 * time.Millisecond)
+			}
+			return &workflowservice.TerminateWorkflowExecutionResponse{}, nil
+		}).Times(3)
+
+	options := BatchOperationOptions{
+		Query:       "ExecutionStatus='Running'",
+		Type:        BatchOperationTerminate,
+		Concurrency: 1,
+	}
+	result, err := s.client.BatchOperation(ctx, options)
+	s.Equal(context.Canceled, err)
+	s.Equal(1, result.Processed)
+	s.Equal([]WorkflowExecution{{ID: "wid1", RunID: runID}}, result.ProcessedExecutions)
+
+	options.NextPageToken = result.NextPageToken
+	options.ProcessedExecutions = result.ProcessedExecutions
+	result, err = s.client.BatchOperation(context.Background(), options)
+	s.NoError(err)
+	s.Equal(2, result.Processed)
+	s.Empty(result.ProcessedExecutions)
+	s.Equal([]string{"wid1", "wid2", "wid3"}, terminated)
+}
+
+func (s *workflowClientTestSuite) TestBatchOperation_ResumeAfterFailure() {
+	executions := []*workflowpb.WorkflowExecutionInfo{
+		{Execution: &commonpb.WorkflowExecution{WorkflowId: "wid1", RunId: runID}},
+		{Execution: &commonpb.WorkflowExecution{WorkflowId: "wid2", RunId: runID}},
+		{Execution: &commonpb.WorkflowExecution{WorkflowId: "wid3", RunId: runID}},
+	}
+	s.service.EXPECT().ListWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&workflowservice.ListWorkflowExecutionsResponse{Executions: executions}, nil).Times(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	var terminated []string
+	s.service.EXPECT().TerminateWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, request *workflowservice.TerminateWorkflowExecutionRequest, _ ...interface{}) (*workflowservice.TerminateWorkflowExecutionResponse, error) {
+			terminated = append(terminated, request.GetWorkflowExecution().GetWorkflowId())
+			switch len(terminated) {
+			case 1:
+				return nil, serviceerror.NewNotFound("workflow execution already completed")
+			case 2:
+				// The batch is interrupted while the operation is running.
+				cancel()
+				return nil, context.Canceled
+			}
+			return &workflowservice.TerminateWorkflowExecutionResponse{}, nil
+		}).Times(4)
+
+	options := BatchOperationOptions{
+		Query:       "ExecutionStatus='Running'",
+		Type:        BatchOperationTerminate,
+		Concurrency: 1,
+	}
+	result, err := s.client.BatchOperation(ctx, options)
+	s.Equal(context.Canceled, err)
+	s.Equal(2, result.Processed)
+	s.Len(result.Failures, 2)
+	// The execution failed by the cancellation isn't processed, so the resumed batch retries it.
+	s.Equal([]WorkflowExecution{{ID: "wid1", RunID: runID}}, result.ProcessedExecutions)
+
+	options.NextPageToken = result.NextPageToken
+	options.ProcessedExecutions = result.ProcessedExecutions
+	result, err = s.client.BatchOperation(context.Background(), options)
+	s.NoError(err)
+	s.Equal(2, result.Processed)
+	s.Empty(result.Failures)
+	s.Equal([]string{"wid1", "wid2", "wid2", "wid3"}, terminated)
+}
+
+func (s *workflowClientTestSuite) TestListWorkflowExecutions() {
+	memo, err := getWorkflowMemo(map[string]interface{}{"owner": "alice"}, s.dataConverter)
+	s.NoError(err)
+	searchAttributes, err := serializeSearchAttributes(map[string]interface{}{"CustomIntField": 42})
+	s.NoError(err)
+	executionInfo := func(id string) *workflowpb.WorkflowExecutionInfo {
+		return &workflowpb.WorkflowExecutionInfo{
+			Execution:        &commonpb.WorkflowExecution{WorkflowId: id, RunId: runID},
+			Type:             &commonpb.WorkflowType{Name: workflowType},
+			Memo:             memo,
+			SearchAttributes: searchAttributes,
+		}
+	}
+	pages := map[string]*workflowservice.ScanWorkflowExecutionsResponse{
+		"": {
+			Executions:    []*workflowpb.WorkflowExecutionInfo{executionInfo("wid1"), executionInfo("wid2")},
+			NextPageToken: []byte("page2"),
+		},
+		"page2": {
+			NextPageToken: []byte("page3"),
+		},
+		"page3": {
+			Executions: []*workflowpb.WorkflowExecutionInfo{executionInfo("wid3")},
+		},
+	}
+	s.service.EXPECT().ScanWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, request *workflowservice.ScanWorkflowExecutionsRequest, _ ...interface{}) (*workflowservice.ScanWorkflowExecutionsResponse, error) {
+			s.Equal("WorkflowType='"+workflowType+"'", request.GetQuery())
+			return pages[string(request.GetNextPageToken())], nil
+		}).Times(3)
+
+	request := &workflowservice.ScanWorkflowExecutionsRequest{Query: "WorkflowType='" + workflowType + "'"}
+	iter := s.client.ListWorkflowExecutions(context.Background(), ListWorkflowExecutionsOptions{
+		ScanRequest: request,
+		Prefetch:    true,
+	})
+	var ids []string
+	for iter.HasNext() {
+		execution, err := iter.Next()
+		s.NoError(err)
+		ids = append(ids, execution.Execution.ID)
+		s.Equal(workflowType, execution.WorkflowType)
+
+		var owner string
+		s.NoError(execution.Memo["owner"].Get(&owner))
+		s.Equal("alice", owner)
+		var customIntField int
+		s.NoError(execution.SearchAttributes["CustomIntField"].Get(&customIntField))
+		s.Equal(42, customIntField)
+	}
+	s.Equal([]string{"wid1", "wid2", "wid3"}, ids)
+	s.Nil(request.NextPageToken)
+
+	iter = s.client.ListWorkflowExecutions(context.Background(), ListWorkflowExecutionsOptions{})
+	s.True(iter.HasNext())
+	_, err = iter.Next()
+	s.Error(err)
+	s.False(iter.HasNext())
+}
+
 func serializeEvents(events []*historypb.HistoryEvent) *commonpb.DataBlob {
 	blob, _ := serializer.SerializeBatchEvents(events, enumspb.ENCODING_TYPE_PROTO3)
 
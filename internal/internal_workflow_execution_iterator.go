@@ -0,0 +1,330 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+
+	"go.temporal.io/sdk/converter"
+)
+
+type (
+	// ListWorkflowExecutionsOptions selects the visibility API used by a WorkflowExecutionIterator. Exactly one of
+	// the requests must be set. The NextPageToken of the request is used as the first page to fetch, the request itself
+	// is not modified by the iterator.
+	ListWorkflowExecutionsOptions struct {
+		ListRequest         *workflowservice.ListWorkflowExecutionsRequest
+		ListOpenRequest     *workflowservice.ListOpenWorkflowExecutionsRequest
+		ListClosedRequest   *workflowservice.ListClosedWorkflowExecutionsRequest
+		ListArchivedRequest *workflowservice.ListArchivedWorkflowExecutionsRequest
+		ScanRequest         *workflowservice.ScanWorkflowExecutionsRequest
+
+		// Prefetch requests the next page in the background while the current page is consumed.
+		Prefetch bool
+	}
+
+	// WorkflowExecutionIterator represents the interface for
+	// workflow execution iterator
+	WorkflowExecutionIterator interface {
+		// HasNext return whether this iterator has next value
+		HasNext() bool
+		// Next returns the next workflow execution and error
+		// The errors it can return:
+		//	- BadRequestError
+		//	- InternalServiceError
+		//	- EntityNotExistError
+		Next() (*WorkflowExecutionInfo, error)
+	}
+
+	// WorkflowExecutionInfo is a workflow execution returned by the visibility APIs, with memo and search attributes
+	// decoded through the DataConverter of the client.
+	WorkflowExecutionInfo struct {
+		Execution     WorkflowExecution
+		WorkflowType  string
+		TaskQueue     string
+		Status        enumspb.WorkflowExecutionStatus
+		StartTime     time.Time
+		ExecutionTime time.Time
+		// CloseTime is zero for open workflow executions.
+		CloseTime         time.Time
+		HistoryLength     int64
+		ParentNamespaceID string
+		// ParentExecution is nil if the workflow execution is not a child workflow.
+		ParentExecution  *WorkflowExecution
+		Memo             map[string]converter.EncodedValue
+		SearchAttributes map[string]converter.EncodedValue
+	}
+
+	// workflowExecutionIteratorImpl is the implementation of WorkflowExecutionIterator
+	workflowExecutionIteratorImpl struct {
+		// whether this iterator is initialized
+		initialized bool
+		// local cached workflow executions and corresponding consuming index
+		nextExecutionIndex int
+		executions         []*workflowpb.WorkflowExecutionInfo
+		// token to get next page of workflow executions
+		nexttoken []byte
+		// err when getting next page of workflow executions
+		err error
+		// func which use a next token to get next page of workflow executions
+		paginate func(nexttoken []byte) (*workflowExecutionPage, error)
+		// whether the next page is fetched in the background and the channel it is delivered to
+		prefetch      bool
+		prefetched    chan workflowExecutionPageResult
+		dataConverter converter.DataConverter
+	}
+
+	workflowExecutionPage struct {
+		executions []*workflowpb.WorkflowExecutionInfo
+		nexttoken  []byte
+	}
+
+	workflowExecutionPageResult struct {
+		page *workflowExecutionPage
+		err  error
+	}
+)
+
+// ListWorkflowExecutions returns an iterator over the workflow executions returned by the visibility API selected
+// in the options. Pages are fetched lazily when the iterator runs out of cached executions.
+func (wc *WorkflowClient) ListWorkflowExecutions(ctx context.Context, options ListWorkflowExecutionsOptions) WorkflowExecutionIterator {
+	return &workflowExecutionIteratorImpl{
+		paginate:      wc.getWorkflowExecutionPaginate(ctx, options),
+		prefetch:      options.Prefetch,
+		dataConverter: wc.dataConverter,
+	}
+}
+
+func (wc *WorkflowClient) getWorkflowExecutionPaginate(ctx context.Context, options ListWorkflowExecutionsOptions) func(nexttoken []byte) (*workflowExecutionPage, error) {
+	requests := 0
+	for _, set := range []bool{
+		options.ListRequest != nil,
+		options.ListOpenRequest != nil,
+		options.ListClosedRequest != nil,
+		options.ListArchivedRequest != nil,
+		options.ScanRequest != nil,
+	} {
+		if set {
+			requests++
+		}
+	}
+	if requests != 1 {
+		return func(nexttoken []byte) (*workflowExecutionPage, error) {
+			return nil, errors.New("exactly one request must be set in ListWorkflowExecutionsOptions")
+		}
+	}
+
+	// Requests are copied so the caller's request is neither mutated nor shared with a prefetching goroutine.
+	switch {
+	case options.ListRequest != nil:
+		request := *options.ListRequest
+		return func(nexttoken []byte) (*workflowExecutionPage, error) {
+			request := request
+			if nexttoken != nil {
+				request.NextPageToken = nexttoken
+			}
+			response, err := wc.ListWorkflow(ctx, &request)
+			if err != nil {
+				return nil, err
+			}
+			return &workflowExecutionPage{executions: response.GetExecutions(), nexttoken: response.GetNextPageToken()}, nil
+		}
+	case options.ListOpenRequest != nil:
+		request := *options.ListOpenRequest
+		return func(nexttoken []byte) (*workflowExecutionPage, error) {
+			request := request
+			if nexttoken != nil {
+				request.NextPageToken = nexttoken
+			}
+			response, err := wc.ListOpenWorkflow(ctx, &request)
+			if err != nil {
+				return nil, err
+			}
+			return &workflowExecutionPage{executions: response.GetExecutions(), nexttoken: response.GetNextPageToken()}, nil
+		}
+	case options.ListClosedRequest != nil:
+		request := *options.ListClosedRequest
+		return func(nexttoken []byte) (*workflowExecutionPage, error) {
+			request := request
+			if nexttoken != nil {
+				request.NextPageToken = nexttoken
+			}
+			response, err := wc.ListClosedWorkflow(ctx, &request)
+			if err != nil {
+				return nil, err
+			}
+			return &workflowExecutionPage{executions: response.GetExecutions(), nexttoken: response.GetNextPageToken()}, nil
+		}
+	case options.ListArchivedRequest != nil:
+		request := *options.ListArchivedRequest
+		return func(nexttoken []byte) (*workflowExecutionPage, error) {
+			request := request
+			if nexttoken != nil {
+				request.NextPageToken = nexttoken
+			}
+			response, err := wc.ListArchivedWorkflow(ctx, &request)
+			if err != nil {
+				return nil, err
+			}
+			return &workflowExecutionPage{executions: response.GetExecutions(), nexttoken: response.GetNextPageToken()}, nil
+		}
+	default:
+		request := *options.ScanRequest
+		return func(nexttoken []byte) (*workflowExecutionPage, error) {
+			request := request
+			if nexttoken != nil {
+				request.NextPageToken = nexttoken
+			}
+			response, err := wc.ScanWorkflow(ctx, &request)
+			if err != nil {
+				return nil, err
+			}
+			return &workflowExecutionPage{executions: response.GetExecutions(), nexttoken: response.GetNextPageToken()}, nil
+		}
+	}
+}
+
+func (iter *workflowExecutionIteratorImpl) HasNext() bool {
+	if iter.nextExecutionIndex < len(iter.executions) || iter.err != nil {
+		return true
+	}
+
+	// skip over empty pages which still carry a next page token
+	for !iter.initialized || len(iter.nexttoken) != 0 {
+		iter.initialized = true
+		page, err := iter.nextPage()
+		iter.nextExecutionIndex = 0
+		if err == nil {
+			iter.executions = page.executions
+			iter.nexttoken = page.nexttoken
+			iter.err = nil
+		} else {
+			iter.executions = nil
+			iter.nexttoken = nil
+			iter.err = err
+		}
+
+		if iter.prefetch && len(iter.nexttoken) != 0 {
+			iter.prefetchPage(iter.nexttoken)
+		}
+
+		if iter.nextExecutionIndex < len(iter.executions) || iter.err != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (iter *workflowExecutionIteratorImpl) Next() (*WorkflowExecutionInfo, error) {
+	if !iter.HasNext() {
+		panic("WorkflowExecutionIterator Next() called without checking HasNext()")
+	}
+
+	// we have cached executions
+	if iter.nextExecutionIndex < len(iter.executions) {
+		index := iter.nextExecutionIndex
+		iter.nextExecutionIndex++
+		return convertWorkflowExecutionInfo(iter.executions[index], iter.dataConverter), nil
+	} else if iter.err != nil {
+		// we have err, clear that iter.err and return err
+		err := iter.err
+		iter.err = nil
+		return nil, err
+	}
+
+	panic("WorkflowExecutionIterator Next() should return either a workflow execution or a err")
+}
+
+// nextPage returns the page prefetched in the background if there is one, otherwise it fetches the page of the
+// current token.
+func (iter *workflowExecutionIteratorImpl) nextPage() (*workflowExecutionPage, error) {
+	if iter.prefetched != nil {
+		result := <-iter.prefetched
+		iter.prefetched = nil
+		return result.page, result.err
+	}
+	return iter.paginate(iter.nexttoken)
+}
+
+func (iter *workflowExecutionIteratorImpl) prefetchPage(nexttoken []byte) {
+	prefetched := make(chan workflowExecutionPageResult, 1)
+	iter.prefetched = prefetched
+	go func() {
+		page, err := iter.paginate(nexttoken)
+		prefetched <- workflowExecutionPageResult{page: page, err: err}
+	}()
+}
+
+func convertWorkflowExecutionInfo(info *workflowpb.WorkflowExecutionInfo, dc converter.DataConverter) *WorkflowExecutionInfo {
+	result := &WorkflowExecutionInfo{
+		Execution: WorkflowExecution{
+			ID:    info.GetExecution().GetWorkflowId(),
+			RunID: info.GetExecution().GetRunId(),
+		},
+		WorkflowType:      info.GetType().GetName(),
+		TaskQueue:         info.GetTaskQueue(),
+		Status:            info.GetStatus(),
+		StartTime:         unixNanoToTime(info.GetStartTime().GetValue()),
+		ExecutionTime:     unixNanoToTime(info.GetExecutionTime()),
+		CloseTime:         unixNanoToTime(info.GetCloseTime().GetValue()),
+		HistoryLength:     info.GetHistoryLength(),
+		ParentNamespaceID: info.GetParentNamespaceId(),
+		Memo:              convertPayloadMap(info.GetMemo().GetFields(), dc),
+		SearchAttributes:  convertPayloadMap(info.GetSearchAttributes().GetIndexedFields(), dc),
+	}
+	if parent := info.GetParentExecution(); parent != nil {
+		result.ParentExecution = &WorkflowExecution{
+			ID:    parent.GetWorkflowId(),
+			RunID: parent.GetRunId(),
+		}
+	}
+	return result
+}
+
+func convertPayloadMap(fields map[string]*commonpb.Payload, dc converter.DataConverter) map[string]converter.EncodedValue {
+	if fields == nil {
+		return nil
+	}
+	result := make(map[string]converter.EncodedValue, len(fields))
+	for k, payload := range fields {
+		result[k] = newEncodedValue(&commonpb.Payloads{Payloads: []*commonpb.Payload{payload}}, dc)
+	}
+	return result
+}
+
+func unixNanoToTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	commonpb "go.temporal.io/api/common/v1"
 
 	"go.temporal.io/sdk/converter"
 	"go.temporal.io/sdk/internal/common/metrics"
@@ -253,6 +254,48 @@ func (s *WorkflowUnitTest) Test_ClockWorkflow() {
 	s.False(nowTime.IsZero())
 }
 
+func deadlineWorkflowTest(ctx Context) (time.Duration, error) {
+	start := Now(ctx)
+	timeoutCtx, cancel := WithTimeout(ctx, time.Minute)
+	defer cancel()
+	deadline, ok := timeoutCtx.Deadline()
+	if !ok || !deadline.Equal(start.Add(time.Minute)) {
+		return 0, fmt.Errorf("unexpected deadline %v", deadline)
+	}
+	// A later deadline doesn't extend the parent one.
+	childCtx, childCancel := WithTimeout(timeoutCtx, time.Hour)
+	defer childCancel()
+	if childDeadline, _ := childCtx.Deadline(); !childDeadline.Equal(deadline) {
+		return 0, fmt.Errorf("unexpected child deadline %v", childDeadline)
+	}
+
+	err := NewTimer(childCtx, time.Hour).Get(ctx, nil)
+	if !IsCanceledError(err) {
+		return 0, fmt.Errorf("expected timer to be canceled, got %v", err)
+	}
+	if timeoutCtx.Err() != ErrDeadlineExceeded || childCtx.Err() != ErrDeadlineExceeded {
+		return 0, fmt.Errorf("unexpected context errors %v, %v", timeoutCtx.Err(), childCtx.Err())
+	}
+
+	// Work that completes before the deadline is not affected.
+	longCtx, longCancel := WithDeadline(ctx, Now(ctx).Add(time.Hour))
+	defer longCancel()
+	if err := NewTimer(longCtx, time.Minute).Get(longCtx, nil); err != nil {
+		return 0, err
+	}
+	return Now(ctx).Sub(start), nil
+}
+
+func (s *WorkflowUnitTest) Test_DeadlineWorkflow() {
+	env := s.NewTestWorkflowEnvironment()
+	env.ExecuteWorkflow(deadlineWorkflowTest)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var elapsed time.Duration
+	s.NoError(env.GetWorkflowResult(&elapsed))
+	s.Equal(2*time.Minute, elapsed)
+}
+
 type testTimerWorkflow struct {
 	t *testing.T
 }
@@ -599,6 +642,48 @@ func (s *WorkflowUnitTest) Test_SignalWorkflow() {
 	s.EqualValues(strings.Join(expected, ""), string(result))
 }
 
+func signalAndQueryWorkflowTest(ctx Context) (string, error) {
+	state := "waiting"
+	err := SetQueryHandler(ctx, "state", func() (string, error) {
+		return state, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	var v string
+	GetSignalChannel(ctx, "testSig").Receive(ctx, &v)
+	state = "done"
+	return v, nil
+}
+
+func (s *WorkflowUnitTest) Test_SignalAndQueryInterceptors() {
+	env := s.NewTestWorkflowEnvironment()
+	tracer := tracingWorkflowInterceptor{}
+	env.SetWorkerOptions(WorkerOptions{WorkflowInterceptorChainFactories: []WorkflowInterceptor{&tracer}})
+	env.RegisterDelayedCallback(func() {
+		encoded, err := env.QueryWorkflow("state")
+		s.NoError(err)
+		var state string
+		s.NoError(encoded.Get(&state))
+		s.Equal("waiting", state)
+		env.SignalWorkflow("testSig", "signal value")
+	}, time.Minute)
+
+	env.ExecuteWorkflow(signalAndQueryWorkflowTest)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("signal value", result)
+	s.Equal(1, len(tracer.instances))
+	s.Equal([]string{
+		"ExecuteWorkflow signalAndQueryWorkflowTest begin",
+		"HandleQuery state",
+		"HandleSignal testSig",
+		"ExecuteWorkflow signalAndQueryWorkflowTest end",
+	}, tracer.instances[0].trace)
+}
+
 type message struct {
 	Value string
 }
@@ -1266,6 +1351,16 @@ func (t *tracingInboundCallsInterceptor) ExecuteWorkflow(ctx Context, workflowTy
 	return result
 }
 
+func (t *tracingInboundCallsInterceptor) HandleSignal(ctx Context, signalName string, arg *commonpb.Payloads) error {
+	t.trace = append(t.trace, "HandleSignal "+signalName)
+	return t.Next.HandleSignal(ctx, signalName, arg)
+}
+
+func (t *tracingInboundCallsInterceptor) HandleQuery(ctx Context, queryType string, args *commonpb.Payloads) (*commonpb.Payloads, error) {
+	t.trace = append(t.trace, "HandleQuery "+queryType)
+	return t.Next.HandleQuery(ctx, queryType, args)
+}
+
 func (t *tracingOutboundCallsInterceptor) ExecuteActivity(ctx Context, activityType string, args ...interface{}) Future {
 	t.inbound.trace = append(t.inbound.trace, "ExecuteActivity "+activityType)
 	return t.Next.ExecuteActivity(ctx, activityType, args...)
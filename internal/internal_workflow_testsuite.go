@@ -139,6 +139,7 @@ type (
 		contextPropagators []ContextPropagator
 		identity           string
 		tracer             opentracing.Tracer
+		payloadLimits      PayloadLimitOptions
 
 		mockClock *clock.Mock
 		wallClock clock.Clock
@@ -402,6 +403,10 @@ func (env *testWorkflowEnvironmentImpl) setDataConverter(dataConverter converter
 	env.dataConverter = dataConverter
 }
 
+func (env *testWorkflowEnvironmentImpl) setPayloadLimits(payloadLimits PayloadLimitOptions) {
+	env.payloadLimits = payloadLimits
+}
+
 func (env *testWorkflowEnvironmentImpl) setContextPropagators(contextPropagators []ContextPropagator) {
 	env.contextPropagators = contextPropagators
 }
@@ -612,6 +617,7 @@ func (env *testWorkflowEnvironmentImpl) executeLocalActivity(
 		metricsScope: env.metricsScope,
 		logger:       env.logger,
 		tracer:       opentracing.NoopTracer{},
+		interceptors: env.workerOptions.ActivityInterceptorChainFactories,
 	}
 
 	result := taskHandler.executeLocalActivityTask(task)
@@ -957,7 +963,8 @@ func (env *testWorkflowEnvironmentImpl) CompleteActivity(taskToken []byte, resul
 				tagActivityID, activityID)
 			return
 		}
-		request := convertActivityResultToRespondRequest("test-identity", taskToken, data, err, env.GetDataConverter())
+		request := convertActivityResultToRespondRequest("test-identity", taskToken, data, err, env.GetDataConverter(),
+			NewDefaultFailureConverter(DefaultFailureConverterOptions{DataConverter: env.GetDataConverter()}))
 		env.handleActivityResult(activityID, request, activityHandle.activityType, env.GetDataConverter())
 	}, false /* do not auto schedule workflow task, because activity might be still pending */)
 
@@ -976,6 +983,14 @@ func (env *testWorkflowEnvironmentImpl) GetDataConverter() converter.DataConvert
 	return env.dataConverter
 }
 
+func (env *testWorkflowEnvironmentImpl) GetDeadlockDetectionTimeout() time.Duration {
+	return env.workerOptions.DeadlockDetectionTimeout
+}
+
+func (env *testWorkflowEnvironmentImpl) GetPayloadSizeChecker() *payloadSizeChecker {
+	return newPayloadSizeChecker(env.payloadLimits, env.logger, env.metricsScope)
+}
+
 func (env *testWorkflowEnvironmentImpl) GetContextPropagators() []ContextPropagator {
 	return env.contextPropagators
 }
@@ -1773,15 +1788,17 @@ func (m *mockWrapper) executeMockWithActualArgs(ctx interface{}, inputArgs []int
 func (env *testWorkflowEnvironmentImpl) newTestActivityTaskHandler(taskQueue string, dataConverter converter.DataConverter) ActivityTaskHandler {
 	setWorkerOptionsDefaults(&env.workerOptions)
 	params := workerExecutionParameters{
-		TaskQueue:          taskQueue,
-		Identity:           env.identity,
-		MetricsScope:       env.metricsScope,
-		Logger:             env.logger,
-		UserContext:        env.workerOptions.BackgroundActivityContext,
-		DataConverter:      dataConverter,
-		WorkerStopChannel:  env.workerStopChannel,
-		ContextPropagators: env.contextPropagators,
-		Tracer:             env.tracer,
+		TaskQueue:            taskQueue,
+		Identity:             env.identity,
+		MetricsScope:         env.metricsScope,
+		Logger:               env.logger,
+		UserContext:          env.workerOptions.BackgroundActivityContext,
+		DataConverter:        dataConverter,
+		PayloadLimits:        env.payloadLimits,
+		WorkerStopChannel:    env.workerStopChannel,
+		ContextPropagators:   env.contextPropagators,
+		Tracer:               env.tracer,
+		ActivityInterceptors: env.workerOptions.ActivityInterceptorChainFactories,
 	}
 	ensureRequiredParams(&params)
 	if params.UserContext == nil {
@@ -398,6 +398,78 @@ func (s *WorkflowTestSuiteUnitTest) Test_ActivityWithUserContext() {
 	s.Equal(testValue, value)
 }
 
+type testActivityInterceptor struct {
+	calls *[]string
+}
+
+func (t *testActivityInterceptor) InterceptActivity(info *ActivityInfo, next ActivityInboundCallsInterceptor) ActivityInboundCallsInterceptor {
+	*t.calls = append(*t.calls, "InterceptActivity "+info.ActivityType.Name)
+	return &testActivityInboundCallsInterceptor{ActivityInboundCallsInterceptorBase: ActivityInboundCallsInterceptorBase{Next: next}, calls: t.calls}
+}
+
+type testActivityInboundCallsInterceptor struct {
+	ActivityInboundCallsInterceptorBase
+	calls *[]string
+}
+
+func (t *testActivityInboundCallsInterceptor) Init(outbound ActivityOutboundCallsInterceptor) error {
+	return t.Next.Init(&testActivityOutboundCallsInterceptor{ActivityOutboundCallsInterceptorBase: ActivityOutboundCallsInterceptorBase{Next: outbound}, calls: t.calls})
+}
+
+func (t *testActivityInboundCallsInterceptor) ExecuteActivity(ctx context.Context, activityType string, args ...interface{}) []interface{} {
+	*t.calls = append(*t.calls, fmt.Sprintf("ExecuteActivity %v", args))
+	results := t.Next.ExecuteActivity(ctx, activityType, args...)
+	results[0] = results[0].(string) + " intercepted"
+	return results
+}
+
+type testActivityOutboundCallsInterceptor struct {
+	ActivityOutboundCallsInterceptorBase
+	calls *[]string
+}
+
+func (t *testActivityOutboundCallsInterceptor) GetInfo(ctx context.Context) ActivityInfo {
+	*t.calls = append(*t.calls, "GetInfo")
+	return t.Next.GetInfo(ctx)
+}
+
+func (t *testActivityOutboundCallsInterceptor) RecordHeartbeat(ctx context.Context, details ...interface{}) {
+	*t.calls = append(*t.calls, fmt.Sprintf("RecordHeartbeat %v", details))
+	t.Next.RecordHeartbeat(ctx, details...)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_ActivityInterceptors() {
+	activityFn := func(ctx context.Context, name string) (string, error) {
+		RecordActivityHeartbeat(ctx, "progress")
+		_ = GetActivityInfo(ctx)
+		return name, nil
+	}
+
+	for _, local := range []bool{false, true} {
+		var calls []string
+		env := s.NewTestActivityEnvironment()
+		env.RegisterActivityWithOptions(activityFn, RegisterActivityOptions{Name: "testActivity"})
+		env.SetWorkerOptions(WorkerOptions{
+			ActivityInterceptorChainFactories: []ActivityInterceptor{&testActivityInterceptor{calls: &calls}},
+		})
+		var blob converter.EncodedValue
+		var err error
+		if local {
+			blob, err = env.ExecuteLocalActivity(activityFn, "hello")
+		} else {
+			blob, err = env.ExecuteActivity("testActivity", "hello")
+		}
+		s.NoError(err)
+		var value string
+		s.NoError(blob.Get(&value))
+		s.Equal("hello intercepted", value)
+		s.Equal([]string{"ExecuteActivity [hello]", "RecordHeartbeat [progress]", "GetInfo"}, calls[len(calls)-3:])
+		if !local {
+			s.Equal("InterceptActivity testActivity", calls[0])
+		}
+	}
+}
+
 func (s *WorkflowTestSuiteUnitTest) Test_ActivityWithHeaderContext() {
 	// inline activity using value passing through user context.
 	activityWithUserContext := func(ctx context.Context) (string, error) {
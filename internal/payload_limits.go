@@ -0,0 +1,199 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"fmt"
+
+	"github.com/uber-go/tally"
+	commonpb "go.temporal.io/api/common/v1"
+
+	"go.temporal.io/sdk/internal/common/metrics"
+	"go.temporal.io/sdk/log"
+)
+
+type (
+	// PayloadLimitOptions configures checks of payload sizes made before payloads are sent to the server.
+	// The server rejects payloads larger than its blob size limit (2MB by default, warning at 512KB).
+	// Rejections surface as opaque service errors on the client, or as workflow task failures which are
+	// retried forever on the worker. Checks made by the SDK name the operation, type and argument instead.
+	PayloadLimitOptions struct {
+		// Optional: Payloads larger than PayloadSizeWarning bytes are logged with a warning and counted
+		// by the temporal_payload_size_warning metric.
+		// default: 0, no warnings
+		PayloadSizeWarning int
+
+		// Optional: Payloads larger than PayloadSizeError bytes are not sent, the operation fails with
+		// *PayloadSizeError instead. Workflow results and continue as new inputs which are too large fail the
+		// workflow, activity and local activity results which are too large fail the activity with a non
+		// retryable error, and query results which are too large fail the query. Local activity inputs are
+		// passed to the activity function as is and are not checked.
+		// default: 0, no limit
+		PayloadSizeError int
+	}
+
+	// PayloadSizeError is returned when a payload exceeds PayloadLimitOptions.PayloadSizeError.
+	PayloadSizeError struct {
+		operation string
+		name      string
+		index     int
+		size      int
+		limit     int
+	}
+
+	payloadSizeChecker struct {
+		options      PayloadLimitOptions
+		logger       log.Logger
+		metricsScope tally.Scope
+	}
+)
+
+// Operations which payloads are checked.
+const (
+	payloadOperationWorkflowInput       = "workflow input"
+	payloadOperationWorkflowResult      = "workflow result"
+	payloadOperationContinueAsNewInput  = "continue as new input"
+	payloadOperationChildWorkflowInput  = "child workflow input"
+	payloadOperationActivityInput       = "activity input"
+	payloadOperationActivityResult      = "activity result"
+	payloadOperationLocalActivityResult = "local activity result"
+	payloadOperationHeartbeatDetails    = "activity heartbeat details"
+	payloadOperationSignalInput         = "signal input"
+	payloadOperationQueryInput          = "query input"
+	payloadOperationQueryResult         = "query result"
+)
+
+func (e *PayloadSizeError) Error() string {
+	var target string
+	if e.name != "" {
+		target = fmt.Sprintf(" of %q", e.name)
+	}
+	var argument string
+	if e.index >= 0 {
+		argument = fmt.Sprintf(" argument %d", e.index)
+	}
+	return fmt.Sprintf("%s%s%s is %d bytes which exceeds payload size limit of %d bytes",
+		e.operation, target, argument, e.size, e.limit)
+}
+
+// Operation returns the operation of the payload, for example "activity input" or "workflow result".
+func (e *PayloadSizeError) Operation() string {
+	return e.operation
+}
+
+// Name returns the name of the workflow, activity, signal or query type of the payload if known.
+func (e *PayloadSizeError) Name() string {
+	return e.name
+}
+
+// Index returns the index of the argument which exceeds the limit,
+// or -1 if the limit is exceeded by the total size of all arguments.
+func (e *PayloadSizeError) Index() int {
+	return e.index
+}
+
+// Size returns the size of the payload in bytes.
+func (e *PayloadSizeError) Size() int {
+	return e.size
+}
+
+// Limit returns PayloadLimitOptions.PayloadSizeError the payload was checked against.
+func (e *PayloadSizeError) Limit() int {
+	return e.limit
+}
+
+func newPayloadSizeChecker(options PayloadLimitOptions, logger log.Logger, metricsScope tally.Scope) *payloadSizeChecker {
+	if options.PayloadSizeWarning <= 0 && options.PayloadSizeError <= 0 {
+		return nil
+	}
+	return &payloadSizeChecker{
+		options:      options,
+		logger:       logger,
+		metricsScope: metricsScope,
+	}
+}
+
+// check validates the size of every payload and of all payloads together.
+// Results are checked with the same function, a single result is reported without an argument index.
+// At most one warning is reported per call.
+func (c *payloadSizeChecker) check(payloads *commonpb.Payloads, operation, name string) error {
+	if c == nil || payloads == nil {
+		return nil
+	}
+
+	isResult := operation == payloadOperationWorkflowResult || operation == payloadOperationActivityResult ||
+		operation == payloadOperationLocalActivityResult || operation == payloadOperationQueryResult
+	warned := false
+	checkSize := func(size, index int) error {
+		if c.options.PayloadSizeError > 0 && size > c.options.PayloadSizeError {
+			return &PayloadSizeError{
+				operation: operation,
+				name:      name,
+				index:     index,
+				size:      size,
+				limit:     c.options.PayloadSizeError,
+			}
+		}
+		if !warned && c.options.PayloadSizeWarning > 0 && size > c.options.PayloadSizeWarning {
+			warned = true
+			c.warn(size, operation, name, index)
+		}
+		return nil
+	}
+
+	for i, payload := range payloads.Payloads {
+		index := i
+		if isResult {
+			index = -1
+		}
+		if err := checkSize(payload.Size(), index); err != nil {
+			return err
+		}
+	}
+	if len(payloads.Payloads) > 1 {
+		return checkSize(payloads.Size(), -1)
+	}
+	return nil
+}
+
+func (c *payloadSizeChecker) warn(size int, operation, name string, index int) {
+	if c.metricsScope != nil {
+		c.metricsScope.Counter(metrics.PayloadSizeWarningCounter).Inc(1)
+	}
+	if c.logger != nil {
+		c.logger.Warn("Payload size exceeds warning limit",
+			tagPayloadOperation, operation,
+			tagPayloadName, name,
+			tagPayloadIndex, index,
+			tagPayloadSize, size,
+			tagPayloadSizeLimit, c.options.PayloadSizeWarning)
+	}
+}
+
+// checkWorkflowPayloadSize checks payloads created by workflow code. Payloads are checked during replay as well,
+// as a payload rejected by the original execution didn't produce a command, and replay must not produce one either.
+func checkWorkflowPayloadSize(env WorkflowEnvironment, payloads *commonpb.Payloads, operation, name string) error {
+	return env.GetPayloadSizeChecker().check(payloads, operation, name)
+}
@@ -0,0 +1,225 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/internal/common/metrics"
+	ilog "go.temporal.io/sdk/internal/log"
+)
+
+func TestPayloadSizeChecker(t *testing.T) {
+	require.Nil(t, newPayloadSizeChecker(PayloadLimitOptions{}, nil, nil))
+	var nilChecker *payloadSizeChecker
+	require.NoError(t, nilChecker.check(nil, payloadOperationActivityInput, "activity"))
+
+	scope := tally.NewTestScope("", nil)
+	checker := newPayloadSizeChecker(PayloadLimitOptions{PayloadSizeWarning: 100, PayloadSizeError: 200}, ilog.NewNopLogger(), scope)
+	dc := converter.GetDefaultDataConverter()
+
+	small, err := dc.ToPayloads("small", "small")
+	require.NoError(t, err)
+	require.NoError(t, checker.check(small, payloadOperationActivityInput, "activity"))
+	require.Empty(t, scope.Snapshot().Counters())
+
+	warn, err := dc.ToPayloads("small", strings.Repeat("x", 120))
+	require.NoError(t, err)
+	require.NoError(t, checker.check(warn, payloadOperationActivityInput, "activity"))
+	require.Equal(t, int64(1), scope.Snapshot().Counters()[metrics.PayloadSizeWarningCounter+"+"].Value())
+
+	large, err := dc.ToPayloads("small", strings.Repeat("x", 250))
+	require.NoError(t, err)
+	err = checker.check(large, payloadOperationActivityInput, "activity")
+	var sizeErr *PayloadSizeError
+	require.True(t, errors.As(err, &sizeErr))
+	require.Equal(t, payloadOperationActivityInput, sizeErr.Operation())
+	require.Equal(t, "activity", sizeErr.Name())
+	require.Equal(t, 1, sizeErr.Index())
+	require.Equal(t, 200, sizeErr.Limit())
+	require.Equal(t, large.Payloads[1].Size(), sizeErr.Size())
+	require.Equal(t, `activity input of "activity" argument 1 is `+
+		`279 bytes which exceeds payload size limit of 200 bytes`, err.Error())
+
+	total, err := dc.ToPayloads(strings.Repeat("x", 120), strings.Repeat("x", 120))
+	require.NoError(t, err)
+	err = checker.check(total, payloadOperationWorkflowInput, "workflow")
+	require.True(t, errors.As(err, &sizeErr))
+	require.Equal(t, -1, sizeErr.Index())
+
+	result, err := dc.ToPayloads(strings.Repeat("x", 250))
+	require.NoError(t, err)
+	err = checker.check(result, payloadOperationActivityResult, "")
+	require.EqualError(t, err, "activity result is 279 bytes which exceeds payload size limit of 200 bytes")
+}
+
+func TestPayloadLimitsOnClient(t *testing.T) {
+	client, err := NewClient(ClientOptions{
+		WorkflowService: NewInMemoryWorkflowService(),
+		Logger:          ilog.NewNopLogger(),
+		PayloadLimits:   PayloadLimitOptions{PayloadSizeError: 1000},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	large := strings.Repeat("x", 2000)
+	_, err = client.ExecuteWorkflow(ctx, StartWorkflowOptions{ID: "payload-limits", TaskQueue: "payload-limits"}, "someWorkflow", "small", large)
+	var sizeErr *PayloadSizeError
+	require.True(t, errors.As(err, &sizeErr))
+	require.Equal(t, payloadOperationWorkflowInput, sizeErr.Operation())
+	require.Equal(t, "someWorkflow", sizeErr.Name())
+	require.Equal(t, 1, sizeErr.Index())
+
+	err = client.SignalWorkflow(ctx, "payload-limits", "", "someSignal", large)
+	require.True(t, errors.As(err, &sizeErr))
+	require.Equal(t, payloadOperationSignalInput, sizeErr.Operation())
+	require.Equal(t, "someSignal", sizeErr.Name())
+}
+
+func payloadLimitsActivity(_ context.Context, size int) (string, error) {
+	return strings.Repeat("x", size), nil
+}
+
+func payloadLimitsWorkflow(ctx Context, activityInputSize, activityResultSize, resultSize int) (string, error) {
+	ctx = WithActivityOptions(ctx, ActivityOptions{StartToCloseTimeout: 10 * time.Second})
+	err := ExecuteActivity(ctx, payloadLimitsActivity, activityInputSize, strings.Repeat("x", activityInputSize)).Get(ctx, nil)
+	var sizeErr *PayloadSizeError
+	if !errors.As(err, &sizeErr) || sizeErr.Operation() != payloadOperationActivityInput {
+		return "", errors.New("activity input must exceed the limit")
+	}
+
+	err = ExecuteActivity(ctx, payloadLimitsActivity, activityResultSize).Get(ctx, nil)
+	var applicationErr *ApplicationError
+	if !errors.As(err, &applicationErr) || applicationErr.Type() != "PayloadSizeError" || !applicationErr.NonRetryable() {
+		return "", errors.New("activity result must exceed the limit")
+	}
+	return strings.Repeat("x", resultSize), nil
+}
+
+func TestPayloadLimitsInWorkflow(t *testing.T) {
+	testSuite := &WorkflowTestSuite{}
+	env := testSuite.NewTestWorkflowEnvironment()
+	env.SetPayloadLimits(PayloadLimitOptions{PayloadSizeError: 1000})
+	env.RegisterActivity(payloadLimitsActivity)
+	env.ExecuteWorkflow(payloadLimitsWorkflow, 2000, 2000, 2000)
+	require.True(t, env.IsWorkflowCompleted())
+	err := env.GetWorkflowError()
+	var applicationErr *ApplicationError
+	require.True(t, errors.As(err, &applicationErr))
+	require.Equal(t, "PayloadSizeError", applicationErr.Type())
+	require.Contains(t, applicationErr.Error(), "workflow result of \"payloadLimitsWorkflow\"")
+}
+
+func payloadLimitsReplayWorkflow(ctx Context) (string, error) {
+	ctx = WithActivityOptions(ctx, ActivityOptions{StartToCloseTimeout: 10 * time.Second})
+	err := ExecuteActivity(ctx, payloadLimitsActivity, 2000, strings.Repeat("x", 2000)).Get(ctx, nil)
+	var sizeErr *PayloadSizeError
+	if !errors.As(err, &sizeErr) {
+		return "", errors.New("activity input must exceed the limit")
+	}
+	var result string
+	err = ExecuteActivity(ctx, payloadLimitsActivity, 10).Get(ctx, &result)
+	return result, err
+}
+
+func TestPayloadLimitsInWorkflowReplay(t *testing.T) {
+	client, err := NewClient(ClientOptions{
+		WorkflowService: NewInMemoryWorkflowService(),
+		Logger:          ilog.NewNopLogger(),
+		PayloadLimits:   PayloadLimitOptions{PayloadSizeError: 1000},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Without sticky execution every workflow task replays the history from the start.
+	worker := NewAggregatedWorker(client.(*WorkflowClient), "payload-limits-replay", WorkerOptions{DisableStickyExecution: true})
+	worker.RegisterWorkflow(payloadLimitsReplayWorkflow)
+	worker.RegisterActivity(payloadLimitsActivity)
+	require.NoError(t, worker.Start())
+	defer worker.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	run, err := client.ExecuteWorkflow(ctx, StartWorkflowOptions{ID: "payload-limits-replay", TaskQueue: "payload-limits-replay"}, payloadLimitsReplayWorkflow)
+	require.NoError(t, err)
+	var result string
+	require.NoError(t, run.Get(ctx, &result))
+	require.Equal(t, strings.Repeat("x", 10), result)
+}
+
+func payloadLimitsWorkerWorkflow(ctx Context, input string) error {
+	err := SetQueryHandler(ctx, "large", func() (string, error) {
+		return strings.Repeat("x", 2000), nil
+	})
+	if err != nil {
+		return err
+	}
+	ctx = WithLocalActivityOptions(ctx, LocalActivityOptions{ScheduleToCloseTimeout: 10 * time.Second})
+	err = ExecuteLocalActivity(ctx, payloadLimitsActivity, 2000).Get(ctx, nil)
+	var applicationErr *ApplicationError
+	if !errors.As(err, &applicationErr) || applicationErr.Type() != "PayloadSizeError" || !applicationErr.NonRetryable() {
+		return errors.New("local activity result must exceed the limit")
+	}
+	return NewContinueAsNewError(ctx, payloadLimitsWorkerWorkflow, strings.Repeat("x", 2000))
+}
+
+func TestPayloadLimitsOnWorker(t *testing.T) {
+	client, err := NewClient(ClientOptions{
+		WorkflowService: NewInMemoryWorkflowService(),
+		Logger:          ilog.NewNopLogger(),
+		PayloadLimits:   PayloadLimitOptions{PayloadSizeError: 1000},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	worker := NewAggregatedWorker(client.(*WorkflowClient), "payload-limits-worker", WorkerOptions{})
+	worker.RegisterWorkflow(payloadLimitsWorkerWorkflow)
+	require.NoError(t, worker.Start())
+	defer worker.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	run, err := client.ExecuteWorkflow(ctx, StartWorkflowOptions{ID: "payload-limits-worker", TaskQueue: "payload-limits-worker"}, payloadLimitsWorkerWorkflow, "")
+	require.NoError(t, err)
+	err = run.Get(ctx, nil)
+	var applicationErr *ApplicationError
+	require.True(t, errors.As(err, &applicationErr))
+	require.Equal(t, "PayloadSizeError", applicationErr.Type())
+	require.Contains(t, applicationErr.Error(), `continue as new input of "payloadLimitsWorkerWorkflow" argument 0`)
+
+	_, err = client.QueryWorkflow(ctx, "payload-limits-worker", run.GetRunID(), "large")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `query result of "large"`)
+}
@@ -76,6 +76,7 @@ type (
 	sessionTokenBucket struct {
 		*sync.Cond
 		availableToken int
+		size           int
 	}
 
 	sessionEnvironment interface {
@@ -482,6 +483,7 @@ func newSessionTokenBucket(concurrentSessionExecutionSize int) *sessionTokenBuck
 	return &sessionTokenBucket{
 		Cond:           sync.NewCond(&sync.Mutex{}),
 		availableToken: concurrentSessionExecutionSize,
+		size:           concurrentSessionExecutionSize,
 	}
 }
 
@@ -500,6 +502,13 @@ func (t *sessionTokenBucket) addToken() {
 	t.Signal()
 }
 
+// openSessions returns the number of sessions holding a token and the size of the bucket.
+func (t *sessionTokenBucket) openSessions() (int, int) {
+	t.L.Lock()
+	defer t.L.Unlock()
+	return t.size - t.availableToken, t.size
+}
+
 func (t *sessionTokenBucket) getToken() bool {
 	t.L.Lock()
 	defer t.L.Unlock()
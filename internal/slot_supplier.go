@@ -0,0 +1,169 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultTargetMemoryUsage = 0.8
+	defaultTargetCPUUsage    = 0.9
+	defaultRampThrottle      = 50 * time.Millisecond
+
+	// resourceUsageRefreshInterval is how long a measurement of resource usage is reused.
+	resourceUsageRefreshInterval = 100 * time.Millisecond
+)
+
+type (
+	// SlotSupplier decides whether a worker may use another task execution slot. A worker reserves a slot before
+	// it polls for a task and releases it once the task is processed or the poll returns nothing. The number of
+	// slots of a worker is still limited by MaxConcurrentActivityExecutionSize, MaxConcurrentWorkflowTaskExecutionSize
+	// and MaxConcurrentLocalActivityExecutionSize.
+	// The same SlotSupplier is used by all the task workers of a worker and must be safe for concurrent use.
+	SlotSupplier interface {
+		// TryReserveSlot returns true if a slot can be used. A worker denied a slot retries shortly after.
+		TryReserveSlot(info SlotInfo) bool
+		// ReleaseSlot is called when a slot reserved with TryReserveSlot is released.
+		ReleaseSlot(info SlotInfo)
+	}
+
+	// SlotInfo describes the task worker a slot is reserved or released for.
+	SlotInfo struct {
+		// WorkerType is one of "WorkflowWorker", "ActivityWorker" and "LocalActivityWorker".
+		WorkerType string
+		// UsedSlots is the number of slots the task worker uses excluding the one being reserved or released.
+		UsedSlots int
+	}
+
+	// ResourceBasedSlotSupplierOptions are the options of ResourceBasedSlotSupplier.
+	ResourceBasedSlotSupplierOptions struct {
+		// Optional: Fraction of memory available to the process, from 0 to 1, above which no new slots are given.
+		// default: 0.8
+		TargetMemoryUsage float64
+
+		// Optional: Fraction of CPU available to the process, from 0 to 1, above which no new slots are given.
+		// default: 0.9
+		TargetCPUUsage float64
+
+		// Optional: Number of slots of every task worker that are given regardless of resource usage.
+		// default: 0
+		MinSlots int
+
+		// Optional: Maximum number of slots of every task worker.
+		// default: 0, only limited by the worker options
+		MaxSlots int
+
+		// Optional: Minimum time between two slots given to a task worker above MinSlots. The resources used by a
+		// task are not visible right after it is started, so giving slots too fast overshoots the targets.
+		// default: 50ms
+		RampThrottle time.Duration
+	}
+
+	// ResourceBasedSlotSupplier is a SlotSupplier that gives slots while memory and CPU usage of the process are
+	// below targets. Usage is read from the cgroup of the process, or from /proc if the cgroup has no limits.
+	// It is only supported on Linux.
+	ResourceBasedSlotSupplier struct {
+		options ResourceBasedSlotSupplierOptions
+		usage   resourceUsageReader
+
+		lock        sync.Mutex
+		lastGranted map[string]time.Time
+		lastRead    time.Time
+		memoryUsage float64
+		cpuUsage    float64
+		readErr     error
+	}
+
+	// resourceUsageReader reads memory and CPU usage as fractions of what is available to the process.
+	// CPU usage is measured since the previous read.
+	resourceUsageReader interface {
+		read() (memoryUsage float64, cpuUsage float64, err error)
+	}
+)
+
+// NewResourceBasedSlotSupplier creates a ResourceBasedSlotSupplier. It fails if resource usage can't be read.
+func NewResourceBasedSlotSupplier(options ResourceBasedSlotSupplierOptions) (*ResourceBasedSlotSupplier, error) {
+	usage, err := newResourceUsageReader()
+	if err != nil {
+		return nil, err
+	}
+	return newResourceBasedSlotSupplier(options, usage)
+}
+
+func newResourceBasedSlotSupplier(options ResourceBasedSlotSupplierOptions, usage resourceUsageReader) (*ResourceBasedSlotSupplier, error) {
+	if options.TargetMemoryUsage <= 0 {
+		options.TargetMemoryUsage = defaultTargetMemoryUsage
+	}
+	if options.TargetCPUUsage <= 0 {
+		options.TargetCPUUsage = defaultTargetCPUUsage
+	}
+	if options.RampThrottle == 0 {
+		options.RampThrottle = defaultRampThrottle
+	}
+	s := &ResourceBasedSlotSupplier{
+		options:     options,
+		usage:       usage,
+		lastGranted: make(map[string]time.Time),
+	}
+	// First read starts CPU usage measurement.
+	if _, _, err := usage.read(); err != nil {
+		return nil, err
+	}
+	s.lastRead = time.Now()
+	return s, nil
+}
+
+// TryReserveSlot gives a slot if the task worker uses less than MinSlots, or if it uses less than MaxSlots,
+// RampThrottle passed since its previous slot and memory and CPU usage are below targets.
+func (s *ResourceBasedSlotSupplier) TryReserveSlot(info SlotInfo) bool {
+	if info.UsedSlots < s.options.MinSlots {
+		return true
+	}
+	if s.options.MaxSlots > 0 && info.UsedSlots >= s.options.MaxSlots {
+		return false
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	if now.Sub(s.lastGranted[info.WorkerType]) < s.options.RampThrottle {
+		return false
+	}
+	if now.Sub(s.lastRead) >= resourceUsageRefreshInterval {
+		s.memoryUsage, s.cpuUsage, s.readErr = s.usage.read()
+		s.lastRead = now
+	}
+	if s.readErr != nil || s.memoryUsage >= s.options.TargetMemoryUsage || s.cpuUsage >= s.options.TargetCPUUsage {
+		return false
+	}
+	s.lastGranted[info.WorkerType] = now
+	return true
+}
+
+// ReleaseSlot does nothing, released resources are seen by the next read of resource usage.
+func (s *ResourceBasedSlotSupplier) ReleaseSlot(SlotInfo) {
+}
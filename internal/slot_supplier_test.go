@@ -0,0 +1,156 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+
+	"go.temporal.io/sdk/internal/common/metrics"
+)
+
+type testResourceUsageReader struct {
+	memoryUsage float64
+	cpuUsage    float64
+	err         error
+	reads       int
+}
+
+func (r *testResourceUsageReader) read() (float64, float64, error) {
+	r.reads++
+	return r.memoryUsage, r.cpuUsage, r.err
+}
+
+func TestResourceBasedSlotSupplier(t *testing.T) {
+	usage := &testResourceUsageReader{memoryUsage: 0.5, cpuUsage: 0.5}
+	s, err := newResourceBasedSlotSupplier(ResourceBasedSlotSupplierOptions{
+		MinSlots:     1,
+		MaxSlots:     3,
+		RampThrottle: -1,
+	}, usage)
+	require.NoError(t, err)
+	require.Equal(t, defaultTargetMemoryUsage, s.options.TargetMemoryUsage)
+	require.Equal(t, defaultTargetCPUUsage, s.options.TargetCPUUsage)
+
+	require.True(t, s.TryReserveSlot(SlotInfo{WorkerType: "ActivityWorker", UsedSlots: 1}))
+	require.False(t, s.TryReserveSlot(SlotInfo{WorkerType: "ActivityWorker", UsedSlots: 3}))
+
+	// Usage is cached between reads.
+	usage.memoryUsage = 0.85
+	require.True(t, s.TryReserveSlot(SlotInfo{WorkerType: "ActivityWorker", UsedSlots: 1}))
+	s.lastRead = time.Time{}
+	require.False(t, s.TryReserveSlot(SlotInfo{WorkerType: "ActivityWorker", UsedSlots: 1}))
+	// MinSlots are given regardless of usage.
+	require.True(t, s.TryReserveSlot(SlotInfo{WorkerType: "ActivityWorker", UsedSlots: 0}))
+
+	usage.memoryUsage, usage.cpuUsage = 0.5, 0.95
+	s.lastRead = time.Time{}
+	require.False(t, s.TryReserveSlot(SlotInfo{WorkerType: "ActivityWorker", UsedSlots: 1}))
+
+	usage.cpuUsage, usage.err = 0.5, errors.New("read failed")
+	s.lastRead = time.Time{}
+	require.False(t, s.TryReserveSlot(SlotInfo{WorkerType: "ActivityWorker", UsedSlots: 1}))
+
+	_, err = newResourceBasedSlotSupplier(ResourceBasedSlotSupplierOptions{}, usage)
+	require.Error(t, err)
+}
+
+func TestResourceBasedSlotSupplierRampThrottle(t *testing.T) {
+	s, err := newResourceBasedSlotSupplier(ResourceBasedSlotSupplierOptions{RampThrottle: time.Hour}, &testResourceUsageReader{})
+	require.NoError(t, err)
+	require.True(t, s.TryReserveSlot(SlotInfo{WorkerType: "ActivityWorker", UsedSlots: 0}))
+	require.False(t, s.TryReserveSlot(SlotInfo{WorkerType: "ActivityWorker", UsedSlots: 1}))
+	// Throttled per task worker.
+	require.True(t, s.TryReserveSlot(SlotInfo{WorkerType: "WorkflowWorker", UsedSlots: 0}))
+}
+
+type countingSlotSupplier struct {
+	maxSlots int
+
+	lock     sync.Mutex
+	reserved int
+	released int
+}
+
+func (s *countingSlotSupplier) TryReserveSlot(info SlotInfo) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if info.UsedSlots >= s.maxSlots {
+		return false
+	}
+	s.reserved++
+	return true
+}
+
+func (s *countingSlotSupplier) ReleaseSlot(SlotInfo) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.released++
+}
+
+func TestBaseWorkerSlotSupplier(t *testing.T) {
+	supplier := &countingSlotSupplier{maxSlots: 2}
+	poller := &testTaskPoller{task: struct{}{}, processDuration: 5 * time.Millisecond}
+	scope := tally.NewTestScope("", nil)
+	worker := newTestBaseWorker(poller, baseWorkerOptions{
+		pollerCount:       4,
+		maxConcurrentTask: 10,
+		slotSupplier:      supplier},
+		scope,
+	)
+
+	worker.Start()
+	time.Sleep(300 * time.Millisecond)
+	worker.Stop()
+
+	_, maxProcessing := poller.maxConcurrency()
+	require.Equal(t, 2, maxProcessing)
+	supplier.lock.Lock()
+	require.True(t, supplier.reserved > 2)
+	require.True(t, supplier.released > 0)
+	supplier.lock.Unlock()
+
+	snapshot := scope.Snapshot()
+	var denied int64
+	for _, c := range snapshot.Counters() {
+		if c.Name() == metrics.WorkerTaskSlotsDeniedCounter {
+			denied += c.Value()
+		}
+	}
+	require.True(t, denied > 0)
+	var gauges int
+	for _, g := range snapshot.Gauges() {
+		if g.Name() == metrics.WorkerTaskSlotsUsedGauge {
+			gauges++
+			require.True(t, g.Value() <= 2)
+		}
+	}
+	require.Equal(t, 1, gauges)
+}
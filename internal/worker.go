@@ -27,6 +27,8 @@ package internal
 import (
 	"context"
 	"time"
+
+	"go.temporal.io/sdk/converter"
 )
 
 type (
@@ -78,6 +80,11 @@ type (
 		// default: 2
 		MaxConcurrentActivityTaskPollers int
 
+		// Optional: Sets the minimum number of goroutines that poll the temporal-server for activity tasks
+		// when EnablePollerAutoscaling is set.
+		// default: 1
+		MinConcurrentActivityTaskPollers int
+
 		// Optional: To set the maximum concurrent workflow task executions this worker can have.
 		// The zero value of this uses the default value.
 		// default: defaultMaxConcurrentTaskExecutionSize(1k)
@@ -95,6 +102,26 @@ type (
 		// default: 2
 		MaxConcurrentWorkflowTaskPollers int
 
+		// Optional: Sets the minimum number of goroutines that poll the temporal-server for workflow tasks
+		// when EnablePollerAutoscaling is set.
+		// default: 1
+		MinConcurrentWorkflowTaskPollers int
+
+		// Optional: Enables poller autoscaling. Instead of always running the maximum number of pollers, the worker
+		// grows the number of pollers up to MaxConcurrentActivityTaskPollers/MaxConcurrentWorkflowTaskPollers while
+		// polls return tasks and the task queue has a backlog, and shrinks it down to
+		// MinConcurrentActivityTaskPollers/MinConcurrentWorkflowTaskPollers while polls return no tasks or fail.
+		// Pollers are not added while all execution slots are in use.
+		// default: false
+		EnablePollerAutoscaling bool
+
+		// Optional: Sets SlotSupplier that decides when the worker can start executing one more workflow task,
+		// activity or local activity, within the limits of MaxConcurrentWorkflowTaskExecutionSize,
+		// MaxConcurrentActivityExecutionSize and MaxConcurrentLocalActivityExecutionSize.
+		// See NewResourceBasedSlotSupplier for a SlotSupplier based on memory and CPU usage.
+		// default: nil, the limits are the only constraint
+		SlotSupplier SlotSupplier
+
 		// Optional: Enable logging in replay.
 		// In the workflow code you can use workflow.GetLogger(ctx) to write logs. By default, the logger will skip log
 		// entry during replay mode so you won't see duplicate logs. This option will enable the logging in replay mode.
@@ -130,6 +157,26 @@ type (
 		// default: 0s
 		WorkerStopTimeout time.Duration
 
+		// Optional: Sets how long a workflow coroutine can run without yielding, for example by blocking on a
+		// workflow.Future or workflow.Channel, before the worker fails the workflow task as a potential deadlock.
+		// The failure includes the stack traces of all the coroutines of the workflow. Busy loops and blocking on
+		// native Go primitives are the usual causes. The detection pauses while a debugger is attached to the
+		// worker process, or when the TEMPORAL_DEBUG environment variable is set. A deadlocked coroutine can't be
+		// stopped, it keeps running in the background and can still modify workflow state until it yields.
+		// default: 1s
+		DeadlockDetectionTimeout time.Duration
+
+		// Optional: BlobStore used by converter.NewBlobStoreCodec of the client DataConverter. If set, blobs of
+		// workflows closed by this worker are deleted once namespace retention removed their history. Blobs shared
+		// with other executions are kept.
+		// default: nil, blobs are never deleted
+		BlobStore converter.BlobStore
+
+		// Optional: Sets FailureConverter to customize serialization/deserialization of errors returned
+		// by workflows and activities of this worker.
+		// default: FailureConverter of the client
+		FailureConverter FailureConverter
+
 		// Optional: Enable running session workers.
 		// Session workers is for activities within a session.
 		// Enable this option to allow worker to process sessions.
@@ -149,6 +196,59 @@ type (
 		// Optional: Specifies factories used to instantiate workflow interceptor chain
 		// The chain is instantiated per each replay of a workflow execution
 		WorkflowInterceptorChainFactories []WorkflowInterceptor
+
+		// Optional: Specifies factories used to instantiate activity interceptor chain
+		// The chain is instantiated per each activity execution, including local activities
+		ActivityInterceptorChainFactories []ActivityInterceptor
+	}
+
+	// WorkerUpdateOptions are the options of a running worker changed with Worker.UpdateOptions. The fields have
+	// the same meaning as in WorkerOptions. Negative values are rejected.
+	WorkerUpdateOptions struct {
+		// Optional: Sets the maximum concurrent activity executions of the worker and of its session activities.
+		// Activities in flight are not affected, a lowered limit applies once enough of them complete.
+		// default: 0, the current value is unchanged
+		MaxConcurrentActivityExecutionSize int
+
+		// Optional: Sets the rate limiting on number of activities that can be executed per second per worker.
+		// default: 0, the current value is unchanged
+		WorkerActivitiesPerSecond float64
+
+		// Optional: Sets the maximum concurrent local activity executions of the worker.
+		// Local activities in flight are not affected, a lowered limit applies once enough of them complete.
+		// default: 0, the current value is unchanged
+		MaxConcurrentLocalActivityExecutionSize int
+
+		// Optional: Sets the rate limiting on number of local activities that can be executed per second per
+		// worker.
+		// default: 0, the current value is unchanged
+		WorkerLocalActivitiesPerSecond float64
+
+		// Optional: Sets the rate limiting on number of activities that can be executed per second for the entire
+		// task queue. It is sent to the server with the next activity task polls.
+		// default: 0, the current value is unchanged
+		TaskQueueActivitiesPerSecond float64
+
+		// Optional: Sets the maximum number of goroutines that concurrently poll the temporal-server for activity
+		// tasks. With EnablePollerAutoscaling it is the new upper bound of the autoscaler. Polls in flight of
+		// removed pollers complete before the pollers exit.
+		// default: 0, the current value is unchanged
+		MaxConcurrentActivityTaskPollers int
+
+		// Optional: Sets the maximum concurrent workflow task executions of the worker.
+		// Workflow tasks in flight are not affected, a lowered limit applies once enough of them complete.
+		// default: 0, the current value is unchanged
+		MaxConcurrentWorkflowTaskExecutionSize int
+
+		// Optional: Sets the rate limiting on number of workflow tasks that can be executed per second per worker.
+		// default: 0, the current value is unchanged
+		WorkerWorkflowTasksPerSecond float64
+
+		// Optional: Sets the maximum number of goroutines that concurrently poll the temporal-server for workflow
+		// tasks. With EnablePollerAutoscaling it is the new upper bound of the autoscaler. Polls in flight of
+		// removed pollers complete before the pollers exit.
+		// default: 0, the current value is unchanged
+		MaxConcurrentWorkflowTaskPollers int
 	}
 )
 
@@ -0,0 +1,156 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"sort"
+	"time"
+)
+
+type (
+	// WorkerStatus is a snapshot of the state of a worker returned by Worker.Status.
+	WorkerStatus struct {
+		TaskQueue string
+		Identity  string
+		Paused    bool
+
+		// TaskWorkers are the statuses of the task workers of the worker: the workflow task worker, the local
+		// activity worker, the activity worker and, if sessions are enabled, the session creation and session
+		// activity workers.
+		TaskWorkers []TaskWorkerStatus
+
+		// StickyCache is the status of the sticky workflow cache shared by the workers of the process.
+		StickyCache StickyCacheStatus
+
+		RegisteredWorkflowTypes []string
+		RegisteredActivityTypes []string
+
+		// OpenSessions is the number of sessions open on the worker, out of MaxConcurrentSessions.
+		// Both are 0 unless EnableSessionWorker is set.
+		OpenSessions          int
+		MaxConcurrentSessions int
+
+		// RunningActivities are the activities executing on the worker, not including local activities.
+		RunningActivities []RunningActivityStatus
+	}
+
+	// TaskWorkerStatus is the status of a task worker that polls one task queue for one kind of task.
+	TaskWorkerStatus struct {
+		// WorkerType is one of "WorkflowWorker", "LocalActivityWorker" and "ActivityWorker".
+		WorkerType string
+		TaskQueue  string
+		Started    bool
+
+		// MaxPollers is the number of pollers configured and RunningPollers the number of them running.
+		// ActivePollers is the number of pollers allowed to poll by poller autoscaling, MaxPollers without it.
+		MaxPollers     int
+		RunningPollers int
+		ActivePollers  int
+
+		// UsedSlots is the number of tasks being processed, AvailableSlots is how many more can be processed.
+		UsedSlots        int
+		AvailableSlots   int
+		MaxTaskPerSecond float64
+
+		// LastPollTime is when the last poll completed, with or without a task.
+		LastPollTime time.Time
+		// LastPollError is the error of the last failed poll and LastPollErrorTime when it failed.
+		LastPollError     error
+		LastPollErrorTime time.Time
+	}
+
+	// StickyCacheStatus is the status of the sticky workflow cache. Hits and misses are counted for workflow
+	// tasks of workflows that were cached on the worker since the process started.
+	StickyCacheStatus struct {
+		Size     int
+		Capacity int
+		Hits     int64
+		Misses   int64
+		// HitRate is Hits / (Hits + Misses), 0 if there were none.
+		HitRate float64
+	}
+
+	// RunningActivityStatus describes an activity executing on the worker.
+	RunningActivityStatus struct {
+		ActivityID   string
+		ActivityType string
+		TaskQueue    string
+		WorkflowID   string
+		RunID        string
+		WorkflowType string
+		Attempt      int32
+		// StartTime is when the worker started executing the activity.
+		StartTime time.Time
+	}
+)
+
+// Status returns a snapshot of the state of the worker.
+func (aw *AggregatedWorker) Status() WorkerStatus {
+	status := WorkerStatus{
+		TaskQueue:               aw.workflowWorker.executionParameters.TaskQueue,
+		Identity:                aw.workflowWorker.executionParameters.Identity,
+		Paused:                  aw.pause.isPaused(),
+		StickyCache:             getStickyCacheStatus(),
+		RegisteredWorkflowTypes: aw.registry.getRegisteredWorkflowTypes(),
+		RegisteredActivityTypes: aw.registry.getRegisteredActivityTypes(),
+	}
+	sort.Strings(status.RegisteredWorkflowTypes)
+	sort.Strings(status.RegisteredActivityTypes)
+
+	status.TaskWorkers = append(status.TaskWorkers,
+		aw.workflowWorker.worker.status(status.TaskQueue),
+		aw.workflowWorker.localActivityWorker.status(status.TaskQueue),
+	)
+	activityWorkers := []*activityWorker{aw.activityWorker}
+	if aw.sessionWorker != nil {
+		activityWorkers = append(activityWorkers, aw.sessionWorker.creationWorker, aw.sessionWorker.activityWorker)
+		status.OpenSessions, status.MaxConcurrentSessions = aw.sessionWorker.creationWorker.worker.sessionTokenBucket.openSessions()
+	}
+	for _, w := range activityWorkers {
+		status.TaskWorkers = append(status.TaskWorkers, w.worker.status(w.executionParameters.TaskQueue))
+		if poller, ok := w.poller.(*activityTaskPoller); ok {
+			status.RunningActivities = append(status.RunningActivities, poller.getRunningActivities()...)
+		}
+	}
+	sort.Slice(status.RunningActivities, func(i, j int) bool {
+		return status.RunningActivities[i].StartTime.Before(status.RunningActivities[j].StartTime)
+	})
+	return status
+}
+
+func getStickyCacheStatus() StickyCacheStatus {
+	stickyCacheLock.Lock()
+	status := StickyCacheStatus{Capacity: stickyCacheSize}
+	if workflowCache != nil {
+		status.Size = workflowCache.Size()
+	}
+	stickyCacheLock.Unlock()
+
+	status.Hits, status.Misses = getStickyCacheHitsAndMisses()
+	if status.Hits+status.Misses > 0 {
+		status.HitRate = float64(status.Hits) / float64(status.Hits+status.Misses)
+	}
+	return status
+}
@@ -0,0 +1,136 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+
+	ilog "go.temporal.io/sdk/internal/log"
+)
+
+func TestBaseWorkerStatus(t *testing.T) {
+	poller := &testTaskPoller{pollErrors: []error{errors.New("poll failed")}, pollDuration: 10 * time.Millisecond}
+	worker := newTestBaseWorker(poller, baseWorkerOptions{
+		pollerCount:       2,
+		maxConcurrentTask: 5,
+		maxTaskPerSecond:  100,
+		pollerAutoscaler:  newPollerAutoscaler(1, 2)},
+		tally.NoopScope,
+	)
+	status := worker.status("tq")
+	require.False(t, status.Started)
+	require.Equal(t, "TestWorker", status.WorkerType)
+	require.Equal(t, "tq", status.TaskQueue)
+	require.Equal(t, 2, status.MaxPollers)
+	require.Equal(t, 0, status.RunningPollers)
+
+	worker.Start()
+	time.Sleep(100 * time.Millisecond)
+	status = worker.status("tq")
+	require.True(t, status.Started)
+	require.Equal(t, 2, status.RunningPollers)
+	require.Equal(t, 1, status.ActivePollers)
+	require.Equal(t, 0, status.UsedSlots)
+	require.Equal(t, 5, status.AvailableSlots)
+	require.Equal(t, float64(100), status.MaxTaskPerSecond)
+	require.EqualError(t, status.LastPollError, "poll failed")
+	require.True(t, status.LastPollTime.After(status.LastPollErrorTime))
+
+	worker.Stop()
+	require.False(t, worker.status("tq").Started)
+}
+
+type statusTestActivities struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (a *statusTestActivities) BlockingActivity(context.Context) error {
+	close(a.started)
+	<-a.release
+	return nil
+}
+
+func statusTestWorkflow(ctx Context) error {
+	ctx = WithActivityOptions(ctx, ActivityOptions{StartToCloseTimeout: time.Minute})
+	return ExecuteActivity(ctx, "BlockingActivity").Get(ctx, nil)
+}
+
+func TestAggregatedWorkerStatus(t *testing.T) {
+	client, err := NewClient(ClientOptions{WorkflowService: NewInMemoryWorkflowService(), Logger: ilog.NewNopLogger()})
+	require.NoError(t, err)
+	defer client.Close()
+
+	worker := NewAggregatedWorker(client.(*WorkflowClient), "status-task-queue", WorkerOptions{EnableSessionWorker: true})
+	activities := &statusTestActivities{started: make(chan struct{}), release: make(chan struct{})}
+	worker.RegisterWorkflow(statusTestWorkflow)
+	worker.RegisterActivity(activities)
+	require.NoError(t, worker.Start())
+	defer worker.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	run, err := client.ExecuteWorkflow(ctx, StartWorkflowOptions{ID: "status", TaskQueue: "status-task-queue"}, statusTestWorkflow)
+	require.NoError(t, err)
+	<-activities.started
+
+	status := worker.Status()
+	require.Equal(t, "status-task-queue", status.TaskQueue)
+	require.NotEmpty(t, status.Identity)
+	require.False(t, status.Paused)
+	require.Equal(t, []string{"statusTestWorkflow"}, status.RegisteredWorkflowTypes)
+	require.Contains(t, status.RegisteredActivityTypes, "BlockingActivity")
+	require.Equal(t, 0, status.OpenSessions)
+	require.Equal(t, defaultMaxConcurrentSessionExecutionSize, status.MaxConcurrentSessions)
+	require.Equal(t, stickyCacheSize, status.StickyCache.Capacity)
+
+	var workerTypes []string
+	for _, w := range status.TaskWorkers {
+		workerTypes = append(workerTypes, w.WorkerType)
+		require.True(t, w.Started, w.WorkerType)
+	}
+	require.Equal(t, []string{"WorkflowWorker", "LocalActivityWorker", "ActivityWorker", "ActivityWorker", "ActivityWorker"}, workerTypes)
+	require.Equal(t, 1, status.TaskWorkers[2].UsedSlots)
+
+	require.Len(t, status.RunningActivities, 1)
+	running := status.RunningActivities[0]
+	require.Equal(t, "BlockingActivity", running.ActivityType)
+	require.Equal(t, "status", running.WorkflowID)
+	require.Equal(t, "statusTestWorkflow", running.WorkflowType)
+	require.Equal(t, "status-task-queue", running.TaskQueue)
+	require.False(t, running.StartTime.IsZero())
+
+	close(activities.release)
+	require.NoError(t, run.Get(ctx, nil))
+	require.Eventually(t, func() bool {
+		return len(worker.Status().RunningActivities) == 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -380,6 +380,30 @@ func (wc *workflowEnvironmentInterceptor) Init(outbound WorkflowOutboundCallsInt
 	return nil
 }
 
+func (wc *workflowEnvironmentInterceptor) HandleSignal(ctx Context, signalName string, arg *commonpb.Payloads) error {
+	eo := getWorkflowEnvOptions(ctx)
+	// We don't want this code to be blocked ever, using sendAsync().
+	ch := eo.getSignalChannel(ctx, signalName).(*channelImpl)
+	ok := ch.SendAsync(arg)
+	if !ok {
+		return fmt.Errorf("exceeded channel buffer size for signal: %v", signalName)
+	}
+	return nil
+}
+
+func (wc *workflowEnvironmentInterceptor) HandleQuery(ctx Context, queryType string, args *commonpb.Payloads) (*commonpb.Payloads, error) {
+	eo := getWorkflowEnvOptions(ctx)
+	handler, ok := eo.queryHandlers[queryType]
+	if !ok {
+		keys := []string{QueryTypeStackTrace, QueryTypeOpenSessions}
+		for k := range eo.queryHandlers {
+			keys = append(keys, k)
+		}
+		return nil, fmt.Errorf("unknown queryType %v. KnownQueryTypes=%v", queryType, keys)
+	}
+	return handler(args)
+}
+
 // ExecuteActivity requests activity execution in the context of a workflow.
 // Context can be used to pass the settings for this activity.
 // For example: task queue that this need to be routed, timeouts that need to be configured.
@@ -450,6 +474,10 @@ func (wc *workflowEnvironmentInterceptor) ExecuteActivity(ctx Context, typeName
 	if err != nil {
 		panic(err)
 	}
+	if err := checkWorkflowPayloadSize(wc.env, input, payloadOperationActivityInput, activityType.Name); err != nil {
+		settable.Set(nil, err)
+		return future
+	}
 
 	params := ExecuteActivityParams{
 		ExecuteActivityOptions: *options,
@@ -658,7 +686,10 @@ func (wc *workflowEnvironmentInterceptor) ExecuteChildWorkflow(ctx Context, chil
 	workflowOptionsFromCtx := getWorkflowEnvOptions(ctx)
 	dc := workflowOptionsFromCtx.DataConverter
 	env := getWorkflowEnvironment(ctx)
-	wfType, input, err := getValidatedWorkflowFunction(childWorkflowType, args, dc, env.GetRegistry())
+	wfType, input, err := getValidatedWorkflowFunction(childWorkflowType, args, dataConverterWithWorkflowContext(ctx, dc), env.GetRegistry())
+	if err == nil {
+		err = checkWorkflowPayloadSize(env, input, payloadOperationChildWorkflowInput, wfType.Name)
+	}
 	if err != nil {
 		executionSettable.Set(nil, err)
 		mainSettable.Set(nil, err)
@@ -922,7 +953,10 @@ func signalExternalWorkflow(ctx Context, workflowID, runID, signalName string, a
 		return future
 	}
 
-	input, err := encodeArg(options.DataConverter, arg)
+	input, err := encodeArg(dataConverterWithWorkflowContext(ctx, options.DataConverter), arg)
+	if err == nil {
+		err = checkWorkflowPayloadSize(env, input, payloadOperationSignalInput, signalName)
+	}
 	if err != nil {
 		settable.Set(nil, err)
 		return future
@@ -504,6 +504,12 @@ func (e *TestWorkflowEnvironment) SetDataConverter(dataConverter converter.DataC
 	return e
 }
 
+// SetPayloadLimits sets limits of payload sizes, see ClientOptions.PayloadLimits.
+func (e *TestWorkflowEnvironment) SetPayloadLimits(payloadLimits PayloadLimitOptions) *TestWorkflowEnvironment {
+	e.impl.setPayloadLimits(payloadLimits)
+	return e
+}
+
 // SetIdentity sets identity.
 func (e *TestWorkflowEnvironment) SetIdentity(identity string) *TestWorkflowEnvironment {
 	e.impl.setIdentity(identity)
@@ -44,6 +44,29 @@ type Client struct {
 	mock.Mock
 }
 
+// BatchOperation provides a mock function with given fields: ctx, options
+func (_m *Client) BatchOperation(ctx context.Context, options client.BatchOperationOptions) (*client.BatchOperationResult, error) {
+	ret := _m.Called(ctx, options)
+
+	var r0 *client.BatchOperationResult
+	if rf, ok := ret.Get(0).(func(context.Context, client.BatchOperationOptions) *client.BatchOperationResult); ok {
+		r0 = rf(ctx, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.BatchOperationResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, client.BatchOperationOptions) error); ok {
+		r1 = rf(ctx, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CancelWorkflow provides a mock function with given fields: ctx, workflowID, runID
 func (_m *Client) CancelWorkflow(ctx context.Context, workflowID string, runID string) error {
 	ret := _m.Called(ctx, workflowID, runID)
@@ -328,6 +351,22 @@ func (_m *Client) ListArchivedWorkflow(ctx context.Context, request *workflowser
 	return r0, r1
 }
 
+// ListWorkflowExecutions provides a mock function with given fields: ctx, options
+func (_m *Client) ListWorkflowExecutions(ctx context.Context, options client.ListWorkflowExecutionsOptions) client.WorkflowExecutionIterator {
+	ret := _m.Called(ctx, options)
+
+	var r0 client.WorkflowExecutionIterator
+	if rf, ok := ret.Get(0).(func(context.Context, client.ListWorkflowExecutionsOptions) client.WorkflowExecutionIterator); ok {
+		r0 = rf(ctx, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(client.WorkflowExecutionIterator)
+		}
+	}
+
+	return r0
+}
+
 // QueryWorkflow provides a mock function with given fields: ctx, workflowID, runID, queryType, args
 func (_m *Client) QueryWorkflow(ctx context.Context, workflowID string, runID string, queryType string, args ...interface{}) (converter.EncodedValue, error) {
 	var _ca []interface{}
@@ -413,6 +452,29 @@ func (_m *Client) RecordActivityHeartbeatByID(ctx context.Context, namespace str
 	return r0
 }
 
+// ResetWorkflow provides a mock function with given fields: ctx, request
+func (_m *Client) ResetWorkflow(ctx context.Context, request *client.ResetWorkflowRequest) (*workflowservice.ResetWorkflowExecutionResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *workflowservice.ResetWorkflowExecutionResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *client.ResetWorkflowRequest) *workflowservice.ResetWorkflowExecutionResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*workflowservice.ResetWorkflowExecutionResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *client.ResetWorkflowRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ScanWorkflow provides a mock function with given fields: ctx, request
 func (_m *Client) ScanWorkflow(ctx context.Context, request *workflowservice.ScanWorkflowExecutionsRequest) (*workflowservice.ScanWorkflowExecutionsResponse, error) {
 	ret := _m.Called(ctx, request)
@@ -143,6 +143,9 @@ type (
 
 	// UnknownExternalWorkflowExecutionError can be returned when external workflow doesn't exist
 	UnknownExternalWorkflowExecutionError = internal.UnknownExternalWorkflowExecutionError
+
+	// PayloadSizeError is returned when a payload exceeds the limit set in client.Options.PayloadLimits.
+	PayloadSizeError = internal.PayloadSizeError
 )
 
 var (
@@ -172,6 +175,17 @@ func NewNonRetryableApplicationError(message, errType string, cause error, detai
 	return internal.NewApplicationError(message, errType, true, cause, details...)
 }
 
+// RegisterErrorType registers the type of err so that errors of this type returned from activities and workflows
+// are reconstructed on the caller side. Exported fields of the error are serialized into ApplicationError details
+// with the DataConverter. The caller receives the usual *ApplicationError, and errors.As(err, &target) with a target
+// of the registered type succeeds and fills target with the deserialized error. This works for activity and child
+// workflow errors in workflow code and for the error returned by WorkflowRun.Get on the client.
+// Both pointer (RegisterErrorType(&MyError{})) and value (RegisterErrorType(MyError{})) error types can be
+// registered. Register the type in the processes of both the code returning the error and the code handling it.
+func RegisterErrorType(err error) {
+	internal.RegisterErrorType(err)
+}
+
 // NewCanceledError creates CanceledError instance.
 // Return this error from activity or child workflow to indicate that it was successfully cancelled.
 func NewCanceledError(details ...interface{}) *CanceledError {
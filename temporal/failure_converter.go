@@ -0,0 +1,50 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package temporal
+
+import "go.temporal.io/sdk/internal"
+
+type (
+	// FailureConverter is used by the sdk to serialize/deserialize errors
+	// that need to be sent over the wire.
+	// To use a custom FailureConverter, set FailureConverter in client.Options or worker.Options.
+	FailureConverter = internal.FailureConverter
+
+	// DefaultFailureConverterOptions are optional parameters for DefaultFailureConverter creation.
+	DefaultFailureConverterOptions = internal.DefaultFailureConverterOptions
+
+	// DefaultFailureConverter converts errors to failures and back, encoding failure details with a DataConverter.
+	DefaultFailureConverter = internal.DefaultFailureConverter
+)
+
+// GetDefaultFailureConverter returns the default failure converter used by Temporal.
+func GetDefaultFailureConverter() FailureConverter {
+	return internal.GetDefaultFailureConverter()
+}
+
+// NewDefaultFailureConverter creates new instance of DefaultFailureConverter.
+func NewDefaultFailureConverter(opt DefaultFailureConverterOptions) *DefaultFailureConverter {
+	return internal.NewDefaultFailureConverter(opt)
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	commonpb "go.temporal.io/api/common/v1"
 	enumspb "go.temporal.io/api/enums/v1"
 	"go.temporal.io/api/serviceerror"
 	workflowpb "go.temporal.io/api/workflow/v1"
@@ -695,3 +696,13 @@ func (t *tracingInboundCallsInterceptor) ExecuteWorkflow(ctx workflow.Context, w
 	t.trace = append(t.trace, "ExecuteWorkflow end")
 	return result
 }
+
+func (t *tracingInboundCallsInterceptor) HandleSignal(ctx workflow.Context, signalName string, arg *commonpb.Payloads) error {
+	t.trace = append(t.trace, "HandleSignal")
+	return t.Next.HandleSignal(ctx, signalName, arg)
+}
+
+func (t *tracingInboundCallsInterceptor) HandleQuery(ctx workflow.Context, queryType string, args *commonpb.Payloads) (*commonpb.Payloads, error) {
+	t.trace = append(t.trace, "HandleQuery")
+	return t.Next.HandleQuery(ctx, queryType, args)
+}
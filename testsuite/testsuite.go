@@ -41,7 +41,16 @@ type (
 
 	// MockCallWrapper is a wrapper to mock.Call. It offers the ability to wait on workflow's clock instead of wall clock.
 	MockCallWrapper = internal.MockCallWrapper
+
+	// InMemoryWorkflowService is an in-process workflowservice.WorkflowServiceClient. Pass it as
+	// client.Options.WorkflowService to run a client and workers end-to-end without a Temporal server.
+	InMemoryWorkflowService = internal.InMemoryWorkflowService
 )
 
 // ErrMockStartChildWorkflowFailed is special error used to indicate the mocked child workflow should fail to start.
 var ErrMockStartChildWorkflowFailed = internal.ErrMockStartChildWorkflowFailed
+
+// NewInMemoryWorkflowService creates an empty InMemoryWorkflowService.
+func NewInMemoryWorkflowService() *InMemoryWorkflowService {
+	return internal.NewInMemoryWorkflowService()
+}
@@ -124,6 +124,23 @@ type (
 
 		// Stop the worker.
 		Stop()
+
+		// Pause stops polling for new tasks, including activities of sessions, while polls in flight complete
+		// and tasks already polled keep executing. Local activities of running workflow tasks are not paused.
+		// The worker stays registered and keeps its sticky workflow cache.
+		Pause()
+
+		// Resume resumes polling of a paused worker.
+		Resume()
+
+		// UpdateOptions changes rate limits, concurrency limits and poller counts of the running worker.
+		// Zero values keep the current values. Tasks in flight are not affected, lowered concurrency limits
+		// apply once enough of them complete.
+		UpdateOptions(options UpdateOptions) error
+
+		// Status returns a snapshot of the state of the worker: its pollers and slots, the sticky workflow cache,
+		// registered types, open sessions and running activities.
+		Status() Status
 	}
 
 	// WorkflowReplayer supports replaying a workflow from its event history.
@@ -169,11 +186,39 @@ type (
 	// Options is used to configure a worker instance.
 	Options = internal.WorkerOptions
 
+	// UpdateOptions are the options of a running worker changed with Worker.UpdateOptions.
+	UpdateOptions = internal.WorkerUpdateOptions
+
+	// Status is a snapshot of the state of a worker returned by Worker.Status.
+	Status = internal.WorkerStatus
+
+	// TaskWorkerStatus is the status of a task worker that polls one task queue for one kind of task.
+	TaskWorkerStatus = internal.TaskWorkerStatus
+
+	// StickyCacheStatus is the status of the sticky workflow cache.
+	StickyCacheStatus = internal.StickyCacheStatus
+
+	// RunningActivityStatus describes an activity executing on the worker.
+	RunningActivityStatus = internal.RunningActivityStatus
+
 	// WorkflowPanicPolicy is used for configuring how worker deals with workflow
 	// code panicking which includes non backwards compatible changes to the workflow code without appropriate
 	// versioning (see workflow.GetVersion).
 	// The default behavior is to block workflow execution until the problem is fixed.
 	WorkflowPanicPolicy = internal.WorkflowPanicPolicy
+
+	// SlotSupplier decides when a worker can start executing one more task, see Options.SlotSupplier.
+	SlotSupplier = internal.SlotSupplier
+
+	// SlotInfo describes the task worker a slot is reserved or released for.
+	SlotInfo = internal.SlotInfo
+
+	// ResourceBasedSlotSupplierOptions are the options of ResourceBasedSlotSupplier.
+	ResourceBasedSlotSupplierOptions = internal.ResourceBasedSlotSupplierOptions
+
+	// ResourceBasedSlotSupplier is a SlotSupplier that gives slots while memory and CPU usage of the process are
+	// below targets. It is only supported on Linux.
+	ResourceBasedSlotSupplier = internal.ResourceBasedSlotSupplier
 )
 
 const (
@@ -202,6 +247,12 @@ func New(
 	return internal.NewWorker(client, taskQueue, options)
 }
 
+// NewResourceBasedSlotSupplier creates a SlotSupplier that reads memory and CPU usage from the cgroup of the process,
+// or from /proc if the cgroup has no limits. It fails if resource usage can't be read.
+func NewResourceBasedSlotSupplier(options ResourceBasedSlotSupplierOptions) (*ResourceBasedSlotSupplier, error) {
+	return internal.NewResourceBasedSlotSupplier(options)
+}
+
 // NewWorkflowReplayer creates a WorkflowReplayer instance.
 func NewWorkflowReplayer() WorkflowReplayer {
 	return internal.NewWorkflowReplayer()
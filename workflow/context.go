@@ -25,6 +25,8 @@
 package workflow
 
 import (
+	"time"
+
 	"go.temporal.io/sdk/internal"
 )
 
@@ -58,6 +60,29 @@ func WithCancel(parent Context) (ctx Context, cancel CancelFunc) {
 	return internal.WithCancel(parent)
 }
 
+// WithDeadline returns a copy of the parent context with the deadline adjusted
+// to be no later than d.  If the parent's deadline is already earlier than d,
+// WithDeadline(parent, d) is semantically equivalent to parent.  The returned
+// context's Done channel is closed when the deadline expires, when the returned
+// cancel function is called, or when the parent context's Done channel is
+// closed, whichever happens first. Activities, child workflows and timers started
+// with the returned context are canceled when it is done.
+// The deadline is measured in workflow time (see Now) and is backed by a workflow timer.
+//
+// Canceling this context releases resources associated with it, so code should
+// call cancel as soon as the operations running in this Context complete.
+func WithDeadline(parent Context, d time.Time) (ctx Context, cancel CancelFunc) {
+	return internal.WithDeadline(parent, d)
+}
+
+// WithTimeout returns WithDeadline(parent, workflow.Now(parent).Add(timeout)).
+//
+// Canceling this context releases resources associated with it, so code should
+// call cancel as soon as the operations running in this Context complete.
+func WithTimeout(parent Context, timeout time.Duration) (ctx Context, cancel CancelFunc) {
+	return internal.WithTimeout(parent, timeout)
+}
+
 // WithValue returns a copy of parent in which the value associated with key is
 // val.
 //
@@ -58,6 +58,11 @@ type (
 	// ContinueAsNewError can be returned by a workflow implementation function and indicates that
 	// the workflow should continue as new with the same WorkflowID, but new RunID and new history.
 	ContinueAsNewError = internal.ContinueAsNewError
+
+	// ContextAwareDataConverter is an optional interface which can be implemented by DataConverter.
+	// SDK passes workflow or activity context to it before encoding or decoding values, so DataConverter can tailor
+	// its behaviour, for example pick an encryption key per namespace or add workflow ID to its logs.
+	ContextAwareDataConverter = internal.ContextAwareDataConverter
 )
 
 // ExecuteActivity requests activity execution in the context of a workflow.